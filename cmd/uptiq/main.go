@@ -1,6 +1,9 @@
 package main
 
 import (
+	// Embed time zone data so schedules with a timezone work on minimal hosts
+	_ "time/tzdata"
+
 	"uptiq/internal/cli"
 )
 
@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -30,6 +31,17 @@ func NewChannelSender() *ChannelSender {
 	}
 }
 
+// StatusError is returned when a channel answers with a non-2xx status.
+type StatusError struct {
+	Status     string
+	StatusCode int
+	RetryAfter time.Duration // From the Retry-After header; 0 if absent
+}
+
+func (e *StatusError) Error() string {
+	return "non-2xx status: " + e.Status
+}
+
 // SendResult contains the outcome of a send operation.
 type SendResult struct {
 	Success bool
@@ -47,6 +59,22 @@ func (s *ChannelSender) Send(ctx context.Context, ch config.Channel, payload Ale
 		err = s.sendSlack(ctx, ch.WebhookURL, payload.WebhookMessage)
 	case config.ChannelTypeEmail:
 		err = s.sendEmail(ctx, ch, payload)
+	case config.ChannelTypePagerDuty:
+		err = s.sendPagerDuty(ctx, ch, payload)
+	case config.ChannelTypeOpsgenie:
+		err = s.sendOpsgenie(ctx, ch, payload)
+	case config.ChannelTypeTeams:
+		err = s.sendTeams(ctx, ch.WebhookURL, payload)
+	case config.ChannelTypeTelegram:
+		err = s.sendTelegram(ctx, ch, payload.WebhookMessage)
+	case config.ChannelTypeMatrix:
+		err = s.sendMatrix(ctx, ch, payload)
+	case config.ChannelTypeNtfy:
+		err = s.sendNtfy(ctx, ch, payload)
+	case config.ChannelTypeGotify:
+		err = s.sendGotify(ctx, ch, payload)
+	case config.ChannelTypeWebhook:
+		err = s.sendWebhook(ctx, ch, payload)
 	default:
 		err = fmt.Errorf("unsupported channel type: %s", ch.Type)
 	}
@@ -83,20 +111,65 @@ func (s *ChannelSender) sendEmail(ctx context.Context, ch config.Channel, payloa
 		body = payload.WebhookMessage
 	}
 
-	return sendEmail(ctx, ch, subject, body)
+	return sendEmail(ctx, ch, subject, body, payload.EmailHTML)
+}
+
+// alertTitle is the one-line summary of an alert, for channels that show a
+// title above the message.
+func alertTitle(payload AlertPayload) string {
+	if strings.TrimSpace(payload.EmailSubject) != "" {
+		return payload.EmailSubject
+	}
+	if payload.Kind == "recovery" {
+		return "[UP] " + payload.Context.Service.ID
+	}
+	return "[DOWN] " + payload.Context.Service.ID
+}
+
+// incidentKey is the same for every alert of a service, so in incident
+// tools a DOWN alert and its reminders update one incident and the
+// recovery closes it.
+func incidentKey(serviceID string) string {
+	return "uptiq/" + serviceID
+}
+
+// incidentChannels returns the names among names of channels that open
+// incidents keyed by incidentKey.
+func incidentChannels(channels map[string]config.Channel, names []string) []string {
+	var incident []string
+	for _, name := range names {
+		switch config.ChannelType(strings.ToLower(strings.TrimSpace(channels[name].Type))) {
+		case config.ChannelTypePagerDuty, config.ChannelTypeOpsgenie:
+			incident = append(incident, name)
+		}
+	}
+	return incident
 }
 
 func (s *ChannelSender) postJSON(ctx context.Context, url string, payload any) error {
+	return s.sendJSON(ctx, http.MethodPost, url, payload, nil)
+}
+
+// sendJSON sends payload to url with extra request headers, such as
+// credentials. Any non-2xx answer is a *StatusError.
+func (s *ChannelSender) sendJSON(ctx context.Context, method, url string, payload any, headers map[string]string) error {
 	body, err := json.Marshal(payload)
 	if err != nil {
 		return fmt.Errorf("marshal payload: %w", err)
 	}
+	return s.sendBody(ctx, method, url, body, "application/json", headers)
+}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+// sendBody sends body with the given content type; headers may override it.
+func (s *ChannelSender) sendBody(ctx context.Context, method, url string, body []byte, contentType string, headers map[string]string) error {
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
 	if err != nil {
 		return fmt.Errorf("build request: %w", err)
 	}
-	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Type", contentType)
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
 
 	resp, err := s.client.Do(req)
 	if err != nil {
@@ -109,8 +182,31 @@ func (s *ChannelSender) postJSON(ctx context.Context, url string, payload any) e
 	}()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("non-2xx status: %s", resp.Status)
+		return &StatusError{
+			Status:     resp.Status,
+			StatusCode: resp.StatusCode,
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+		}
 	}
 
 	return nil
 }
+
+// parseRetryAfter reads a Retry-After header given in seconds or as an HTTP
+// date. It returns 0 when the header is absent or invalid.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(value); err == nil {
+		if secs < 0 {
+			return 0
+		}
+		return time.Duration(secs) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return 0
+}
@@ -0,0 +1,101 @@
+package alerting
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"uptiq/internal/config"
+)
+
+// Chat channel constants.
+const (
+	telegramMaxText = 4096
+	teamsCardSchema = "http://adaptivecards.io/schemas/adaptive-card.json"
+	teamsCardType   = "application/vnd.microsoft.card.adaptive"
+)
+
+// sendTeams posts an Adaptive Card to a Teams workflow webhook.
+func (s *ChannelSender) sendTeams(ctx context.Context, webhookURL string, payload AlertPayload) error {
+	if strings.TrimSpace(webhookURL) == "" {
+		return errors.New("empty teams webhook_url")
+	}
+
+	color := "Attention"
+	if payload.Kind == "recovery" {
+		color = "Good"
+	}
+
+	card := map[string]any{
+		"$schema": teamsCardSchema,
+		"type":    "AdaptiveCard",
+		"version": "1.4",
+		"body": []map[string]any{
+			{"type": "TextBlock", "text": alertTitle(payload), "weight": "Bolder", "size": "Medium", "color": color, "wrap": true},
+			{"type": "TextBlock", "text": payload.WebhookMessage, "wrap": true},
+		},
+	}
+	return s.postJSON(ctx, webhookURL, map[string]any{
+		"type":        "message",
+		"attachments": []map[string]any{{"contentType": teamsCardType, "content": card}},
+	})
+}
+
+// sendTelegram sends the message to a chat through the Bot API.
+func (s *ChannelSender) sendTelegram(ctx context.Context, ch config.Channel, message string) error {
+	if strings.TrimSpace(ch.BotToken) == "" {
+		return errors.New("empty telegram bot_token")
+	}
+
+	apiURL := strings.TrimRight(ch.APIURL, "/")
+	if apiURL == "" {
+		apiURL = config.DefaultTelegramURL
+	}
+
+	err := s.postJSON(ctx, apiURL+"/bot"+ch.BotToken+"/sendMessage", map[string]any{
+		"chat_id":                  ch.ChatID,
+		"text":                     truncate(message, telegramMaxText),
+		"disable_web_page_preview": true,
+	})
+
+	// The token is part of the URL, which transport errors repeat
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		urlErr.URL = strings.ReplaceAll(urlErr.URL, ch.BotToken, "<bot_token>")
+	}
+	return err
+}
+
+// sendMatrix sends the message to a room through the client-server API.
+func (s *ChannelSender) sendMatrix(ctx context.Context, ch config.Channel, payload AlertPayload) error {
+	if strings.TrimSpace(ch.APIURL) == "" || strings.TrimSpace(ch.Token) == "" {
+		return errors.New("matrix api_url and token are required")
+	}
+
+	sendURL := strings.TrimRight(ch.APIURL, "/") +
+		"/_matrix/client/v3/rooms/" + url.PathEscape(ch.RoomID) +
+		"/send/m.room.message/" + matrixTxnID(payload)
+
+	return s.sendJSON(ctx, http.MethodPut, sendURL, map[string]string{
+		"msgtype": "m.text",
+		"body":    payload.WebhookMessage,
+	}, map[string]string{"Authorization": "Bearer " + ch.Token})
+}
+
+// matrixTxnID derives the transaction ID from the alert's delivery key, so
+// a retry of the same alert, also after a restart, is deduplicated by the
+// homeserver instead of posted twice, while two alerts with the same text
+// are both posted. Alerts sent outside the dispatcher fall back to their
+// content.
+func matrixTxnID(payload AlertPayload) string {
+	id := payload.DeliveryKey
+	if id == "" {
+		id = payload.Context.Service.ID + "\x00" + payload.Kind + "\x00" + payload.WebhookMessage
+	}
+	sum := sha256.Sum256([]byte(id))
+	return "uptiq-" + hex.EncodeToString(sum[:16])
+}
@@ -0,0 +1,193 @@
+package alerting
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"uptiq/internal/config"
+	"uptiq/internal/templates"
+)
+
+type recordedRequest struct {
+	Method string
+	Path   string
+	Header http.Header
+	Body   map[string]any
+}
+
+// recordingServer answers 200 and keeps the last request.
+func recordingServer(t *testing.T) (*httptest.Server, *recordedRequest) {
+	t.Helper()
+
+	var last recordedRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		last = recordedRequest{Method: r.Method, Path: r.URL.EscapedPath(), Header: r.Header.Clone()}
+		_ = json.NewDecoder(r.Body).Decode(&last.Body)
+	}))
+	t.Cleanup(server.Close)
+	return server, &last
+}
+
+func TestChannelSender_ChatAndPush(t *testing.T) {
+	payload := AlertPayload{
+		Kind:           "down",
+		Context:        templates.Alert{Service: templates.Service{ID: "web"}},
+		WebhookMessage: "🚨 DOWN: Web (web) [http]",
+		EmailSubject:   "[DOWN] Web (web)",
+	}
+
+	tests := []struct {
+		name       string
+		channel    func(url string) config.Channel
+		wantMethod string
+		wantPath   string
+		wantHeader [2]string
+		check      func(t *testing.T, body map[string]any)
+	}{
+		{
+			name:       "teams",
+			channel:    func(url string) config.Channel { return config.Channel{Type: "teams", WebhookURL: url + "/workflow"} },
+			wantMethod: http.MethodPost,
+			wantPath:   "/workflow",
+			check: func(t *testing.T, body map[string]any) {
+				attachments, _ := body["attachments"].([]any)
+				if body["type"] != "message" || len(attachments) != 1 {
+					t.Fatalf("body = %v, want a message with one attachment", body)
+				}
+				if ct := attachments[0].(map[string]any)["contentType"]; ct != teamsCardType {
+					t.Errorf("contentType = %v, want %s", ct, teamsCardType)
+				}
+			},
+		},
+		{
+			name: "telegram",
+			channel: func(url string) config.Channel {
+				return config.Channel{Type: "telegram", APIURL: url, BotToken: "123:abc", ChatID: "-100200"}
+			},
+			wantMethod: http.MethodPost,
+			wantPath:   "/bot123:abc/sendMessage",
+			check: func(t *testing.T, body map[string]any) {
+				if body["chat_id"] != "-100200" || body["text"] != payload.WebhookMessage {
+					t.Errorf("body = %v", body)
+				}
+			},
+		},
+		{
+			name: "matrix",
+			channel: func(url string) config.Channel {
+				return config.Channel{Type: "matrix", APIURL: url, Token: "syt_token", RoomID: "!room:example.org"}
+			},
+			wantMethod: http.MethodPut,
+			wantPath:   "/_matrix/client/v3/rooms/%21room:example.org/send/m.room.message/" + matrixTxnID(payload),
+			wantHeader: [2]string{"Authorization", "Bearer syt_token"},
+			check: func(t *testing.T, body map[string]any) {
+				if body["msgtype"] != "m.text" || body["body"] != payload.WebhookMessage {
+					t.Errorf("body = %v", body)
+				}
+			},
+		},
+		{
+			name: "ntfy",
+			channel: func(url string) config.Channel {
+				return config.Channel{Type: "ntfy", APIURL: url, Topic: "alerts", Token: "tk_x", Priority: "urgent", Tags: []string{"prod"}}
+			},
+			wantMethod: http.MethodPost,
+			wantPath:   "",
+			wantHeader: [2]string{"Authorization", "Bearer tk_x"},
+			check: func(t *testing.T, body map[string]any) {
+				if body["topic"] != "alerts" || body["title"] != payload.EmailSubject || body["priority"] != float64(5) {
+					t.Errorf("body = %v", body)
+				}
+				if tags, _ := body["tags"].([]any); len(tags) != 2 || tags[0] != ntfyDownTag {
+					t.Errorf("tags = %v, want [%s prod]", body["tags"], ntfyDownTag)
+				}
+			},
+		},
+		{
+			name: "gotify",
+			channel: func(url string) config.Channel {
+				return config.Channel{Type: "gotify", APIURL: url + "/", Token: "app-token", Priority: "8"}
+			},
+			wantMethod: http.MethodPost,
+			wantPath:   "/message",
+			wantHeader: [2]string{"X-Gotify-Key", "app-token"},
+			check: func(t *testing.T, body map[string]any) {
+				if body["title"] != payload.EmailSubject || body["priority"] != float64(8) {
+					t.Errorf("body = %v", body)
+				}
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			server, last := recordingServer(t)
+
+			res := NewChannelSender().Send(context.Background(), tc.channel(server.URL), payload)
+			if !res.Success {
+				t.Fatalf("Send: %v", res.Error)
+			}
+			if last.Method != tc.wantMethod {
+				t.Errorf("method = %s, want %s", last.Method, tc.wantMethod)
+			}
+			if strings.TrimPrefix(last.Path, "/") != strings.TrimPrefix(tc.wantPath, "/") {
+				t.Errorf("path = %s, want %s", last.Path, tc.wantPath)
+			}
+			if key := tc.wantHeader[0]; key != "" && last.Header.Get(key) != tc.wantHeader[1] {
+				t.Errorf("%s = %q, want %q", key, last.Header.Get(key), tc.wantHeader[1])
+			}
+			tc.check(t, last.Body)
+		})
+	}
+}
+
+func TestSendTelegram_RedactsToken(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close() // Refuses connections from here on
+
+	ch := config.Channel{Type: "telegram", APIURL: server.URL, BotToken: "123:secret", ChatID: "1"}
+	res := NewChannelSender().Send(context.Background(), ch, AlertPayload{Kind: "down", WebhookMessage: "down"})
+	if res.Error == nil {
+		t.Fatal("expected an error from a closed server")
+	}
+	if strings.Contains(res.Error.Error(), "secret") {
+		t.Errorf("error leaks the bot token: %v", res.Error)
+	}
+	if _, retry := retryDelay(res.Error, initialRetryDelay); !retry {
+		t.Errorf("connection error should still be retried: %v", res.Error)
+	}
+}
+
+func TestMatrixTxnID(t *testing.T) {
+	alert := func(key, message string) AlertPayload {
+		return AlertPayload{
+			Kind:           "down",
+			Context:        templates.Alert{Service: templates.Service{ID: "web"}},
+			WebhookMessage: message,
+			DeliveryKey:    key,
+		}
+	}
+
+	tests := []struct {
+		name     string
+		a, b     AlertPayload
+		wantSame bool
+	}{
+		{name: "retry of one alert", a: alert("web:down:matrix:1", "down"), b: alert("web:down:matrix:1", "down"), wantSame: true},
+		{name: "alerts with the same text", a: alert("web:down:matrix:1", "down"), b: alert("web:down:matrix:2", "down")},
+		{name: "content without a key", a: alert("", "down at 12:00"), b: alert("", "down at 12:00"), wantSame: true},
+		{name: "different content without a key", a: alert("", "down at 12:00"), b: alert("", "down at 12:05")},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if same := matrixTxnID(tc.a) == matrixTxnID(tc.b); same != tc.wantSame {
+				t.Errorf("same transaction ID = %v, want %v", same, tc.wantSame)
+			}
+		})
+	}
+}
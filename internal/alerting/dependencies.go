@@ -0,0 +1,80 @@
+package alerting
+
+import (
+	"slices"
+
+	"uptiq/internal/config"
+)
+
+// DependencyNode describes a service's position in the dependency graph.
+type DependencyNode struct {
+	ServiceID  string     `json:"service_id"`
+	Name       string     `json:"name"`
+	State      AlertState `json:"state"`
+	DependsOn  []string   `json:"depends_on"`
+	Dependents []string   `json:"dependents"`
+}
+
+// SetServices replaces the service list used to build the dependency graph
+// and drops the alert state of services no longer in it.
+func (e *Engine) SetServices(services []config.Service) {
+	ids := make([]string, len(services))
+	for i, svc := range services {
+		ids[i] = svc.ID
+	}
+
+	e.mu.Lock()
+	e.services = slices.Clone(services)
+	e.mu.Unlock()
+
+	if dropped := e.state.Retain(ids); dropped > 0 {
+		e.log.Info("dropped alert state of removed services", "services", dropped)
+	}
+}
+
+// Dependencies returns the dependency graph with each service's current state.
+func (e *Engine) Dependencies() []DependencyNode {
+	e.mu.RLock()
+	services := e.services
+	e.mu.RUnlock()
+
+	return buildDependencyGraph(services, e.stateOf)
+}
+
+// stateOf returns the current alert state of a service.
+func (e *Engine) stateOf(serviceID string) AlertState {
+	if st, ok := e.state.Lookup(serviceID); ok {
+		return st.State
+	}
+	return StateUnknown
+}
+
+func buildDependencyGraph(services []config.Service, stateOf func(string) AlertState) []DependencyNode {
+	dependents := make(map[string][]string)
+	for _, svc := range services {
+		for _, parent := range svc.DependsOn {
+			dependents[parent] = append(dependents[parent], svc.ID)
+		}
+	}
+
+	nodes := make([]DependencyNode, 0, len(services))
+	for _, svc := range services {
+		nodes = append(nodes, DependencyNode{
+			ServiceID:  svc.ID,
+			Name:       svc.Name,
+			State:      stateOf(svc.ID),
+			DependsOn:  nonNil(svc.DependsOn),
+			Dependents: nonNil(dependents[svc.ID]),
+		})
+	}
+
+	return nodes
+}
+
+// nonNil returns an empty slice instead of nil so JSON encodes [] not null.
+func nonNil(in []string) []string {
+	if in == nil {
+		return []string{}
+	}
+	return slices.Clone(in)
+}
@@ -0,0 +1,72 @@
+package alerting
+
+import (
+	"slices"
+	"testing"
+
+	"uptiq/internal/checks"
+	"uptiq/internal/config"
+)
+
+func TestEngine_Dependencies(t *testing.T) {
+	engine := NewEngine(config.AlertingConfig{}, nil, nil)
+	engine.SetServices([]config.Service{
+		{ID: "lb", Name: "Load Balancer"},
+		{ID: "web", Name: "Web", DependsOn: []string{"lb"}},
+		{ID: "api", Name: "API", DependsOn: []string{"lb", "web"}},
+	})
+
+	engine.HandleResult(config.Service{ID: "lb"}, checks.Result{Success: false})
+
+	nodes := engine.Dependencies()
+	if len(nodes) != 3 {
+		t.Fatalf("len(nodes) = %d, want 3", len(nodes))
+	}
+
+	lb := nodes[0]
+	if lb.ServiceID != "lb" || lb.State != StateDown {
+		t.Errorf("lb node = %+v, want DOWN", lb)
+	}
+	if !slices.Equal(lb.Dependents, []string{"web", "api"}) {
+		t.Errorf("lb dependents = %v, want [web api]", lb.Dependents)
+	}
+	if lb.DependsOn == nil || len(lb.DependsOn) != 0 {
+		t.Errorf("lb depends_on = %#v, want empty non-nil slice", lb.DependsOn)
+	}
+
+	api := nodes[2]
+	if api.State != StateUnknown {
+		t.Errorf("api state = %q, want %q", api.State, StateUnknown)
+	}
+	if !slices.Equal(api.DependsOn, []string{"lb", "web"}) {
+		t.Errorf("api depends_on = %v, want [lb web]", api.DependsOn)
+	}
+}
+
+func TestEngine_SetServices_Replaces(t *testing.T) {
+	engine := NewEngine(config.AlertingConfig{}, nil, nil)
+	engine.SetServices([]config.Service{{ID: "a"}, {ID: "b"}})
+	engine.SetServices([]config.Service{{ID: "c"}})
+
+	nodes := engine.Dependencies()
+	if len(nodes) != 1 || nodes[0].ServiceID != "c" {
+		t.Errorf("nodes = %+v, want only c", nodes)
+	}
+}
+
+func TestEngine_SetServices_DropsRemovedState(t *testing.T) {
+	engine := NewEngine(config.AlertingConfig{}, nil, nil)
+	engine.Restore(map[string]ServiceState{
+		"a": {State: StateDown, DownNotified: true},
+		"b": {State: StateUp},
+	})
+	engine.SetServices([]config.Service{{ID: "a"}})
+
+	snapshot := engine.Snapshot()
+	if _, ok := snapshot["b"]; ok {
+		t.Error("state of removed service b should be dropped")
+	}
+	if st := snapshot["a"]; st.State != StateDown || !st.DownNotified {
+		t.Errorf("state of a = %+v, want DOWN and notified", st)
+	}
+}
@@ -0,0 +1,501 @@
+package alerting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/textproto"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"uptiq/internal/config"
+	"uptiq/internal/metrics"
+)
+
+// Dispatch configuration constants.
+const (
+	sendTimeout       = 10 * time.Second // Per attempt
+	queueSize         = 1000             // Alerts waiting per channel
+	maxSendAttempts   = 5
+	initialRetryDelay = 2 * time.Second
+	maxRetryDelay     = 5 * time.Minute
+
+	outboxRetryInterval = time.Minute    // Retry outbox entries given up on
+	outboxMaxAge        = 24 * time.Hour // Older entries are no longer sent
+)
+
+// delivery is one alert on its way to one channel.
+type delivery struct {
+	key      string // Outbox key
+	name     string
+	channel  config.Channel
+	service  config.Service
+	payload  AlertPayload
+	queuedAt time.Time
+}
+
+// deliveryKey identifies an alert to one channel in the outbox.
+func deliveryKey(serviceID, kind, channel string, at time.Time) string {
+	return fmt.Sprintf("%s:%s:%s:%d", serviceID, kind, channel, at.UnixNano())
+}
+
+// dispatcher sends alerts off the check path. Each channel has its own queue
+// and worker, so a slow or failing channel only delays itself, and a
+// channel's alerts go out in order: a recovery never overtakes its DOWN
+// alert, even while that one is being retried.
+//
+// With an outbox, alerts are persisted before they are queued and only
+// removed once delivered or rejected for good. Alerts still failing after
+// retries, dropped, or abandoned at shutdown stay in the outbox and are
+// queued again every outboxRetryInterval and on the next start, unless a
+// newer alert for the same service and channel supersedes them: a DOWN
+// alert is never sent after its recovery.
+//
+// An HA standby neither queues new alerts nor retries the outbox, so the
+// leader is the only instance sending.
+type dispatcher struct {
+	send       func(ctx context.Context, ch config.Channel, payload AlertPayload) SendResult
+	logResult  func(d delivery, result SendResult)
+	onDone     func(key string) // Called once an alert is delivered or given up for good
+	log        *slog.Logger
+	metrics    *metrics.Collector
+	retryDelay time.Duration
+
+	// Set by useOutbox before the first alert
+	outbox  *Outbox
+	resolve func(name string) (config.Channel, bool)
+
+	// Cancelled when Close gives up waiting, abandoning sends and retries
+	ctx    context.Context
+	cancel context.CancelFunc
+	stop   chan struct{}
+
+	standby atomic.Bool
+
+	mu      sync.Mutex
+	queues  map[string]chan delivery
+	queued  map[string]struct{} // Keys queued or being sent
+	closed  bool
+	onSent  func()
+	workers sync.WaitGroup
+	pending sync.WaitGroup
+}
+
+func newDispatcher(sender *ChannelSender, log *slog.Logger, m *metrics.Collector) *dispatcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &dispatcher{
+		send:       sender.Send,
+		log:        log,
+		metrics:    m,
+		retryDelay: initialRetryDelay,
+		ctx:        ctx,
+		cancel:     cancel,
+		stop:       make(chan struct{}),
+		queues:     make(map[string]chan delivery),
+		queued:     make(map[string]struct{}),
+	}
+}
+
+// useOutbox persists alerts in outbox, queues the ones it holds from a
+// previous run, and keeps retrying those not delivered. resolve looks up a
+// channel's current settings by name.
+func (q *dispatcher) useOutbox(outbox *Outbox, resolve func(name string) (config.Channel, bool)) {
+	q.outbox = outbox
+	q.resolve = resolve
+
+	if n := outbox.Len(); n > 0 && !q.standby.Load() {
+		q.log.Info("sending alerts left in the outbox", "pending", n)
+	}
+	q.redeliver(time.Now())
+
+	go func() {
+		ticker := time.NewTicker(outboxRetryInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-q.stop:
+				return
+			case now := <-ticker.C:
+				q.redeliver(now)
+			}
+		}
+	}()
+}
+
+// redeliver queues the outbox entries not queued already. Entries older
+// than outboxMaxAge, superseded, or for channels no longer configured, are
+// dropped. A standby leaves the outbox alone.
+func (q *dispatcher) redeliver(now time.Time) {
+	if q.standby.Load() {
+		return
+	}
+
+	pending := q.outbox.Pending()
+	for _, entry := range pending {
+		if superseded(entry, pending) {
+			q.supersede(entry)
+			continue
+		}
+		if now.Sub(entry.QueuedAt) > outboxMaxAge {
+			q.log.Warn("alert expired in outbox", "channel", entry.Channel, "service_id", entry.ServiceID, "kind", entry.Payload.Kind, "queued_at", entry.QueuedAt)
+			q.finish(entry.Key, entry.Channel, metrics.AlertExpired)
+			continue
+		}
+
+		ch, ok := q.resolve(entry.Channel)
+		if !ok {
+			q.log.Warn("alert channel missing", "channel", entry.Channel, "service_id", entry.ServiceID, "kind", entry.Payload.Kind)
+			q.finish(entry.Key, entry.Channel, metrics.AlertFailed)
+			continue
+		}
+
+		q.enqueue(delivery{
+			key:      entry.Key,
+			name:     entry.Channel,
+			channel:  ch,
+			service:  config.Service{ID: entry.ServiceID, Name: entry.ServiceName},
+			payload:  entry.Payload,
+			queuedAt: entry.QueuedAt,
+		})
+	}
+}
+
+// submit persists d in the outbox, if any, and queues it. A standby
+// discards d: the leader sends it.
+func (q *dispatcher) submit(d delivery) {
+	if q.standby.Load() {
+		q.log.Info("alert not sent; standing by", "channel", d.name, "service_id", d.service.ID, "kind", d.payload.Kind)
+		if q.onDone != nil {
+			q.onDone(d.key)
+		}
+		return
+	}
+
+	if q.outbox != nil {
+		err := q.outbox.Add(OutboxEntry{
+			Key:         d.key,
+			Channel:     d.name,
+			ServiceID:   d.service.ID,
+			ServiceName: d.service.Name,
+			Payload:     d.payload,
+			QueuedAt:    d.queuedAt,
+		})
+		if err != nil {
+			q.log.Error("alert not persisted to outbox", "channel", d.name, "service_id", d.service.ID, "error", err.Error())
+		}
+		q.dropSuperseded()
+		q.observeOutbox()
+	}
+	q.enqueue(d)
+}
+
+// dropSuperseded removes the outbox entries superseded by a newer alert for
+// the same service and channel, unless they are queued: those are sent
+// before the newer alert anyway.
+func (q *dispatcher) dropSuperseded() {
+	pending := q.outbox.Pending()
+	for _, entry := range pending {
+		if superseded(entry, pending) {
+			q.supersede(entry)
+		}
+	}
+}
+
+func (q *dispatcher) supersede(entry OutboxEntry) {
+	q.mu.Lock()
+	_, queued := q.queued[entry.Key]
+	q.mu.Unlock()
+	if queued {
+		return
+	}
+
+	q.log.Info("alert superseded; removed from outbox", "channel", entry.Channel, "service_id", entry.ServiceID, "kind", entry.Payload.Kind, "queued_at", entry.QueuedAt)
+	q.finish(entry.Key, entry.Channel, metrics.AlertSuperseded)
+}
+
+// superseded reports whether pending holds an alert for the same service
+// and channel as entry that was queued after it.
+func superseded(entry OutboxEntry, pending []OutboxEntry) bool {
+	for _, other := range pending {
+		if other.ServiceID == entry.ServiceID && other.Channel == entry.Channel && other.QueuedAt.After(entry.QueuedAt) {
+			return true
+		}
+	}
+	return false
+}
+
+// enqueue queues d without blocking, unless it is queued already. If the
+// channel's queue is full or the dispatcher is closed, d is dropped, or
+// left for later when there is an outbox.
+func (q *dispatcher) enqueue(d delivery) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if _, ok := q.queued[d.key]; ok {
+		return
+	}
+
+	if q.closed {
+		q.drop(d, "shutting down")
+		return
+	}
+
+	queue, ok := q.queues[d.name]
+	if !ok {
+		queue = make(chan delivery, queueSize)
+		q.queues[d.name] = queue
+		q.workers.Add(1)
+		go q.work(d.name, queue)
+	}
+
+	q.pending.Add(1)
+	select {
+	case queue <- d:
+		q.queued[d.key] = struct{}{}
+		q.observeDepth(d.name, len(queue))
+	default:
+		q.pending.Done()
+		q.drop(d, "channel queue full")
+	}
+}
+
+func (q *dispatcher) drop(d delivery, reason string) {
+	fields := []any{"channel", d.name, "service_id", d.service.ID, "kind", d.payload.Kind, "reason", reason}
+	if q.outbox != nil {
+		q.log.Warn("alert not queued; kept in outbox", fields...)
+		return
+	}
+	q.log.Error("alert dropped", fields...)
+	q.observeDelivery(d.name, metrics.AlertDropped)
+	if q.onDone != nil {
+		q.onDone(d.key)
+	}
+}
+
+func (q *dispatcher) work(name string, queue chan delivery) {
+	defer q.workers.Done()
+
+	for d := range queue {
+		q.observeDepth(name, len(queue))
+		q.deliver(d)
+
+		q.mu.Lock()
+		delete(q.queued, d.key)
+		q.mu.Unlock()
+		q.pending.Done()
+	}
+}
+
+// deliver sends d, retrying transient failures with exponential backoff or
+// as long as the channel's Retry-After asks.
+func (q *dispatcher) deliver(d delivery) {
+	backoff := q.retryDelay
+	payload := d.payload
+	payload.DeliveryKey = d.key
+
+	for attempt := 1; ; attempt++ {
+		ctx, cancel := context.WithTimeout(q.ctx, sendTimeout)
+		result := q.send(ctx, d.channel, payload)
+		cancel()
+
+		if q.logResult != nil {
+			q.logResult(d, result)
+		}
+		if result.Success {
+			q.finish(d.key, d.name, metrics.AlertDelivered)
+			if q.outbox != nil {
+				// Older alerts given up on while this one was queued
+				q.dropSuperseded()
+			}
+			q.mu.Lock()
+			onSent := q.onSent
+			q.mu.Unlock()
+			if onSent != nil {
+				onSent()
+			}
+			return
+		}
+
+		wait, retry := retryDelay(result.Error, backoff)
+		if !retry || attempt >= maxSendAttempts || q.ctx.Err() != nil {
+			q.giveUp(d, attempt, result.Error, !retry)
+			return
+		}
+
+		q.log.Info("retrying alert",
+			"channel", d.name,
+			"service_id", d.service.ID,
+			"kind", d.payload.Kind,
+			"attempt", attempt,
+			"retry_in", wait.String(),
+		)
+		if q.metrics != nil {
+			q.metrics.AlertRetries.WithLabelValues(d.name).Inc()
+		}
+
+		select {
+		case <-time.After(wait):
+		case <-q.ctx.Done():
+			q.giveUp(d, attempt, q.ctx.Err(), false)
+			return
+		}
+		backoff = min(backoff*2, maxRetryDelay)
+	}
+}
+
+// giveUp stops sending d. Unless the failure is permanent or a newer alert
+// supersedes it, an alert in the outbox stays there to be sent again later.
+func (q *dispatcher) giveUp(d delivery, attempts int, err error, permanent bool) {
+	fields := []any{
+		"channel", d.name,
+		"service_id", d.service.ID,
+		"service_name", d.service.Name,
+		"kind", d.payload.Kind,
+		"attempts", attempts,
+		"error", err.Error(),
+	}
+
+	if q.outbox != nil && !permanent {
+		entry := OutboxEntry{ServiceID: d.service.ID, Channel: d.name, QueuedAt: d.queuedAt}
+		if superseded(entry, q.outbox.Pending()) {
+			q.log.Info("alert not delivered; superseded by a newer alert", fields...)
+			q.finish(d.key, d.name, metrics.AlertSuperseded)
+			return
+		}
+		q.log.Warn("alert not delivered; kept in outbox", fields...)
+		return
+	}
+	q.log.Error("alert not delivered", fields...)
+	q.finish(d.key, d.name, metrics.AlertFailed)
+}
+
+// finish records the final result for an alert and removes it from the
+// outbox.
+func (q *dispatcher) finish(key, name, result string) {
+	q.observeDelivery(name, result)
+	if q.onDone != nil {
+		q.onDone(key)
+	}
+	if q.outbox == nil {
+		return
+	}
+	if err := q.outbox.Done(key, result); err != nil {
+		q.log.Warn("outbox update failed", "channel", name, "error", err.Error())
+	}
+	q.observeOutbox()
+}
+
+// setStandby stops or resumes sending new alerts and retrying the outbox.
+// Alerts already queued still go out.
+func (q *dispatcher) setStandby(standby bool) {
+	q.standby.Store(standby)
+}
+
+func (q *dispatcher) setOnSent(fn func()) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.onSent = fn
+}
+
+// flush waits until every queued alert is delivered or given up.
+func (q *dispatcher) flush() {
+	q.pending.Wait()
+}
+
+// close stops accepting alerts and waits for the queued ones until ctx is
+// done. Sends and retries still running then are abandoned.
+func (q *dispatcher) close(ctx context.Context) error {
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.stop)
+		for _, queue := range q.queues {
+			close(queue)
+		}
+	}
+	q.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		q.workers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		q.cancel()
+		return nil
+	case <-ctx.Done():
+		q.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+func (q *dispatcher) observeDepth(name string, depth int) {
+	if q.metrics != nil {
+		q.metrics.AlertQueueDepth.WithLabelValues(name).Set(float64(depth))
+	}
+}
+
+func (q *dispatcher) observeOutbox() {
+	if q.metrics != nil {
+		q.metrics.AlertOutboxPending.Set(float64(q.outbox.Len()))
+	}
+}
+
+func (q *dispatcher) observeDelivery(name, result string) {
+	if q.metrics != nil {
+		q.metrics.ObserveAlertDelivery(name, result)
+	}
+}
+
+// retryDelay reports whether err is transient and how long to wait before
+// the next attempt: the channel's Retry-After if it sent one, otherwise
+// backoff. Network errors, timeouts, HTTP 429 and 5xx, and SMTP 4xx replies
+// are transient; anything else, e.g. a bad webhook URL or a rejected
+// recipient, fails the same way on every attempt.
+func retryDelay(err error, backoff time.Duration) (time.Duration, bool) {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		if statusErr.StatusCode != http.StatusTooManyRequests && statusErr.StatusCode < 500 {
+			return 0, false
+		}
+		if statusErr.RetryAfter > 0 {
+			return min(statusErr.RetryAfter, maxRetryDelay), true
+		}
+		return backoff, true
+	}
+
+	var smtpErr *textproto.Error
+	if errors.As(err, &smtpErr) {
+		if smtpErr.Code < 400 || smtpErr.Code >= 500 {
+			return 0, false
+		}
+		return backoff, true
+	}
+
+	// Not any net.Error: *url.Error is one, also for a malformed URL
+	var (
+		netErr net.Error
+		opErr  *net.OpError
+		dnsErr *net.DNSError
+	)
+	switch {
+	case errors.As(err, &netErr) && netErr.Timeout(),
+		errors.As(err, &opErr),
+		errors.As(err, &dnsErr),
+		errors.Is(err, context.DeadlineExceeded),
+		errors.Is(err, io.EOF),
+		errors.Is(err, io.ErrUnexpectedEOF):
+		return backoff, true
+	}
+	return 0, false
+}
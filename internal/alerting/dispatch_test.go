@@ -0,0 +1,264 @@
+package alerting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"uptiq/internal/config"
+	"uptiq/internal/metrics"
+)
+
+func newTestDispatcher(t *testing.T) (*dispatcher, *metrics.Collector) {
+	t.Helper()
+
+	m := metrics.NewBundle().Collector
+	q := newDispatcher(NewChannelSender(), slog.New(slog.NewTextHandler(io.Discard, nil)), m)
+	q.retryDelay = time.Millisecond
+	t.Cleanup(func() { _ = q.close(context.Background()) })
+	return q, m
+}
+
+// statusServer answers with the given statuses in turn, then 200.
+func statusServer(t *testing.T, statuses ...int) (*httptest.Server, *int32) {
+	t.Helper()
+
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(atomic.AddInt32(&calls, 1))
+		if n <= len(statuses) {
+			w.WriteHeader(statuses[n-1])
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func slackDelivery(name, webhookURL, kind string) delivery {
+	return delivery{
+		key:     name + ":" + kind,
+		name:    name,
+		channel: config.Channel{Type: "slack", WebhookURL: webhookURL},
+		service: config.Service{ID: "web", Name: "Web"},
+		payload: AlertPayload{Kind: kind, WebhookMessage: kind},
+	}
+}
+
+func TestDispatcher_Retries(t *testing.T) {
+	tests := []struct {
+		name        string
+		statuses    []int
+		wantCalls   int32
+		wantRetries float64
+		wantResult  string
+	}{
+		{name: "delivered first time", wantCalls: 1, wantResult: metrics.AlertDelivered},
+		{name: "server error then delivered", statuses: []int{503, 500}, wantCalls: 3, wantRetries: 2, wantResult: metrics.AlertDelivered},
+		{name: "rate limited then delivered", statuses: []int{429}, wantCalls: 2, wantRetries: 1, wantResult: metrics.AlertDelivered},
+		{name: "client error not retried", statuses: []int{400}, wantCalls: 1, wantResult: metrics.AlertFailed},
+		{name: "gives up after max attempts", statuses: []int{502, 502, 502, 502, 502, 502}, wantCalls: maxSendAttempts, wantRetries: maxSendAttempts - 1, wantResult: metrics.AlertFailed},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			q, m := newTestDispatcher(t)
+			server, calls := statusServer(t, tc.statuses...)
+
+			q.enqueue(slackDelivery("slack", server.URL, "down"))
+			q.flush()
+
+			if got := atomic.LoadInt32(calls); got != tc.wantCalls {
+				t.Errorf("calls = %d, want %d", got, tc.wantCalls)
+			}
+			if got := testutil.ToFloat64(m.AlertRetries.WithLabelValues("slack")); got != tc.wantRetries {
+				t.Errorf("retries = %v, want %v", got, tc.wantRetries)
+			}
+			if got := testutil.ToFloat64(m.AlertDeliveries.WithLabelValues("slack", tc.wantResult)); got != 1 {
+				t.Errorf("deliveries{result=%q} = %v, want 1", tc.wantResult, got)
+			}
+		})
+	}
+}
+
+func TestDispatcher_RetriesReuseMatrixTxnID(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		paths []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		first := len(paths) == 1
+		mu.Unlock()
+
+		if first {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	q, _ := newTestDispatcher(t)
+	d := slackDelivery("matrix", server.URL, "down")
+	d.channel = config.Channel{Type: "matrix", APIURL: server.URL, Token: "t", RoomID: "!room"}
+	q.enqueue(d)
+	q.flush()
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := "/_matrix/client/v3/rooms/!room/send/m.room.message/" + matrixTxnID(AlertPayload{DeliveryKey: d.key})
+	if len(paths) != 2 || paths[0] != want || paths[1] != want {
+		t.Errorf("paths = %v, want both attempts at %s", paths, want)
+	}
+}
+
+func TestDispatcher_KeepsOrderPerChannel(t *testing.T) {
+	q, _ := newTestDispatcher(t)
+
+	var (
+		mu   sync.Mutex
+		got  []string
+		fail int32 = 1
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The first alert needs a retry; the second must still go out after it
+		if atomic.CompareAndSwapInt32(&fail, 1, 0) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		got = append(got, string(body))
+		mu.Unlock()
+	}))
+	t.Cleanup(server.Close)
+
+	q.enqueue(slackDelivery("slack", server.URL, "down"))
+	q.enqueue(slackDelivery("slack", server.URL, "recovery"))
+	q.flush()
+
+	want := []string{`{"text":"down"}`, `{"text":"recovery"}`}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("delivered %v, want %v", got, want)
+	}
+}
+
+func TestDispatcher_SlowChannelDoesNotBlock(t *testing.T) {
+	q, _ := newTestDispatcher(t)
+
+	release := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	t.Cleanup(slow.Close)
+	t.Cleanup(func() { close(release) })
+	fast, calls := statusServer(t)
+
+	start := time.Now()
+	q.enqueue(slackDelivery("slow", slow.URL, "down"))
+	q.enqueue(slackDelivery("fast", fast.URL, "down"))
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("enqueue took %v, want it not to wait for sends", elapsed)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for atomic.LoadInt32(calls) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("fast channel was held up by the slow one")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestDispatcher_CloseAbandonsAfterTimeout(t *testing.T) {
+	q, m := newTestDispatcher(t)
+	q.retryDelay = time.Hour
+	server, _ := statusServer(t, 503)
+
+	q.enqueue(slackDelivery("slack", server.URL, "down"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := q.close(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("close = %v, want deadline exceeded", err)
+	}
+	if got := testutil.ToFloat64(m.AlertDeliveries.WithLabelValues("slack", metrics.AlertFailed)); got != 1 {
+		t.Errorf("failed deliveries = %v, want 1", got)
+	}
+
+	// Nothing is accepted once closed
+	q.enqueue(slackDelivery("slack", server.URL, "recovery"))
+	if got := testutil.ToFloat64(m.AlertDeliveries.WithLabelValues("slack", metrics.AlertDropped)); got != 1 {
+		t.Errorf("dropped deliveries = %v, want 1", got)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	backoff := 4 * time.Second
+
+	tests := []struct {
+		name      string
+		err       error
+		wantDelay time.Duration
+		wantRetry bool
+	}{
+		{name: "server error", err: &StatusError{StatusCode: 502}, wantDelay: backoff, wantRetry: true},
+		{name: "rate limited", err: &StatusError{StatusCode: 429}, wantDelay: backoff, wantRetry: true},
+		{name: "retry after", err: &StatusError{StatusCode: 429, RetryAfter: 30 * time.Second}, wantDelay: 30 * time.Second, wantRetry: true},
+		{name: "retry after capped", err: &StatusError{StatusCode: 503, RetryAfter: time.Hour}, wantDelay: maxRetryDelay, wantRetry: true},
+		{name: "client error", err: &StatusError{StatusCode: 404}},
+		{name: "connection refused", err: &url.Error{Op: "Post", URL: "http://x", Err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}}, wantDelay: backoff, wantRetry: true},
+		{name: "timeout", err: fmt.Errorf("send: %w", context.DeadlineExceeded), wantDelay: backoff, wantRetry: true},
+		{name: "malformed url", err: &url.Error{Op: "Post", URL: "x", Err: errors.New("unsupported protocol scheme")}},
+		{name: "smtp temporary", err: fmt.Errorf("rcpt to: %w", &textproto.Error{Code: 451, Msg: "try later"}), wantDelay: backoff, wantRetry: true},
+		{name: "smtp permanent", err: fmt.Errorf("rcpt to: %w", &textproto.Error{Code: 550, Msg: "no such user"})},
+		{name: "config error", err: errors.New("smtp_host is empty")},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			delay, retry := retryDelay(tc.err, backoff)
+			if retry != tc.wantRetry || delay != tc.wantDelay {
+				t.Errorf("retryDelay = (%v, %v), want (%v, %v)", delay, retry, tc.wantDelay, tc.wantRetry)
+			}
+		})
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{value: "", want: 0},
+		{value: "120", want: 2 * time.Minute},
+		{value: "-5", want: 0},
+		{value: now.Add(90 * time.Second).Format(http.TimeFormat), want: 90 * time.Second},
+		{value: now.Add(-time.Minute).Format(http.TimeFormat), want: 0},
+		{value: "soon", want: 0},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.value, func(t *testing.T) {
+			if got := parseRetryAfter(tc.value, now); got != tc.want {
+				t.Errorf("parseRetryAfter(%q) = %v, want %v", tc.value, got, tc.want)
+			}
+		})
+	}
+}
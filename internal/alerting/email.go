@@ -3,7 +3,9 @@ package alerting
 import (
 	"bytes"
 	"context"
+	"crypto/rand"
 	"crypto/tls"
+	"encoding/hex"
 	"fmt"
 	"net"
 	"net/mail"
@@ -21,8 +23,9 @@ const (
 	emailMinTLS       = tls.VersionTLS12
 )
 
-// sendEmail sends an email alert using SMTP.
-func sendEmail(ctx context.Context, ch config.Channel, subject, body string) error {
+// sendEmail sends an email alert using SMTP. A non-empty html is sent as an
+// alternative to the plain text body.
+func sendEmail(ctx context.Context, ch config.Channel, subject, body, html string) error {
 	if err := validateEmailConfig(ch); err != nil {
 		return err
 	}
@@ -37,7 +40,7 @@ func sendEmail(ctx context.Context, ch config.Channel, subject, body string) err
 		return err
 	}
 
-	msg := buildEmailMessage(ch.From, toHeaders, subject, body)
+	msg := buildEmailMessage(ch.From, toHeaders, subject, body, html)
 
 	return sendSMTP(ctx, ch, fromAddr, toAddrs, msg)
 }
@@ -200,7 +203,7 @@ func dialWithContext(ctx context.Context, addr string) (net.Conn, error) {
 	}
 }
 
-func buildEmailMessage(from string, to []string, subject, body string) []byte {
+func buildEmailMessage(from string, to []string, subject, body, html string) []byte {
 	var buf bytes.Buffer
 
 	writeHeader(&buf, "From", from)
@@ -208,21 +211,49 @@ func buildEmailMessage(from string, to []string, subject, body string) []byte {
 	writeHeader(&buf, "Subject", sanitizeHeader(subject))
 	writeHeader(&buf, "Date", time.Now().Format(time.RFC1123Z))
 	writeHeader(&buf, "MIME-Version", "1.0")
-	writeHeader(&buf, "Content-Type", `text/plain; charset="utf-8"`)
-	writeHeader(&buf, "Content-Transfer-Encoding", "8bit")
+
+	if html == "" {
+		writeEmailPart(&buf, `text/plain; charset="utf-8"`, body)
+		return buf.Bytes()
+	}
+
+	// Clients show the last part they support, so HTML goes last
+	boundary := emailBoundary()
+	writeHeader(&buf, "Content-Type", `multipart/alternative; boundary="`+boundary+`"`)
+	buf.WriteString("\r\n")
+	buf.WriteString("--" + boundary + "\r\n")
+	writeEmailPart(&buf, `text/plain; charset="utf-8"`, body)
+	buf.WriteString("--" + boundary + "\r\n")
+	writeEmailPart(&buf, `text/html; charset="utf-8"`, html)
+	buf.WriteString("--" + boundary + "--\r\n")
+
+	return buf.Bytes()
+}
+
+// writeEmailPart writes the content headers and the content, with CRLF
+// line endings.
+func writeEmailPart(buf *bytes.Buffer, contentType, content string) {
+	writeHeader(buf, "Content-Type", contentType)
+	writeHeader(buf, "Content-Transfer-Encoding", "8bit")
 	buf.WriteString("\r\n")
 
 	// Normalize line endings to CRLF
-	body = strings.ReplaceAll(body, "\r\n", "\n")
-	body = strings.ReplaceAll(body, "\r", "\n")
-	body = strings.ReplaceAll(body, "\n", "\r\n")
-	buf.WriteString(body)
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	content = strings.ReplaceAll(content, "\r", "\n")
+	content = strings.ReplaceAll(content, "\n", "\r\n")
+	buf.WriteString(content)
 
-	if !strings.HasSuffix(body, "\r\n") {
+	if !strings.HasSuffix(content, "\r\n") {
 		buf.WriteString("\r\n")
 	}
+}
 
-	return buf.Bytes()
+// emailBoundary returns a random multipart boundary, which cannot occur in
+// the parts by chance.
+func emailBoundary() string {
+	var b [12]byte
+	_, _ = rand.Read(b[:])
+	return "uptiq-" + hex.EncodeToString(b[:])
 }
 
 func writeHeader(buf *bytes.Buffer, key, value string) {
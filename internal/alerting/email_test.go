@@ -0,0 +1,84 @@
+package alerting
+
+import (
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"strings"
+	"testing"
+)
+
+func TestBuildEmailMessage(t *testing.T) {
+	tests := []struct {
+		name      string
+		html      string
+		wantParts map[string]string // Content type -> content; nil: plain text only
+	}{
+		{
+			name: "plain text",
+		},
+		{
+			name: "with html",
+			html: "<p>down</p>",
+			wantParts: map[string]string{
+				"text/plain": "line one\r\nline two",
+				"text/html":  "<p>down</p>",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			raw := buildEmailMessage("a@example.com", []string{"b@example.com"}, "[DOWN] Web\r\nBcc: x", "line one\nline two", tc.html)
+
+			msg, err := mail.ReadMessage(strings.NewReader(string(raw)))
+			if err != nil {
+				t.Fatalf("parse message: %v", err)
+			}
+			if got := msg.Header.Get("Subject"); got != "[DOWN] Web  Bcc: x" {
+				t.Errorf("Subject = %q", got)
+			}
+
+			mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+			if err != nil {
+				t.Fatalf("parse content type: %v", err)
+			}
+
+			if tc.wantParts == nil {
+				if mediaType != "text/plain" {
+					t.Errorf("Content-Type = %q, want text/plain", mediaType)
+				}
+				body, _ := io.ReadAll(msg.Body)
+				if string(body) != "line one\r\nline two\r\n" {
+					t.Errorf("body = %q", body)
+				}
+				return
+			}
+
+			if mediaType != "multipart/alternative" {
+				t.Fatalf("Content-Type = %q, want multipart/alternative", mediaType)
+			}
+			reader := multipart.NewReader(msg.Body, params["boundary"])
+			var order []string
+			for {
+				part, err := reader.NextPart()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatalf("read part: %v", err)
+				}
+				partType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+				content, _ := io.ReadAll(part)
+				if want, ok := tc.wantParts[partType]; !ok || string(content) != want {
+					t.Errorf("part %s = %q, want %q", partType, content, want)
+				}
+				order = append(order, partType)
+			}
+			if strings.Join(order, ",") != "text/plain,text/html" {
+				t.Errorf("parts = %v, want text/plain then text/html", order)
+			}
+		})
+	}
+}
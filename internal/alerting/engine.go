@@ -6,68 +6,204 @@ import (
 	"fmt"
 	"log/slog"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"uptiq/internal/checks"
 	"uptiq/internal/config"
+	"uptiq/internal/metrics"
 )
 
+// defaultPolicy applies to services that match no route.
+var defaultPolicy = ResolvedPolicy{FailureThreshold: 1}
+
+// MaintenanceChecker reports whether a service is inside a maintenance window.
+type MaintenanceChecker interface {
+	InMaintenance(svc config.Service) bool
+}
+
 // Engine manages alert state and dispatches notifications.
 type Engine struct {
 	log      *slog.Logger
-	channels map[string]config.Channel
-	router   *Router
 	state    *StateManager
 	sender   *ChannelSender
 	messages *MessageBuilder
+	queue    *dispatcher
+
+	// Alerts not yet delivered, left out of DeliveredSnapshot
+	undelivered *undeliveredAlerts
+
+	mu          sync.RWMutex
+	channels    map[string]config.Channel
+	router      *Router
+	services    []config.Service
+	maintenance MaintenanceChecker
+
+	// A standby leaves state alone and sends nothing; see SetStandby.
+	standby atomic.Bool
 }
 
-// NewEngine creates an alerting engine from configuration.
-func NewEngine(cfg config.AlertingConfig, log *slog.Logger) *Engine {
+// NewEngine creates an alerting engine from configuration. m may be nil.
+func NewEngine(cfg config.AlertingConfig, log *slog.Logger, m *metrics.Collector) *Engine {
 	if log == nil {
 		log = slog.Default()
 	}
 
-	return &Engine{
-		log:      log,
-		channels: cfg.Channels,
-		router:   NewRouter(cfg),
-		state:    NewStateManager(),
-		sender:   NewChannelSender(),
-		messages: NewMessageBuilder(),
+	e := &Engine{
+		log:         log,
+		channels:    cfg.Channels,
+		router:      NewRouter(cfg),
+		state:       NewStateManager(),
+		sender:      NewChannelSender(),
+		messages:    NewMessageBuilder(),
+		undelivered: newUndeliveredAlerts(),
 	}
+	e.queue = newDispatcher(e.sender, log, m)
+	e.queue.logResult = e.logSendResult
+	e.queue.onDone = e.undelivered.done
+	return e
+}
+
+// SetOutbox persists alerts in o until they are delivered, and sends the
+// ones it still holds from a previous run. Call before the first result.
+func (e *Engine) SetOutbox(o *Outbox) {
+	e.queue.useOutbox(o, e.channel)
+}
+
+// channel returns the current settings of the named channel.
+func (e *Engine) channel(name string) (config.Channel, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	ch, ok := e.channels[name]
+	return ch, ok
+}
+
+// Close stops accepting alerts and waits until ctx is done for the queued
+// ones, including retries, to be sent.
+func (e *Engine) Close(ctx context.Context) error {
+	return e.queue.close(ctx)
+}
+
+// UpdateConfig swaps routes and channels. Alert state is kept, so outages
+// in progress are neither re-alerted nor forgotten.
+func (e *Engine) UpdateConfig(cfg config.AlertingConfig) {
+	router := NewRouter(cfg)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.router = router
+	e.channels = cfg.Channels
+}
+
+// SetStandby switches between leading, where results drive alert state and
+// alerts are sent, and standing by, where results are ignored, state only
+// changes through Restore and the outbox is left to the leader.
+func (e *Engine) SetStandby(standby bool) {
+	e.standby.Store(standby)
+	e.queue.setStandby(standby)
+}
+
+// OnAlert installs a function called after every alert is delivered.
+func (e *Engine) OnAlert(fn func()) {
+	e.queue.setOnSent(fn)
+}
+
+// Snapshot returns the alert state of every service.
+func (e *Engine) Snapshot() map[string]ServiceState {
+	return e.state.Snapshot()
+}
+
+// DeliveredSnapshot returns the alert state of every service as far as its
+// alerts were delivered: an alert still queued, retrying or waiting in the
+// outbox is left out, so a standby taking over from this state sends it
+// again rather than assuming it went out.
+func (e *Engine) DeliveredSnapshot() map[string]ServiceState {
+	snapshot := e.state.Snapshot()
+	e.undelivered.apply(snapshot)
+	return snapshot
+}
+
+// Restore replaces the alert state of every service, e.g. with the state
+// replicated from an HA leader.
+func (e *Engine) Restore(snapshot map[string]ServiceState) {
+	e.state.Restore(snapshot)
 }
 
 // HandleResult processes a check result and sends alerts as needed.
 func (e *Engine) HandleResult(svc config.Service, res checks.Result) {
-	route := e.router.Resolve(svc.ID)
+	if e.standby.Load() {
+		return
+	}
+
+	e.mu.RLock()
+	router, channels := e.router, e.channels
+	e.mu.RUnlock()
+
+	route := router.Resolve(svc.ID)
+
+	// Unrouted services still track state so their dependents can be suppressed
+	policy := route.Policy
 	if !route.Valid {
-		return // No routing configured for this service
+		policy = defaultPolicy
 	}
 
+	parent, blocked := e.downDependency(svc)
+	inMaintenance := e.inMaintenance(svc)
+
 	now := time.Now()
-	var payload *AlertPayload
+	var (
+		payload *AlertPayload
+		before  ServiceState
+	)
 
 	e.state.WithState(svc.ID, func(st *ServiceState) {
+		before = *st
 		st.LastResultAt = now
+		if !res.Success && st.ConsecutiveFailures == 0 {
+			st.FailingSince = now
+		}
 
-		if res.Success {
-			payload = e.handleSuccess(svc, res, st, route)
-		} else {
-			payload = e.handleFailure(svc, res, st, route, now)
+		switch {
+		case inMaintenance:
+			payload = e.handleMaintenance(svc, res, st, policy)
+		case res.Success:
+			payload = e.handleSuccess(svc, res, st, policy)
+		case blocked:
+			e.handleUnreachable(st)
+		default:
+			payload = e.handleFailure(svc, res, st, policy, now)
+		}
+
+		if payload != nil {
+			payload.Context.Since = st.FailingSince
 		}
 	})
 
-	if payload != nil {
-		e.dispatch(route.Channels, svc, *payload)
+	if blocked && !res.Success && !inMaintenance {
+		e.log.Debug("alert suppressed; dependency down",
+			"service_id", svc.ID,
+			"service_name", svc.Name,
+			"depends_on", parent,
+		)
+	}
+
+	if payload != nil && route.Valid {
+		if inMaintenance {
+			route.Channels = incidentChannels(channels, route.Channels)
+		}
+		e.dispatch(channels, route, svc, *payload, before)
 	}
 }
 
-func (e *Engine) handleSuccess(svc config.Service, res checks.Result, st *ServiceState, route ResolvedRoute) *AlertPayload {
+func (e *Engine) handleSuccess(svc config.Service, res checks.Result, st *ServiceState, policy ResolvedPolicy) *AlertPayload {
 	st.ConsecutiveFailures = 0
 
-	// Send recovery alert if transitioning from DOWN and we had notified
-	if st.State == StateDown && route.Policy.RecoveryAlert && st.DownNotified {
+	// Send recovery alert if transitioning from an outage and we had notified
+	outage := st.State == StateDown || st.State == StateUnreachable
+	if outage && policy.RecoveryAlert && st.DownNotified {
 		payload := e.messages.RecoveryAlert(svc, res)
 		st.DownNotified = false
 		st.State = StateUp
@@ -79,11 +215,40 @@ func (e *Engine) handleSuccess(svc config.Service, res checks.Result, st *Servic
 	return nil
 }
 
-func (e *Engine) handleFailure(svc config.Service, res checks.Result, st *ServiceState, route ResolvedRoute, now time.Time) *AlertPayload {
+// handleMaintenance tracks results during maintenance without alerting.
+// An outage that ends inside the window is closed silently, except in
+// incident tools: the returned recovery, meant for incident channels only,
+// resolves the incident its DOWN alert opened.
+func (e *Engine) handleMaintenance(svc config.Service, res checks.Result, st *ServiceState, policy ResolvedPolicy) *AlertPayload {
+	st.State = StateMaintenance
+	if !res.Success {
+		st.ConsecutiveFailures++
+		return nil
+	}
+
+	st.ConsecutiveFailures = 0
+	if !st.DownNotified {
+		return nil
+	}
+	st.DownNotified = false
+	if !policy.RecoveryAlert {
+		return nil
+	}
+	payload := e.messages.RecoveryAlert(svc, res)
+	return &payload
+}
+
+// handleUnreachable records a failure caused by a down parent without alerting.
+func (e *Engine) handleUnreachable(st *ServiceState) {
+	st.ConsecutiveFailures++
+	st.State = StateUnreachable
+}
+
+func (e *Engine) handleFailure(svc config.Service, res checks.Result, st *ServiceState, policy ResolvedPolicy, now time.Time) *AlertPayload {
 	st.ConsecutiveFailures++
 
 	// Not yet considered "down" until threshold reached
-	if st.ConsecutiveFailures < route.Policy.FailureThreshold {
+	if st.ConsecutiveFailures < policy.FailureThreshold {
 		if st.State == StateUnknown {
 			st.State = StateUp
 		}
@@ -94,26 +259,88 @@ func (e *Engine) handleFailure(svc config.Service, res checks.Result, st *Servic
 	wasDown := st.State == StateDown
 	st.State = StateDown
 
-	canSendAlert := e.canSendDownAlert(st, route.Policy, now)
+	canSendAlert := e.canSendDownAlert(st, policy, now)
 
 	// First DOWN alert of an outage
 	if !st.DownNotified && canSendAlert {
 		st.DownNotified = true
 		st.LastDownAlertAt = now
-		payload := e.messages.DownAlert(svc, res, st.ConsecutiveFailures, route.Policy.FailureThreshold)
+		payload := e.messages.DownAlert(svc, res, st.ConsecutiveFailures, policy.FailureThreshold)
 		return &payload
 	}
 
 	// Reminder while still down (cooldown elapsed)
 	if wasDown && st.DownNotified && canSendAlert {
 		st.LastDownAlertAt = now
-		payload := e.messages.StillDownAlert(svc, res, st.ConsecutiveFailures, route.Policy.FailureThreshold)
+		payload := e.messages.StillDownAlert(svc, res, st.ConsecutiveFailures, policy.FailureThreshold)
 		return &payload
 	}
 
 	return nil
 }
 
+// downDependency returns the first parent of svc that is down, unreachable,
+// or failing inside a maintenance window.
+func (e *Engine) downDependency(svc config.Service) (string, bool) {
+	for _, id := range svc.DependsOn {
+		st, ok := e.state.Lookup(id)
+		if !ok {
+			continue
+		}
+		switch {
+		case st.State == StateDown, st.State == StateUnreachable:
+			return id, true
+		case st.State == StateMaintenance && st.ConsecutiveFailures > 0:
+			return id, true
+		}
+	}
+	return "", false
+}
+
+// OutageConfirmed reports whether a failing service's outage is established,
+// so more frequent checks would tell nothing new: it is DOWN, UNREACHABLE
+// because a dependency is down, or in maintenance with failures that would
+// otherwise have made it DOWN.
+func (e *Engine) OutageConfirmed(serviceID string) bool {
+	st, ok := e.state.Lookup(serviceID)
+	if !ok {
+		return false
+	}
+
+	switch st.State {
+	case StateDown, StateUnreachable:
+		return true
+	case StateMaintenance:
+		e.mu.RLock()
+		route := e.router.Resolve(serviceID)
+		e.mu.RUnlock()
+
+		threshold := defaultPolicy.FailureThreshold
+		if route.Valid {
+			threshold = route.Policy.FailureThreshold
+		}
+		return st.ConsecutiveFailures >= threshold
+	default:
+		return false
+	}
+}
+
+// SetMaintenance installs the maintenance window source.
+func (e *Engine) SetMaintenance(m MaintenanceChecker) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.maintenance = m
+}
+
+func (e *Engine) inMaintenance(svc config.Service) bool {
+	e.mu.RLock()
+	m := e.maintenance
+	e.mu.RUnlock()
+
+	return m != nil && m.InMaintenance(svc)
+}
+
 func (e *Engine) canSendDownAlert(st *ServiceState, policy ResolvedPolicy, now time.Time) bool {
 	if policy.Cooldown <= 0 {
 		return true
@@ -124,12 +351,13 @@ func (e *Engine) canSendDownAlert(st *ServiceState, policy ResolvedPolicy, now t
 	return now.Sub(st.LastDownAlertAt) >= policy.Cooldown
 }
 
-func (e *Engine) dispatch(channelNames []string, svc config.Service, payload AlertPayload) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	for _, name := range channelNames {
-		ch, ok := e.channels[name]
+// dispatch renders payload with each channel's templates, overridden by the
+// route's, and queues it; see dispatcher. before is the service's state
+// ahead of the alert, replicated until the alert is delivered.
+func (e *Engine) dispatch(channels map[string]config.Channel, route ResolvedRoute, svc config.Service, payload AlertPayload, before ServiceState) {
+	now := time.Now()
+	for _, name := range route.Channels {
+		ch, ok := channels[name]
 		if !ok {
 			e.log.Warn("alert channel missing",
 				"channel", name,
@@ -139,18 +367,37 @@ func (e *Engine) dispatch(channelNames []string, svc config.Service, payload Ale
 			continue
 		}
 
-		result := e.sender.Send(ctx, ch, payload)
-		e.logSendResult(name, ch, svc, payload.Kind, result)
+		rendered, err := applyTemplates(mergeTemplates(route.Templates, ch.Templates), payload)
+		if err != nil {
+			e.log.Warn("alert template failed; using built-in message",
+				"channel", name,
+				"service_id", svc.ID,
+				"service_name", svc.Name,
+				"error", err,
+			)
+		}
+
+		key := deliveryKey(svc.ID, payload.Kind, name, now)
+		e.undelivered.add(key, svc.ID, before)
+		e.queue.submit(delivery{
+			key:      key,
+			name:     name,
+			channel:  ch,
+			service:  svc,
+			payload:  rendered,
+			queuedAt: now,
+		})
 	}
 }
 
-func (e *Engine) logSendResult(channelName string, ch config.Channel, svc config.Service, kind string, result SendResult) {
+func (e *Engine) logSendResult(d delivery, result SendResult) {
+	ch := d.channel
 	channelType := strings.ToLower(strings.TrimSpace(ch.Type))
 	baseFields := []any{
-		"channel", channelName,
-		"service_id", svc.ID,
-		"service_name", svc.Name,
-		"kind", kind,
+		"channel", d.name,
+		"service_id", d.service.ID,
+		"service_name", d.service.Name,
+		"kind", d.payload.Kind,
 	}
 
 	// Add type-specific fields
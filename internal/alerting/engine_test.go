@@ -0,0 +1,568 @@
+package alerting
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"uptiq/internal/checks"
+	"uptiq/internal/config"
+)
+
+// newTestEngine returns an engine routing the given services to a local Slack stand-in.
+func newTestEngine(t *testing.T, serviceIDs ...string) (*Engine, *int32) {
+	t.Helper()
+
+	var sent int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&sent, 1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	cfg := config.AlertingConfig{
+		Channels: map[string]config.Channel{
+			"slack": {Type: "slack", WebhookURL: server.URL},
+		},
+		Routes: []config.Route{
+			{
+				Match:  config.RouteMatch{ServiceIDs: serviceIDs},
+				Policy: config.RoutePolicy{FailureThreshold: 1, RecoveryAlert: true},
+				Notify: []string{"slack"},
+			},
+		},
+	}
+
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	return NewEngine(cfg, log, nil), &sent
+}
+
+func TestEngine_HandleResult_DownAndRecovery(t *testing.T) {
+	engine, sent := newTestEngine(t, "web")
+	svc := config.Service{ID: "web", Name: "Web", Type: "http"}
+
+	engine.HandleResult(svc, checks.Result{Success: false, Error: "boom"})
+	engine.queue.flush()
+	if got := atomic.LoadInt32(sent); got != 1 {
+		t.Fatalf("alerts after failure = %d, want 1", got)
+	}
+	if st, _ := engine.state.Lookup("web"); st.State != StateDown {
+		t.Errorf("state = %q, want %q", st.State, StateDown)
+	}
+
+	engine.HandleResult(svc, checks.Result{Success: true})
+	engine.queue.flush()
+	if got := atomic.LoadInt32(sent); got != 2 {
+		t.Errorf("alerts after recovery = %d, want 2", got)
+	}
+}
+
+func TestEngine_HandleResult_UnroutedServiceTracksState(t *testing.T) {
+	engine, sent := newTestEngine(t, "other")
+	svc := config.Service{ID: "core-router", Name: "Core Router", Type: "tcp"}
+
+	engine.HandleResult(svc, checks.Result{Success: false})
+
+	engine.queue.flush()
+	if got := atomic.LoadInt32(sent); got != 0 {
+		t.Errorf("alerts for unrouted service = %d, want 0", got)
+	}
+	if st, _ := engine.state.Lookup("core-router"); st.State != StateDown {
+		t.Errorf("state = %q, want %q", st.State, StateDown)
+	}
+}
+
+func TestEngine_HandleResult_SuppressesDependents(t *testing.T) {
+	engine, sent := newTestEngine(t, "lb", "app")
+	parent := config.Service{ID: "lb", Name: "Load Balancer", Type: "tcp"}
+	child := config.Service{ID: "app", Name: "App", Type: "http", DependsOn: []string{"lb"}}
+
+	engine.HandleResult(parent, checks.Result{Success: false})
+	engine.HandleResult(child, checks.Result{Success: false})
+	engine.HandleResult(child, checks.Result{Success: false})
+
+	engine.queue.flush()
+	if got := atomic.LoadInt32(sent); got != 1 {
+		t.Errorf("alerts = %d, want 1 (parent only)", got)
+	}
+
+	st, _ := engine.state.Lookup("app")
+	if st.State != StateUnreachable {
+		t.Errorf("child state = %q, want %q", st.State, StateUnreachable)
+	}
+	if st.ConsecutiveFailures != 2 {
+		t.Errorf("child failures = %d, want 2", st.ConsecutiveFailures)
+	}
+	if st.DownNotified {
+		t.Error("child should not be marked as notified")
+	}
+
+	// Child recovering while unreachable sends no recovery (it never alerted)
+	engine.HandleResult(child, checks.Result{Success: true})
+	engine.queue.flush()
+	if got := atomic.LoadInt32(sent); got != 1 {
+		t.Errorf("alerts after silent child recovery = %d, want 1", got)
+	}
+}
+
+func TestEngine_HandleResult_TransitiveSuppression(t *testing.T) {
+	engine, sent := newTestEngine(t, "router", "db", "api")
+	router := config.Service{ID: "router", Name: "Router", Type: "tcp"}
+	db := config.Service{ID: "db", Name: "DB", Type: "tcp", DependsOn: []string{"router"}}
+	api := config.Service{ID: "api", Name: "API", Type: "http", DependsOn: []string{"db"}}
+
+	engine.HandleResult(router, checks.Result{Success: false})
+	engine.HandleResult(db, checks.Result{Success: false})
+	engine.HandleResult(api, checks.Result{Success: false})
+
+	engine.queue.flush()
+	if got := atomic.LoadInt32(sent); got != 1 {
+		t.Errorf("alerts = %d, want 1", got)
+	}
+	if st, _ := engine.state.Lookup("api"); st.State != StateUnreachable {
+		t.Errorf("api state = %q, want %q", st.State, StateUnreachable)
+	}
+}
+
+func TestEngine_HandleResult_AlertsOnceParentRecovers(t *testing.T) {
+	engine, sent := newTestEngine(t, "lb", "app")
+	parent := config.Service{ID: "lb", Name: "Load Balancer", Type: "tcp"}
+	child := config.Service{ID: "app", Name: "App", Type: "http", DependsOn: []string{"lb"}}
+
+	engine.HandleResult(parent, checks.Result{Success: false})
+	engine.HandleResult(child, checks.Result{Success: false})
+	engine.HandleResult(parent, checks.Result{Success: true})
+	engine.HandleResult(child, checks.Result{Success: false})
+
+	// Parent down + parent recovery + child down
+	engine.queue.flush()
+	if got := atomic.LoadInt32(sent); got != 3 {
+		t.Errorf("alerts = %d, want 3", got)
+	}
+	if st, _ := engine.state.Lookup("app"); st.State != StateDown {
+		t.Errorf("child state = %q, want %q", st.State, StateDown)
+	}
+}
+
+type stubMaintenance map[string]bool
+
+func (m stubMaintenance) InMaintenance(svc config.Service) bool { return m[svc.ID] }
+
+func TestEngine_HandleResult_MaintenanceSuppressesAlerts(t *testing.T) {
+	engine, sent := newTestEngine(t, "web")
+	maint := stubMaintenance{"web": true}
+	engine.SetMaintenance(maint)
+	svc := config.Service{ID: "web", Name: "Web", Type: "http"}
+
+	engine.HandleResult(svc, checks.Result{Success: false})
+	engine.HandleResult(svc, checks.Result{Success: false})
+
+	engine.queue.flush()
+	if got := atomic.LoadInt32(sent); got != 0 {
+		t.Errorf("alerts during maintenance = %d, want 0", got)
+	}
+	st, _ := engine.state.Lookup("web")
+	if st.State != StateMaintenance {
+		t.Errorf("state = %q, want %q", st.State, StateMaintenance)
+	}
+	if st.ConsecutiveFailures != 2 {
+		t.Errorf("failures = %d, want 2", st.ConsecutiveFailures)
+	}
+
+	// Still failing once the window ends: alert immediately
+	maint["web"] = false
+	engine.HandleResult(svc, checks.Result{Success: false})
+	engine.queue.flush()
+	if got := atomic.LoadInt32(sent); got != 1 {
+		t.Errorf("alerts after maintenance = %d, want 1", got)
+	}
+}
+
+func TestEngine_HandleResult_MaintenanceClosesOutageSilently(t *testing.T) {
+	engine, sent := newTestEngine(t, "web")
+	maint := stubMaintenance{}
+	engine.SetMaintenance(maint)
+	svc := config.Service{ID: "web", Name: "Web", Type: "http"}
+
+	engine.HandleResult(svc, checks.Result{Success: false})
+	maint["web"] = true
+	engine.HandleResult(svc, checks.Result{Success: true})
+	maint["web"] = false
+	engine.HandleResult(svc, checks.Result{Success: true})
+
+	engine.queue.flush()
+	if got := atomic.LoadInt32(sent); got != 1 {
+		t.Errorf("alerts = %d, want 1 (down only, no recovery)", got)
+	}
+	if st, _ := engine.state.Lookup("web"); st.State != StateUp {
+		t.Errorf("state = %q, want %q", st.State, StateUp)
+	}
+}
+
+func TestEngine_HandleResult_MaintenanceResolvesIncidents(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		actions []string
+	)
+	pagerDuty := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event pagerDutyEvent
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			t.Errorf("decode event: %v", err)
+		}
+		mu.Lock()
+		actions = append(actions, event.EventAction)
+		mu.Unlock()
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	t.Cleanup(pagerDuty.Close)
+
+	var chats int32
+	slack := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&chats, 1)
+	}))
+	t.Cleanup(slack.Close)
+
+	cfg := config.AlertingConfig{
+		Channels: map[string]config.Channel{
+			"slack":     {Type: "slack", WebhookURL: slack.URL},
+			"pagerduty": {Type: "pagerduty", RoutingKey: "key", APIURL: pagerDuty.URL},
+		},
+		Routes: []config.Route{
+			{
+				Match:  config.RouteMatch{ServiceIDs: []string{"web"}},
+				Policy: config.RoutePolicy{FailureThreshold: 1, RecoveryAlert: true},
+				Notify: []string{"slack", "pagerduty"},
+			},
+		},
+	}
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	engine := NewEngine(cfg, log, nil)
+	maint := stubMaintenance{}
+	engine.SetMaintenance(maint)
+	svc := config.Service{ID: "web", Name: "Web", Type: "http"}
+
+	// DOWN, recovered inside a window, then UP once it ends
+	engine.HandleResult(svc, checks.Result{Success: false})
+	maint["web"] = true
+	engine.HandleResult(svc, checks.Result{Success: true})
+	maint["web"] = false
+	engine.HandleResult(svc, checks.Result{Success: true})
+	engine.queue.flush()
+
+	// Chat stays quiet about the recovery; the incident is resolved
+	if got := atomic.LoadInt32(&chats); got != 1 {
+		t.Errorf("slack alerts = %d, want 1 (down only)", got)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if got := strings.Join(actions, ","); got != "trigger,resolve" {
+		t.Errorf("pagerduty events = %s, want trigger,resolve", got)
+	}
+	if st, _ := engine.state.Lookup("web"); st.State != StateUp || st.DownNotified {
+		t.Errorf("state = %+v, want UP with the outage closed", st)
+	}
+}
+
+func TestEngine_HandleResult_FailingParentInMaintenanceSuppressesDependents(t *testing.T) {
+	engine, sent := newTestEngine(t, "db", "api")
+	engine.SetMaintenance(stubMaintenance{"db": true})
+	parent := config.Service{ID: "db", Name: "DB", Type: "tcp"}
+	child := config.Service{ID: "api", Name: "API", Type: "http", DependsOn: []string{"db"}}
+
+	engine.HandleResult(parent, checks.Result{Success: false})
+	engine.HandleResult(child, checks.Result{Success: false})
+
+	engine.queue.flush()
+	if got := atomic.LoadInt32(sent); got != 0 {
+		t.Errorf("alerts = %d, want 0", got)
+	}
+	if st, _ := engine.state.Lookup("api"); st.State != StateUnreachable {
+		t.Errorf("child state = %q, want %q", st.State, StateUnreachable)
+	}
+}
+
+func TestEngine_HandleResult_Templates(t *testing.T) {
+	texts := make(chan string, 4)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Text string `json:"text"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		texts <- body.Text
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	engine := NewEngine(config.AlertingConfig{
+		Channels: map[string]config.Channel{
+			"slack": {
+				Type:       "slack",
+				WebhookURL: server.URL,
+				Templates:  config.MessageTemplates{Message: "channel: {{ .Service.ID }}"},
+			},
+		},
+		Routes: []config.Route{
+			{
+				Match:  config.RouteMatch{ServiceIDs: []string{"web"}},
+				Policy: config.RoutePolicy{FailureThreshold: 2, RecoveryAlert: true},
+				Notify: []string{"slack"},
+			},
+			{
+				Match:     config.RouteMatch{ServiceIDs: []string{"api"}},
+				Policy:    config.RoutePolicy{FailureThreshold: 2, RecoveryAlert: true},
+				Notify:    []string{"slack"},
+				Templates: config.MessageTemplates{Message: "route: {{ .State }} {{ .Service.Labels.team }} {{ not .Since.IsZero }}"},
+			},
+		},
+	}, slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError})), nil)
+
+	web := config.Service{ID: "web", Name: "Web", Type: "http"}
+	api := config.Service{ID: "api", Name: "API", Type: "http", Labels: map[string]string{"team": "payments"}}
+
+	tests := []struct {
+		svc    config.Service
+		result checks.Result
+		want   string
+	}{
+		{web, checks.Result{Success: false}, ""},
+		{web, checks.Result{Success: false}, "channel: web"},
+		{api, checks.Result{Success: false}, ""},
+		{api, checks.Result{Success: false}, "route: DOWN payments true"},
+		{api, checks.Result{Success: true}, "route: UP payments true"},
+	}
+
+	for i, tc := range tests {
+		engine.HandleResult(tc.svc, tc.result)
+		engine.queue.flush()
+
+		select {
+		case got := <-texts:
+			if got != tc.want {
+				t.Errorf("step %d: message = %q, want %q", i, got, tc.want)
+			}
+		default:
+			if tc.want != "" {
+				t.Errorf("step %d: no message, want %q", i, tc.want)
+			}
+		}
+	}
+}
+
+func TestEngine_UpdateConfigPreservesState(t *testing.T) {
+	engine, sent := newTestEngine(t, "web")
+	svc := config.Service{ID: "web", Name: "Web", Type: "http"}
+
+	engine.HandleResult(svc, checks.Result{Success: false})
+	engine.queue.flush()
+	if got := atomic.LoadInt32(sent); got != 1 {
+		t.Fatalf("alerts after failure = %d, want 1", got)
+	}
+
+	var resent int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&resent, 1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	engine.UpdateConfig(config.AlertingConfig{
+		Channels: map[string]config.Channel{
+			"ops": {Type: "slack", WebhookURL: server.URL},
+		},
+		Routes: []config.Route{
+			{
+				Match:  config.RouteMatch{ServiceIDs: []string{"web"}},
+				Policy: config.RoutePolicy{FailureThreshold: 1, Cooldown: "1h", RecoveryAlert: true},
+				Notify: []string{"ops"},
+			},
+		},
+	})
+
+	// Still down: no new DOWN alert since the outage was already notified
+	engine.HandleResult(svc, checks.Result{Success: false})
+	engine.queue.flush()
+	if got := atomic.LoadInt32(&resent); got != 0 {
+		t.Errorf("alerts after reload while down = %d, want 0", got)
+	}
+	if st, _ := engine.state.Lookup("web"); st.State != StateDown || !st.DownNotified {
+		t.Errorf("state = %+v, want DOWN and notified", st)
+	}
+
+	// Recovery goes to the new channel only
+	engine.HandleResult(svc, checks.Result{Success: true})
+	engine.queue.flush()
+	if got := atomic.LoadInt32(&resent); got != 1 {
+		t.Errorf("recovery alerts on new channel = %d, want 1", got)
+	}
+	engine.queue.flush()
+	if got := atomic.LoadInt32(sent); got != 1 {
+		t.Errorf("alerts on old channel = %d, want 1", got)
+	}
+}
+
+func TestEngine_OutageConfirmed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	engine, _ := newTestEngine(t)
+	engine.UpdateConfig(config.AlertingConfig{
+		Channels: map[string]config.Channel{
+			"slack": {Type: "slack", WebhookURL: server.URL},
+		},
+		Routes: []config.Route{
+			{
+				Match:  config.RouteMatch{ServiceIDs: []string{"web"}},
+				Policy: config.RoutePolicy{FailureThreshold: 2},
+				Notify: []string{"slack"},
+			},
+		},
+	})
+	svc := config.Service{ID: "web", Name: "Web", Type: "http"}
+
+	engine.HandleResult(svc, checks.Result{Success: false})
+	if engine.OutageConfirmed("web") {
+		t.Error("outage confirmed below failure threshold")
+	}
+
+	engine.HandleResult(svc, checks.Result{Success: false})
+	if !engine.OutageConfirmed("web") {
+		t.Error("outage not confirmed at failure threshold")
+	}
+
+	if engine.OutageConfirmed("unknown") {
+		t.Error("unknown service reported as down")
+	}
+}
+
+func TestEngine_OutageConfirmed_UnreachableAndMaintenance(t *testing.T) {
+	engine, _ := newTestEngine(t, "lb", "app", "db")
+	maint := stubMaintenance{}
+	engine.SetMaintenance(maint)
+
+	parent := config.Service{ID: "lb", Name: "Load Balancer", Type: "tcp"}
+	child := config.Service{ID: "app", Name: "App", Type: "http", DependsOn: []string{"lb"}}
+	engine.HandleResult(parent, checks.Result{Success: false})
+	engine.HandleResult(child, checks.Result{Success: false})
+	if !engine.OutageConfirmed("app") {
+		t.Error("outage of a service unreachable behind a down parent not confirmed")
+	}
+
+	// newTestEngine routes with failure_threshold 1; use a stricter route
+	engine.UpdateConfig(config.AlertingConfig{
+		Channels: map[string]config.Channel{"slack": {Type: "slack", WebhookURL: "http://127.0.0.1:1"}},
+		Routes: []config.Route{{
+			Match:  config.RouteMatch{ServiceIDs: []string{"db"}},
+			Policy: config.RoutePolicy{FailureThreshold: 2},
+			Notify: []string{"slack"},
+		}},
+	})
+	maint["db"] = true
+	db := config.Service{ID: "db", Name: "DB", Type: "tcp"}
+	engine.HandleResult(db, checks.Result{Success: false})
+	if engine.OutageConfirmed("db") {
+		t.Error("outage confirmed in maintenance below the failure threshold")
+	}
+	engine.HandleResult(db, checks.Result{Success: false})
+	if !engine.OutageConfirmed("db") {
+		t.Error("outage not confirmed in maintenance at the failure threshold")
+	}
+}
+
+func TestEngine_Standby(t *testing.T) {
+	leader, leaderSent := newTestEngine(t, "web")
+	standby, standbySent := newTestEngine(t, "web")
+	standby.SetStandby(true)
+
+	var alerts int
+	leader.OnAlert(func() { alerts++ })
+
+	svc := config.Service{ID: "web", Name: "Web", Type: "http"}
+	leader.HandleResult(svc, checks.Result{Success: false})
+	standby.HandleResult(svc, checks.Result{Success: false})
+	leader.queue.flush()
+
+	if alerts != 1 {
+		t.Errorf("OnAlert calls = %d, want 1", alerts)
+	}
+	standby.queue.flush()
+	if got := atomic.LoadInt32(standbySent); got != 0 {
+		t.Errorf("alerts from standby = %d, want 0", got)
+	}
+	if _, ok := standby.state.Lookup("web"); ok {
+		t.Error("standby should not track state from its own results")
+	}
+
+	// After failover the replicated state knows DOWN was already sent, so
+	// the new leader sends the recovery
+	standby.Restore(leader.DeliveredSnapshot())
+	standby.SetStandby(false)
+	standby.HandleResult(svc, checks.Result{Success: true})
+
+	standby.queue.flush()
+	if got := atomic.LoadInt32(standbySent); got != 1 {
+		t.Errorf("alerts after failover = %d, want 1 (recovery)", got)
+	}
+	leader.queue.flush()
+	if got := atomic.LoadInt32(leaderSent); got != 1 {
+		t.Errorf("alerts from leader = %d, want 1", got)
+	}
+}
+
+func TestEngine_DeliveredSnapshot(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	t.Cleanup(server.Close)
+
+	cfg := config.AlertingConfig{
+		Channels: map[string]config.Channel{
+			"slack": {Type: "slack", WebhookURL: server.URL},
+		},
+		Routes: []config.Route{
+			{
+				Match:  config.RouteMatch{ServiceIDs: []string{"web"}},
+				Policy: config.RoutePolicy{FailureThreshold: 1, RecoveryAlert: true},
+				Notify: []string{"slack"},
+			},
+		},
+	}
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	engine := NewEngine(cfg, log, nil)
+	svc := config.Service{ID: "web", Name: "Web", Type: "http"}
+
+	// DOWN queued but not delivered: a standby taking over must send it
+	engine.HandleResult(svc, checks.Result{Success: false})
+	if st := engine.Snapshot()["web"]; !st.DownNotified {
+		t.Fatal("live state should count the queued DOWN alert")
+	}
+	if st := engine.DeliveredSnapshot()["web"]; st.DownNotified || !st.LastDownAlertAt.IsZero() || st.State != StateDown {
+		t.Errorf("delivered state = %+v, want DOWN not yet notified", st)
+	}
+
+	release <- struct{}{}
+	engine.queue.flush()
+	if st := engine.DeliveredSnapshot()["web"]; !st.DownNotified {
+		t.Errorf("delivered state = %+v, want DOWN notified once sent", st)
+	}
+
+	// Recovery queued but not delivered: the outage stays open for a standby
+	engine.HandleResult(svc, checks.Result{Success: true})
+	if st := engine.DeliveredSnapshot()["web"]; !st.DownNotified || st.State != StateDown {
+		t.Errorf("delivered state = %+v, want the outage open until the recovery is sent", st)
+	}
+
+	release <- struct{}{}
+	engine.queue.flush()
+	if st := engine.DeliveredSnapshot()["web"]; st.DownNotified || st.State != StateUp {
+		t.Errorf("delivered state = %+v, want UP once the recovery is sent", st)
+	}
+}
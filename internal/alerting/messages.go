@@ -8,14 +8,24 @@ import (
 
 	"uptiq/internal/checks"
 	"uptiq/internal/config"
+	"uptiq/internal/templates"
 )
 
 // AlertPayload contains formatted alert content for all channel types.
 type AlertPayload struct {
-	Kind           string // "down" | "recovery"
-	WebhookMessage string // For Discord/Slack
-	EmailSubject   string
-	EmailBody      string
+	Kind           string `json:"kind"`            // "down" | "recovery"
+	WebhookMessage string `json:"webhook_message"` // For Discord/Slack
+	EmailSubject   string `json:"email_subject"`   // Also the one-line summary for incident tools
+	EmailBody      string `json:"email_body"`
+	EmailHTML      string `json:"email_html,omitempty"` // HTML alternative of EmailBody, from a template
+
+	// Context is what templated channels render; its service ID also ties
+	// an outage's alerts together, e.g. as PagerDuty dedup_key.
+	Context templates.Alert `json:"context"`
+
+	// DeliveryKey identifies the alert to one channel, the same on every
+	// attempt and after a restart. Set by the dispatcher when sending.
+	DeliveryKey string `json:"-"`
 }
 
 // MessageBuilder creates alert messages for different scenarios.
@@ -33,6 +43,7 @@ func (b *MessageBuilder) DownAlert(svc config.Service, res checks.Result, failur
 		WebhookMessage: b.formatDownWebhook(svc, res, failures, threshold, false),
 		EmailSubject:   b.formatDownSubject(svc, false),
 		EmailBody:      b.formatDownBody(svc, res, failures, threshold),
+		Context:        alertContext(svc, res, "down", false, failures, threshold),
 	}
 }
 
@@ -43,6 +54,7 @@ func (b *MessageBuilder) StillDownAlert(svc config.Service, res checks.Result, f
 		WebhookMessage: b.formatDownWebhook(svc, res, failures, threshold, true),
 		EmailSubject:   b.formatDownSubject(svc, true),
 		EmailBody:      b.formatDownBody(svc, res, failures, threshold),
+		Context:        alertContext(svc, res, "down", true, failures, threshold),
 	}
 }
 
@@ -53,6 +65,39 @@ func (b *MessageBuilder) RecoveryAlert(svc config.Service, res checks.Result) Al
 		WebhookMessage: b.formatRecoveryWebhook(svc, res),
 		EmailSubject:   fmt.Sprintf("[UP] %s (%s)", svc.Name, svc.ID),
 		EmailBody:      b.formatRecoveryBody(svc, res),
+		Context:        alertContext(svc, res, "recovery", false, 0, 0),
+	}
+}
+
+// alertContext describes an alert for templates.
+func alertContext(svc config.Service, res checks.Result, kind string, reminder bool, failures, threshold int) templates.Alert {
+	state := string(StateDown)
+	if kind == "recovery" {
+		state = string(StateUp)
+	}
+
+	return templates.Alert{
+		Kind:     kind,
+		Reminder: reminder,
+		State:    state,
+		Service: templates.Service{
+			ID:     svc.ID,
+			Name:   svc.Name,
+			Type:   strings.ToLower(svc.Type),
+			Target: targetForService(svc),
+			Tags:   svc.Tags,
+			Labels: svc.Labels,
+			Links:  svc.Links,
+		},
+		Result: templates.Result{
+			Success:    res.Success,
+			StatusCode: res.StatusCode,
+			LatencyMS:  res.Latency.Milliseconds(),
+			Error:      strings.TrimSpace(res.Error),
+		},
+		Failures:  failures,
+		Threshold: threshold,
+		Time:      time.Now(),
 	}
 }
 
@@ -164,9 +209,12 @@ func targetForService(svc config.Service) string {
 	if svc.IsHTTP() {
 		return svc.URL
 	}
-	if svc.IsTCP() {
+	if svc.IsTCP() || svc.IsNTP() {
 		return net.JoinHostPort(svc.Host, fmt.Sprintf("%d", svc.Port))
 	}
+	if svc.IsDomain() {
+		return svc.Domain
+	}
 	return ""
 }
 
@@ -181,3 +229,64 @@ func truncate(s string, maxLen int) string {
 	}
 	return s[:maxLen-3] + "..."
 }
+
+// applyTemplates replaces the built-in texts of payload with those rendered
+// from the set templates. The templates see the built-in texts, so they can
+// embed them. A template that fails keeps its built-in text, and the first
+// error is returned.
+func applyTemplates(tmpl config.MessageTemplates, payload AlertPayload) (AlertPayload, error) {
+	data := templateData(payload)
+	fields := []struct {
+		name, text string
+		html       bool
+		dst        *string
+	}{
+		{"message", tmpl.Message, false, &payload.WebhookMessage},
+		{"subject", tmpl.Subject, false, &payload.EmailSubject},
+		{"body", tmpl.Body, false, &payload.EmailBody},
+		{"html_body", tmpl.HTMLBody, true, &payload.EmailHTML},
+	}
+
+	var firstErr error
+	for _, f := range fields {
+		if f.text == "" {
+			continue
+		}
+		out, err := renderField(f.name, f.text, f.html, data)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		*f.dst = out
+	}
+
+	return payload, firstErr
+}
+
+// templateData is what templates are executed with for payload.
+func templateData(payload AlertPayload) templates.Data {
+	return templates.Data{
+		Alert:   payload.Context,
+		Message: payload.WebhookMessage,
+		Subject: alertTitle(payload),
+		Body:    payload.EmailBody,
+	}
+}
+
+func renderField(name, text string, html bool, data templates.Data) (string, error) {
+	var (
+		t   templates.Template
+		err error
+	)
+	if html {
+		t, err = templates.ParseHTML(name, text)
+	} else {
+		t, err = templates.Parse(name, text)
+	}
+	if err != nil {
+		return "", fmt.Errorf("parse %s template: %w", name, err)
+	}
+	return templates.Render(t, data)
+}
@@ -417,3 +417,89 @@ func TestMessageBuilder_TimestampPresence(t *testing.T) {
 		t.Error("email body should contain time label")
 	}
 }
+
+func TestApplyTemplates(t *testing.T) {
+	svc := config.Service{
+		ID:     "api-1",
+		Name:   "API Server",
+		Type:   "http",
+		URL:    "https://api.example.com/health",
+		Labels: map[string]string{"team": "payments"},
+		Links:  map[string]string{"runbook": "https://wiki.example.com/api"},
+	}
+	builder := NewMessageBuilder()
+	payload := builder.DownAlert(svc, checks.Result{Success: false, Error: "timeout"}, 3, 3)
+
+	tests := []struct {
+		name      string
+		tmpl      config.MessageTemplates
+		check     func(t *testing.T, got AlertPayload)
+		wantError string // Empty: no error
+	}{
+		{
+			name: "no templates",
+			check: func(t *testing.T, got AlertPayload) {
+				if got.WebhookMessage != payload.WebhookMessage || got.EmailSubject != payload.EmailSubject ||
+					got.EmailBody != payload.EmailBody || got.EmailHTML != "" {
+					t.Errorf("payload changed without templates: %+v", got)
+				}
+			},
+		},
+		{
+			name: "labels and links",
+			tmpl: config.MessageTemplates{
+				Message: "{{ .Message }}\nRunbook: {{ .Service.Links.runbook }}",
+				Subject: "[{{ upper .Service.Labels.team }}] {{ .Subject }}",
+			},
+			check: func(t *testing.T, got AlertPayload) {
+				if want := payload.WebhookMessage + "\nRunbook: https://wiki.example.com/api"; got.WebhookMessage != want {
+					t.Errorf("WebhookMessage = %q, want %q", got.WebhookMessage, want)
+				}
+				if want := "[PAYMENTS] " + payload.EmailSubject; got.EmailSubject != want {
+					t.Errorf("EmailSubject = %q, want %q", got.EmailSubject, want)
+				}
+				if got.EmailBody != payload.EmailBody {
+					t.Error("EmailBody changed without a body template")
+				}
+			},
+		},
+		{
+			name: "html body",
+			tmpl: config.MessageTemplates{HTMLBody: "<p>{{ .Result.Error }}</p>"},
+			check: func(t *testing.T, got AlertPayload) {
+				if got.EmailHTML != "<p>timeout</p>" {
+					t.Errorf("EmailHTML = %q, want %q", got.EmailHTML, "<p>timeout</p>")
+				}
+			},
+		},
+		{
+			name: "failing template keeps built-in",
+			tmpl: config.MessageTemplates{
+				Message: "{{ .Service.Owner }}",
+				Body:    "custom body",
+			},
+			check: func(t *testing.T, got AlertPayload) {
+				if got.WebhookMessage != payload.WebhookMessage {
+					t.Errorf("WebhookMessage = %q, want built-in", got.WebhookMessage)
+				}
+				if got.EmailBody != "custom body" {
+					t.Errorf("EmailBody = %q, want %q", got.EmailBody, "custom body")
+				}
+			},
+			wantError: "Owner",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := applyTemplates(tc.tmpl, payload)
+			if tc.wantError == "" && err != nil {
+				t.Fatalf("applyTemplates error: %v", err)
+			}
+			if tc.wantError != "" && (err == nil || !strings.Contains(err.Error(), tc.wantError)) {
+				t.Fatalf("applyTemplates error = %v, want containing %q", err, tc.wantError)
+			}
+			tc.check(t, got)
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package alerting
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"uptiq/internal/config"
+)
+
+// Opsgenie Alert API constants.
+const (
+	opsgenieSource         = "uptiq"
+	opsgenieMaxMessage     = 130
+	opsgenieMaxDescription = 15000
+)
+
+// opsgenieCreate is an Opsgenie create alert request.
+type opsgenieCreate struct {
+	Message     string              `json:"message"`
+	Alias       string              `json:"alias"`
+	Description string              `json:"description,omitempty"`
+	Responders  []opsgenieResponder `json:"responders,omitempty"`
+	Tags        []string            `json:"tags,omitempty"`
+	Entity      string              `json:"entity,omitempty"`
+	Source      string              `json:"source"`
+	Priority    string              `json:"priority,omitempty"`
+	Details     map[string]string   `json:"details,omitempty"`
+}
+
+// opsgenieResponder names a team, escalation or schedule by name, and a
+// user by username.
+type opsgenieResponder struct {
+	Type     string `json:"type"`
+	Name     string `json:"name,omitempty"`
+	Username string `json:"username,omitempty"`
+}
+
+// opsgenieClose is an Opsgenie close alert request.
+type opsgenieClose struct {
+	Source string `json:"source"`
+	Note   string `json:"note,omitempty"`
+}
+
+// sendOpsgenie creates an alert for a DOWN alert and closes it on recovery.
+// Both use the service's alias, so reminders while down are deduplicated
+// into the open alert.
+func (s *ChannelSender) sendOpsgenie(ctx context.Context, ch config.Channel, payload AlertPayload) error {
+	if strings.TrimSpace(ch.APIKey) == "" {
+		return errors.New("empty opsgenie api_key")
+	}
+	if payload.Context.Service.ID == "" {
+		return errors.New("alert has no service_id for the opsgenie alias")
+	}
+
+	apiURL := strings.TrimRight(ch.APIURL, "/")
+	if apiURL == "" {
+		apiURL = config.DefaultOpsgenieURL
+	}
+	headers := map[string]string{"Authorization": "GenieKey " + ch.APIKey}
+	alias := incidentKey(payload.Context.Service.ID)
+
+	if payload.Kind == "recovery" {
+		closeURL := apiURL + "/v2/alerts/" + url.PathEscape(alias) + "/close?identifierType=alias"
+		return s.sendJSON(ctx, http.MethodPost, closeURL, opsgenieClose{
+			Source: opsgenieSource,
+			Note:   payload.EmailSubject,
+		}, headers)
+	}
+
+	return s.sendJSON(ctx, http.MethodPost, apiURL+"/v2/alerts", opsgenieCreate{
+		Message:     truncate(alertTitle(payload), opsgenieMaxMessage),
+		Alias:       alias,
+		Description: truncate(payload.EmailBody, opsgenieMaxDescription),
+		Responders:  opsgenieResponders(ch.Responders),
+		Tags:        ch.Tags,
+		Entity:      payload.Context.Service.ID,
+		Source:      opsgenieSource,
+		Priority:    ch.Priority,
+		Details:     map[string]string{"target": payload.Context.Service.Target},
+	}, headers)
+}
+
+func opsgenieResponders(responders []config.OpsgenieResponder) []opsgenieResponder {
+	out := make([]opsgenieResponder, 0, len(responders))
+	for _, r := range responders {
+		if config.OpsgenieResponderType(r.Type) == config.OpsgenieUser {
+			out = append(out, opsgenieResponder{Type: r.Type, Username: r.Name})
+			continue
+		}
+		out = append(out, opsgenieResponder{Type: r.Type, Name: r.Name})
+	}
+	return out
+}
@@ -0,0 +1,128 @@
+package alerting
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"uptiq/internal/checks"
+	"uptiq/internal/config"
+	"uptiq/internal/templates"
+)
+
+type opsgenieRequest struct {
+	Path  string
+	Query string
+	Auth  string
+	Body  []byte
+}
+
+// opsgenieServer stands in for the Alert API and records the requests.
+func opsgenieServer(t *testing.T) (*httptest.Server, func() []opsgenieRequest) {
+	t.Helper()
+
+	var (
+		mu       sync.Mutex
+		requests []opsgenieRequest
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		requests = append(requests, opsgenieRequest{
+			Path:  r.URL.EscapedPath(),
+			Query: r.URL.RawQuery,
+			Auth:  r.Header.Get("Authorization"),
+			Body:  body,
+		})
+		mu.Unlock()
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func() []opsgenieRequest {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]opsgenieRequest(nil), requests...)
+	}
+}
+
+func TestSendOpsgenie_AlertLifecycle(t *testing.T) {
+	server, requests := opsgenieServer(t)
+	ch := config.Channel{
+		Type:     "opsgenie",
+		APIKey:   "secret",
+		APIURL:   server.URL + "/",
+		Priority: "P2",
+		Tags:     []string{"uptiq", "prod"},
+		Responders: []config.OpsgenieResponder{
+			{Type: "team", Name: "SRE"},
+			{Type: "user", Name: "oncall@example.com"},
+		},
+	}
+	svc := config.Service{ID: "web", Name: "Web", Type: "tcp", Host: "db.internal", Port: 5432}
+
+	b := NewMessageBuilder()
+	sender := NewChannelSender()
+	for _, payload := range []AlertPayload{
+		b.DownAlert(svc, checks.Result{Error: "connection refused"}, 1, 1),
+		b.RecoveryAlert(svc, checks.Result{Success: true}),
+	} {
+		if res := sender.Send(context.Background(), ch, payload); !res.Success {
+			t.Fatalf("Send(%s): %v", payload.Kind, res.Error)
+		}
+	}
+
+	got := requests()
+	if len(got) != 2 {
+		t.Fatalf("requests = %d, want 2", len(got))
+	}
+	for i, req := range got {
+		if req.Auth != "GenieKey secret" {
+			t.Errorf("request %d Authorization = %q, want GenieKey secret", i, req.Auth)
+		}
+	}
+
+	create := got[0]
+	if create.Path != "/v2/alerts" {
+		t.Errorf("create path = %q, want /v2/alerts", create.Path)
+	}
+	var alert opsgenieCreate
+	if err := json.Unmarshal(create.Body, &alert); err != nil {
+		t.Fatalf("decode create: %v", err)
+	}
+	if alert.Alias != "uptiq/web" || alert.Priority != "P2" || alert.Message != "[DOWN] Web (web)" {
+		t.Errorf("create = alias %q, priority %q, message %q", alert.Alias, alert.Priority, alert.Message)
+	}
+	if len(alert.Tags) != 2 {
+		t.Errorf("tags = %v, want [uptiq prod]", alert.Tags)
+	}
+	wantResponders := []opsgenieResponder{
+		{Type: "team", Name: "SRE"},
+		{Type: "user", Username: "oncall@example.com"},
+	}
+	if len(alert.Responders) != 2 || alert.Responders[0] != wantResponders[0] || alert.Responders[1] != wantResponders[1] {
+		t.Errorf("responders = %+v, want %+v", alert.Responders, wantResponders)
+	}
+
+	closeReq := got[1]
+	if closeReq.Path != "/v2/alerts/uptiq%2Fweb/close" || closeReq.Query != "identifierType=alias" {
+		t.Errorf("close = %s?%s, want /v2/alerts/uptiq%%2Fweb/close?identifierType=alias", closeReq.Path, closeReq.Query)
+	}
+}
+
+func TestSendOpsgenie_MissingAPIKey(t *testing.T) {
+	server, requests := opsgenieServer(t)
+	ch := config.Channel{Type: "opsgenie", APIURL: server.URL}
+
+	res := NewChannelSender().Send(context.Background(), ch, AlertPayload{Kind: "down", Context: templates.Alert{Service: templates.Service{ID: "web"}}})
+	if res.Success || res.Error == nil {
+		t.Errorf("Send = %+v, want an error", res)
+	}
+	if len(requests()) != 0 {
+		t.Error("nothing should be sent without an api_key")
+	}
+}
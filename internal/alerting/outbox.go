@@ -0,0 +1,353 @@
+package alerting
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Outbox configuration constants.
+const (
+	outboxCompactAfter = 1000 // Done records appended before the file is rewritten
+	outboxMaxLine      = 1 << 20
+	outboxLockSuffix   = ".lock"
+
+	outboxAdd  = "add"
+	outboxDone = "done"
+)
+
+// ErrOutboxInUse is returned when modifying an outbox a running uptiq holds.
+var ErrOutboxInUse = errors.New("outbox is in use by a running uptiq")
+
+// OutboxEntry is an alert waiting to be delivered to one channel. Only the
+// channel name is stored, never its settings, so webhook URLs and SMTP
+// passwords stay out of the file; it is resolved against the current
+// config when the entry is sent.
+type OutboxEntry struct {
+	Key         string       `json:"key"` // Unique per alert and channel
+	Channel     string       `json:"channel"`
+	ServiceID   string       `json:"service_id"`
+	ServiceName string       `json:"service_name"`
+	Payload     AlertPayload `json:"payload"`
+	QueuedAt    time.Time    `json:"queued_at"`
+}
+
+// outboxRecord is one line of the outbox file.
+type outboxRecord struct {
+	Op     string       `json:"op"`
+	Entry  *OutboxEntry `json:"entry,omitempty"`
+	Key    string       `json:"key,omitempty"`
+	Result string       `json:"result,omitempty"`
+}
+
+// Outbox persists alerts until they are delivered, so alerts queued when
+// uptiq stops or crashes are sent after it starts again. It is an
+// append-only file of JSON lines: an "add" record when an alert is queued
+// and a "done" record once it is delivered or given up on. The file is
+// rewritten with only the pending entries when opened and after every
+// outboxCompactAfter done records.
+type Outbox struct {
+	path string
+	lock *os.File // Holds the lock until Close
+
+	mu      sync.Mutex
+	file    *os.File
+	pending map[string]OutboxEntry
+	done    int
+}
+
+// OpenOutbox opens the outbox at path, creating it if needed, and locks it
+// until Close. It fails with ErrOutboxInUse while another running uptiq
+// holds it. The lock is released by the kernel when its process exits, so
+// a crashed uptiq never leaves the outbox locked.
+func OpenOutbox(path string) (*Outbox, error) {
+	lock, err := lockOutbox(path)
+	if err != nil {
+		return nil, err
+	}
+
+	pending, err := readOutbox(path)
+	if err != nil {
+		_ = lock.Close()
+		return nil, err
+	}
+
+	o := &Outbox{path: path, lock: lock, pending: pending}
+	if err := o.compact(); err != nil {
+		_ = lock.Close()
+		return nil, err
+	}
+	return o, nil
+}
+
+// Add records entry as pending. An entry with the same key is only kept
+// once.
+func (o *Outbox) Add(entry OutboxEntry) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if _, ok := o.pending[entry.Key]; ok {
+		return nil
+	}
+	if err := o.append(outboxRecord{Op: outboxAdd, Entry: &entry}, true); err != nil {
+		return err
+	}
+	o.pending[entry.Key] = entry
+	return nil
+}
+
+// Done records that the entry with key needs no further attempts, with
+// the final result.
+func (o *Outbox) Done(key, result string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if _, ok := o.pending[key]; !ok {
+		return nil
+	}
+	delete(o.pending, key)
+
+	// Not synced: losing it at worst sends the alert once more
+	if err := o.append(outboxRecord{Op: outboxDone, Key: key, Result: result}, false); err != nil {
+		return err
+	}
+
+	o.done++
+	if o.done >= outboxCompactAfter {
+		return o.compact()
+	}
+	return nil
+}
+
+// Pending returns the entries not yet done, oldest first.
+func (o *Outbox) Pending() []OutboxEntry {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return sortedEntries(o.pending)
+}
+
+// Len returns the number of pending entries.
+func (o *Outbox) Len() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return len(o.pending)
+}
+
+// Close closes the file and releases the outbox.
+func (o *Outbox) Close() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	// The lock file stays: removing it could let two processes lock
+	// different files
+	err := o.file.Close()
+	if lerr := o.lock.Close(); lerr != nil && err == nil {
+		err = lerr
+	}
+	return err
+}
+
+func (o *Outbox) append(rec outboxRecord, sync bool) error {
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("encode outbox record: %w", err)
+	}
+	if _, err := o.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("write outbox: %w", err)
+	}
+	if sync {
+		if err := o.file.Sync(); err != nil {
+			return fmt.Errorf("sync outbox: %w", err)
+		}
+	}
+	return nil
+}
+
+// compact rewrites the file with only the pending entries. The caller
+// holds o.mu, except in OpenOutbox.
+func (o *Outbox) compact() error {
+	if err := writeOutbox(o.path, o.pending); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(o.path, os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("open outbox: %w", err)
+	}
+	if o.file != nil {
+		_ = o.file.Close()
+	}
+	o.file = file
+	o.done = 0
+	return nil
+}
+
+// ReadOutbox returns the entries pending in the outbox at path, oldest
+// first. It is safe to call while uptiq is running.
+func ReadOutbox(path string) ([]OutboxEntry, error) {
+	pending, err := readOutbox(path)
+	if err != nil {
+		return nil, err
+	}
+	return sortedEntries(pending), nil
+}
+
+// PurgeOutbox removes the entries with the given keys from the outbox at
+// path, or every entry if keys is empty, and returns how many it removed.
+// It refuses while a running uptiq holds the outbox unless force is set.
+func PurgeOutbox(path string, keys []string, force bool) (int, error) {
+	if pid, held := outboxHolder(path); held && !force {
+		return 0, fmt.Errorf("%w (pid %s); stop it first", ErrOutboxInUse, pid)
+	}
+
+	pending, err := readOutbox(path)
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for key := range pending {
+		if len(keys) == 0 || slices.Contains(keys, key) {
+			delete(pending, key)
+			removed++
+		}
+	}
+	if removed == 0 {
+		return 0, nil
+	}
+	return removed, writeOutbox(path, pending)
+}
+
+// lockOutbox locks the lock file next to the outbox at path and writes
+// this process's pid into it, for the error other processes report.
+func lockOutbox(path string) (*os.File, error) {
+	lock, err := os.OpenFile(path+outboxLockSuffix, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("lock outbox: %w", err)
+	}
+
+	if err := lockFile(lock); err != nil {
+		_ = lock.Close()
+		if errors.Is(err, errLocked) {
+			pid, _ := outboxHolder(path)
+			return nil, fmt.Errorf("%w (pid %s)", ErrOutboxInUse, pid)
+		}
+		return nil, fmt.Errorf("lock outbox: %w", err)
+	}
+
+	if err := lock.Truncate(0); err == nil {
+		_, _ = lock.WriteAt([]byte(strconv.Itoa(os.Getpid())), 0)
+	}
+	return lock, nil
+}
+
+// outboxHolder reports whether a process holds the lock of the outbox at
+// path, and the pid it wrote into the lock file.
+func outboxHolder(path string) (string, bool) {
+	lock, err := os.OpenFile(path+outboxLockSuffix, os.O_RDWR, 0)
+	if err != nil {
+		return "", false
+	}
+	defer lock.Close()
+
+	if err := lockFile(lock); !errors.Is(err, errLocked) {
+		return "", false // Closing lock releases it
+	}
+	data, _ := os.ReadFile(path + outboxLockSuffix)
+	pid := strings.TrimSpace(string(data))
+	if pid == "" {
+		pid = "unknown"
+	}
+	return pid, true
+}
+
+// readOutbox replays the file at path into the pending entries. A missing
+// file is an empty outbox. Lines that do not decode, such as one cut short
+// by a crash, are skipped.
+func readOutbox(path string) (map[string]OutboxEntry, error) {
+	pending := make(map[string]OutboxEntry)
+
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return pending, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open outbox: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), outboxMaxLine)
+	for scanner.Scan() {
+		var rec outboxRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			continue
+		}
+		switch {
+		case rec.Op == outboxAdd && rec.Entry != nil:
+			pending[rec.Entry.Key] = *rec.Entry
+		case rec.Op == outboxDone:
+			delete(pending, rec.Key)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read outbox: %w", err)
+	}
+	return pending, nil
+}
+
+// writeOutbox atomically replaces the file at path with add records for
+// the pending entries.
+func writeOutbox(path string, pending map[string]OutboxEntry) error {
+	tmp := path + ".tmp"
+	file, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("create outbox: %w", err)
+	}
+
+	w := bufio.NewWriter(file)
+	enc := json.NewEncoder(w)
+	for _, entry := range sortedEntries(pending) {
+		if err := enc.Encode(outboxRecord{Op: outboxAdd, Entry: &entry}); err != nil {
+			file.Close()
+			return fmt.Errorf("write outbox: %w", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		file.Close()
+		return fmt.Errorf("write outbox: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return fmt.Errorf("sync outbox: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("close outbox: %w", err)
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("replace outbox: %w", err)
+	}
+	return nil
+}
+
+func sortedEntries(pending map[string]OutboxEntry) []OutboxEntry {
+	entries := make([]OutboxEntry, 0, len(pending))
+	for _, entry := range pending {
+		entries = append(entries, entry)
+	}
+	slices.SortFunc(entries, func(a, b OutboxEntry) int {
+		if c := a.QueuedAt.Compare(b.QueuedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Key, b.Key)
+	})
+	return entries
+}
@@ -0,0 +1,17 @@
+//go:build !unix
+
+package alerting
+
+import (
+	"errors"
+	"os"
+)
+
+// errLocked is returned by lockFile for a file another process has locked.
+var errLocked = errors.New("file is locked")
+
+// lockFile is a no-op where advisory locks are not supported: the outbox
+// is not protected against a second uptiq there.
+func lockFile(file *os.File) error {
+	return nil
+}
@@ -0,0 +1,22 @@
+//go:build unix
+
+package alerting
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// errLocked is returned by lockFile for a file another process has locked.
+var errLocked = errors.New("file is locked")
+
+// lockFile takes an exclusive advisory lock on file without waiting. It is
+// held until file is closed or its process exits.
+func lockFile(file *os.File) error {
+	err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	if errors.Is(err, syscall.EWOULDBLOCK) {
+		return errLocked
+	}
+	return err
+}
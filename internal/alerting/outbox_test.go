@@ -0,0 +1,354 @@
+package alerting
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"uptiq/internal/config"
+	"uptiq/internal/metrics"
+)
+
+func openTestOutbox(t *testing.T, path string) *Outbox {
+	t.Helper()
+
+	o, err := OpenOutbox(path)
+	if err != nil {
+		t.Fatalf("OpenOutbox: %v", err)
+	}
+	return o
+}
+
+func outboxEntry(key, channel string, queuedAt time.Time) OutboxEntry {
+	return OutboxEntry{
+		Key:       key,
+		Channel:   channel,
+		ServiceID: "web",
+		Payload:   AlertPayload{Kind: "down", WebhookMessage: key},
+		QueuedAt:  queuedAt,
+	}
+}
+
+func pendingKeys(entries []OutboxEntry) string {
+	keys := make([]string, len(entries))
+	for i, entry := range entries {
+		keys[i] = entry.Key
+	}
+	return strings.Join(keys, ",")
+}
+
+func TestOutbox_SurvivesReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.jsonl")
+	now := time.Now()
+
+	o := openTestOutbox(t, path)
+	for _, entry := range []OutboxEntry{
+		outboxEntry("b", "slack", now.Add(time.Second)),
+		outboxEntry("a", "slack", now),
+		outboxEntry("a", "slack", now), // Same key is kept once
+		outboxEntry("c", "email", now.Add(2*time.Second)),
+	} {
+		if err := o.Add(entry); err != nil {
+			t.Fatalf("Add: %v", err)
+		}
+	}
+	if err := o.Done("b", "delivered"); err != nil {
+		t.Fatalf("Done: %v", err)
+	}
+	if err := o.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	o = openTestOutbox(t, path)
+	defer o.Close()
+
+	if got := pendingKeys(o.Pending()); got != "a,c" {
+		t.Errorf("pending after reopen = %s, want a,c (oldest first)", got)
+	}
+
+	// Reopening compacts the file to the pending entries
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(string(data), "\n"); lines != 2 {
+		t.Errorf("lines after compaction = %d, want 2", lines)
+	}
+}
+
+func TestOutbox_SkipsTornLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.jsonl")
+
+	o := openTestOutbox(t, path)
+	if err := o.Add(outboxEntry("a", "slack", time.Now())); err != nil {
+		t.Fatal(err)
+	}
+	o.Close()
+
+	// A crash cut the last record short
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"op":"add","entry":{"key":"b"`)
+	f.Close()
+
+	entries, err := ReadOutbox(path)
+	if err != nil {
+		t.Fatalf("ReadOutbox: %v", err)
+	}
+	if got := pendingKeys(entries); got != "a" {
+		t.Errorf("pending = %s, want a", got)
+	}
+}
+
+func TestOpenOutbox_Lock(t *testing.T) {
+	// A lock left by a crash is stale whatever pid it names, even one in
+	// use again, e.g. uptiq as pid 1 in a restarted container
+	tests := []struct {
+		name string
+		lock string
+	}{
+		{name: "pid now reused", lock: strconv.Itoa(os.Getpid())},
+		{name: "pid 1", lock: "1"},
+		{name: "unreadable", lock: "garbage"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "outbox.jsonl")
+			if err := os.WriteFile(path+outboxLockSuffix, []byte(tc.lock), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			o, err := OpenOutbox(path)
+			if err != nil {
+				t.Fatalf("OpenOutbox() error = %v, want the stale lock taken over", err)
+			}
+			defer o.Close()
+
+			if pid, held := outboxHolder(path); !held || pid != strconv.Itoa(os.Getpid()) {
+				t.Errorf("lock holder = %q (held %v), want this process", pid, held)
+			}
+		})
+	}
+}
+
+func TestOpenOutbox_HeldLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.jsonl")
+
+	o := openTestOutbox(t, path)
+	if _, err := OpenOutbox(path); !errors.Is(err, ErrOutboxInUse) {
+		t.Fatalf("second OpenOutbox() error = %v, want ErrOutboxInUse", err)
+	}
+
+	if err := o.Close(); err != nil {
+		t.Fatal(err)
+	}
+	reopened, err := OpenOutbox(path)
+	if err != nil {
+		t.Fatalf("OpenOutbox() after Close error = %v", err)
+	}
+	reopened.Close()
+}
+
+func TestPurgeOutbox(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.jsonl")
+	now := time.Now()
+
+	o := openTestOutbox(t, path)
+	for i, key := range []string{"a", "b", "c"} {
+		if err := o.Add(outboxEntry(key, "slack", now.Add(time.Duration(i)*time.Second))); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := PurgeOutbox(path, nil, false); !errors.Is(err, ErrOutboxInUse) {
+		t.Fatalf("purge while open = %v, want ErrOutboxInUse", err)
+	}
+	o.Close()
+
+	removed, err := PurgeOutbox(path, []string{"b", "missing"}, false)
+	if err != nil || removed != 1 {
+		t.Fatalf("PurgeOutbox(b) = %d, %v; want 1, nil", removed, err)
+	}
+	entries, _ := ReadOutbox(path)
+	if got := pendingKeys(entries); got != "a,c" {
+		t.Errorf("pending after purging b = %s, want a,c", got)
+	}
+
+	removed, err = PurgeOutbox(path, nil, false)
+	if err != nil || removed != 2 {
+		t.Fatalf("PurgeOutbox(all) = %d, %v; want 2, nil", removed, err)
+	}
+	if entries, _ := ReadOutbox(path); len(entries) != 0 {
+		t.Errorf("pending after purging all = %s, want none", pendingKeys(entries))
+	}
+}
+
+func TestDispatcher_OutboxDeliversAfterRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.jsonl")
+	down, _ := statusServer(t, 503, 503, 503, 503, 503)
+	channels := map[string]config.Channel{"slack": {Type: "slack", WebhookURL: down.URL}}
+	resolve := func(name string) (config.Channel, bool) {
+		ch, ok := channels[name]
+		return ch, ok
+	}
+
+	// First run: the channel is down, so the alert stays in the outbox
+	q, _ := newTestDispatcher(t)
+	outbox := openTestOutbox(t, path)
+	q.useOutbox(outbox, resolve)
+	d := slackDelivery("slack", down.URL, "down")
+	d.queuedAt = time.Now()
+	q.submit(d)
+	q.flush()
+	q.close(context.Background())
+	outbox.Close()
+
+	if entries, _ := ReadOutbox(path); len(entries) != 1 {
+		t.Fatalf("pending after failed run = %d, want 1", len(entries))
+	}
+
+	// Second run: the channel is back and gets the alert once
+	up, calls := statusServer(t)
+	channels["slack"] = config.Channel{Type: "slack", WebhookURL: up.URL}
+
+	q, _ = newTestDispatcher(t)
+	outbox = openTestOutbox(t, path)
+	defer outbox.Close()
+	q.useOutbox(outbox, resolve)
+	q.flush()
+
+	// Already delivered, so retrying the outbox sends nothing more
+	q.redeliver(time.Now())
+	q.flush()
+
+	if got := atomic.LoadInt32(calls); got != 1 {
+		t.Errorf("sends after restart = %d, want 1", got)
+	}
+	if outbox.Len() != 0 {
+		t.Errorf("pending after delivery = %d, want 0", outbox.Len())
+	}
+}
+
+func TestDispatcher_OutboxExpiresOldEntries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.jsonl")
+	server, calls := statusServer(t)
+
+	outbox := openTestOutbox(t, path)
+	defer outbox.Close()
+	if err := outbox.Add(outboxEntry("old", "slack", time.Now().Add(-outboxMaxAge-time.Minute))); err != nil {
+		t.Fatal(err)
+	}
+	if err := outbox.Add(outboxEntry("gone", "removed-channel", time.Now())); err != nil {
+		t.Fatal(err)
+	}
+
+	q, _ := newTestDispatcher(t)
+	q.useOutbox(outbox, func(name string) (config.Channel, bool) {
+		return config.Channel{Type: "slack", WebhookURL: server.URL}, name == "slack"
+	})
+	q.flush()
+
+	if got := atomic.LoadInt32(calls); got != 0 {
+		t.Errorf("sends = %d, want 0", got)
+	}
+	if outbox.Len() != 0 {
+		t.Errorf("pending = %s, want none", pendingKeys(outbox.Pending()))
+	}
+}
+
+func TestDispatcher_OutboxDropsSupersededAlert(t *testing.T) {
+	// The DOWN alert fails all its attempts, then the channel is back
+	statuses := make([]int, maxSendAttempts)
+	for i := range statuses {
+		statuses[i] = 503
+	}
+	server, calls := statusServer(t, statuses...)
+
+	outbox := openTestOutbox(t, filepath.Join(t.TempDir(), "outbox.jsonl"))
+	defer outbox.Close()
+
+	q, m := newTestDispatcher(t)
+	q.useOutbox(outbox, func(name string) (config.Channel, bool) {
+		return config.Channel{Type: "slack", WebhookURL: server.URL}, true
+	})
+
+	now := time.Now()
+	down := slackDelivery("slack", server.URL, "down")
+	down.queuedAt = now
+	q.submit(down)
+	q.flush()
+	if outbox.Len() != 1 {
+		t.Fatalf("pending after failed DOWN = %d, want 1", outbox.Len())
+	}
+
+	recovery := slackDelivery("slack", server.URL, "recovery")
+	recovery.queuedAt = now.Add(time.Second)
+	q.submit(recovery)
+	q.flush()
+
+	// The recovery went out, so the DOWN alert must not follow it
+	q.redeliver(now.Add(outboxRetryInterval))
+	q.flush()
+
+	if got := atomic.LoadInt32(calls); got != maxSendAttempts+1 {
+		t.Errorf("sends = %d, want %d", got, maxSendAttempts+1)
+	}
+	if outbox.Len() != 0 {
+		t.Errorf("pending = %s, want none", pendingKeys(outbox.Pending()))
+	}
+	if got := testutil.ToFloat64(m.AlertDeliveries.WithLabelValues("slack", metrics.AlertSuperseded)); got != 1 {
+		t.Errorf("superseded = %v, want 1", got)
+	}
+}
+
+func TestDispatcher_StandbySendsNothing(t *testing.T) {
+	server, calls := statusServer(t)
+
+	outbox := openTestOutbox(t, filepath.Join(t.TempDir(), "outbox.jsonl"))
+	defer outbox.Close()
+	if err := outbox.Add(outboxEntry("left", "slack", time.Now())); err != nil {
+		t.Fatal(err)
+	}
+
+	q, _ := newTestDispatcher(t)
+	q.setStandby(true)
+	q.useOutbox(outbox, func(name string) (config.Channel, bool) {
+		return config.Channel{Type: "slack", WebhookURL: server.URL}, true
+	})
+
+	d := slackDelivery("slack", server.URL, "down")
+	d.queuedAt = time.Now()
+	q.submit(d)
+	q.redeliver(time.Now())
+	q.flush()
+
+	if got := atomic.LoadInt32(calls); got != 0 {
+		t.Errorf("sends on standby = %d, want 0", got)
+	}
+	if got := pendingKeys(outbox.Pending()); got != "left" {
+		t.Errorf("pending on standby = %s, want left", got)
+	}
+
+	// Once leading, the outbox is sent
+	q.setStandby(false)
+	q.redeliver(time.Now())
+	q.flush()
+
+	if got := atomic.LoadInt32(calls); got != 1 {
+		t.Errorf("sends after promotion = %d, want 1", got)
+	}
+	if outbox.Len() != 0 {
+		t.Errorf("pending after promotion = %s, want none", pendingKeys(outbox.Pending()))
+	}
+}
@@ -0,0 +1,84 @@
+package alerting
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"uptiq/internal/config"
+)
+
+// PagerDuty Events API v2 constants.
+const (
+	pagerDutyTrigger    = "trigger"
+	pagerDutyResolve    = "resolve"
+	pagerDutyClient     = "uptiq"
+	pagerDutyMaxSummary = 1024
+)
+
+// pagerDutyEvent is a PagerDuty Events API v2 request.
+type pagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"`
+	DedupKey    string            `json:"dedup_key"`
+	Client      string            `json:"client,omitempty"`
+	Payload     *pagerDutyPayload `json:"payload,omitempty"` // Trigger only
+}
+
+type pagerDutyPayload struct {
+	Summary       string            `json:"summary"`
+	Source        string            `json:"source"`
+	Severity      string            `json:"severity"`
+	Timestamp     string            `json:"timestamp"`
+	Component     string            `json:"component,omitempty"`
+	CustomDetails map[string]string `json:"custom_details,omitempty"`
+}
+
+// sendPagerDuty triggers an incident for a DOWN alert and resolves it on
+// recovery.
+func (s *ChannelSender) sendPagerDuty(ctx context.Context, ch config.Channel, payload AlertPayload) error {
+	if strings.TrimSpace(ch.RoutingKey) == "" {
+		return errors.New("empty pagerduty routing_key")
+	}
+	if payload.Context.Service.ID == "" {
+		return errors.New("alert has no service_id for the pagerduty dedup_key")
+	}
+
+	apiURL := ch.APIURL
+	if apiURL == "" {
+		apiURL = config.DefaultPagerDutyURL
+	}
+
+	event := pagerDutyEvent{
+		RoutingKey: ch.RoutingKey,
+		DedupKey:   incidentKey(payload.Context.Service.ID),
+		Client:     pagerDutyClient,
+	}
+	if payload.Kind == "recovery" {
+		event.EventAction = pagerDutyResolve
+		return s.postJSON(ctx, apiURL, event)
+	}
+
+	severity := ch.Severity
+	if severity == "" {
+		severity = config.DefaultPagerDutySeverity
+	}
+	source := payload.Context.Service.Target
+	if source == "" {
+		source = payload.Context.Service.ID
+	}
+
+	event.EventAction = pagerDutyTrigger
+	event.Payload = &pagerDutyPayload{
+		Summary:   truncate(alertTitle(payload), pagerDutyMaxSummary),
+		Source:    source,
+		Severity:  severity,
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+		Component: payload.Context.Service.ID,
+		CustomDetails: map[string]string{
+			"details": payload.EmailBody,
+		},
+	}
+	return s.postJSON(ctx, apiURL, event)
+}
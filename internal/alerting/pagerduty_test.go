@@ -0,0 +1,126 @@
+package alerting
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"uptiq/internal/checks"
+	"uptiq/internal/config"
+	"uptiq/internal/templates"
+)
+
+// pagerDutyServer stands in for the Events API and records the events.
+func pagerDutyServer(t *testing.T) (*httptest.Server, func() []pagerDutyEvent) {
+	t.Helper()
+
+	var (
+		mu     sync.Mutex
+		events []pagerDutyEvent
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event pagerDutyEvent
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		events = append(events, event)
+		mu.Unlock()
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func() []pagerDutyEvent {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]pagerDutyEvent(nil), events...)
+	}
+}
+
+func TestSendPagerDuty_IncidentLifecycle(t *testing.T) {
+	server, events := pagerDutyServer(t)
+	ch := config.Channel{Type: "pagerduty", RoutingKey: "key", APIURL: server.URL}
+	svc := config.Service{ID: "web", Name: "Web", Type: "http", URL: "https://example.com"}
+
+	b := NewMessageBuilder()
+	sender := NewChannelSender()
+	for _, payload := range []AlertPayload{
+		b.DownAlert(svc, checks.Result{Error: "connection refused"}, 3, 3),
+		b.StillDownAlert(svc, checks.Result{Error: "connection refused"}, 6, 3),
+		b.RecoveryAlert(svc, checks.Result{Success: true, Latency: time.Millisecond}),
+	} {
+		if res := sender.Send(context.Background(), ch, payload); !res.Success {
+			t.Fatalf("Send(%s): %v", payload.Kind, res.Error)
+		}
+	}
+
+	got := events()
+	if len(got) != 3 {
+		t.Fatalf("events = %d, want 3", len(got))
+	}
+
+	wantActions := []string{pagerDutyTrigger, pagerDutyTrigger, pagerDutyResolve}
+	for i, event := range got {
+		if event.EventAction != wantActions[i] {
+			t.Errorf("event %d action = %q, want %q", i, event.EventAction, wantActions[i])
+		}
+		if event.DedupKey != "uptiq/web" {
+			t.Errorf("event %d dedup_key = %q, want uptiq/web", i, event.DedupKey)
+		}
+		if event.RoutingKey != "key" {
+			t.Errorf("event %d routing_key = %q, want key", i, event.RoutingKey)
+		}
+	}
+
+	trigger := got[0].Payload
+	if trigger == nil {
+		t.Fatal("trigger has no payload")
+	}
+	if trigger.Severity != config.DefaultPagerDutySeverity {
+		t.Errorf("severity = %q, want %q", trigger.Severity, config.DefaultPagerDutySeverity)
+	}
+	if trigger.Summary != "[DOWN] Web (web)" {
+		t.Errorf("summary = %q, want [DOWN] Web (web)", trigger.Summary)
+	}
+	if trigger.Source != "https://example.com" {
+		t.Errorf("source = %q, want the service URL", trigger.Source)
+	}
+	if got[2].Payload != nil {
+		t.Error("resolve should carry no payload")
+	}
+}
+
+func TestSendPagerDuty_Errors(t *testing.T) {
+	server, _ := pagerDutyServer(t)
+
+	tests := []struct {
+		name    string
+		channel config.Channel
+		payload AlertPayload
+	}{
+		{
+			name:    "missing routing key",
+			channel: config.Channel{Type: "pagerduty", APIURL: server.URL},
+			payload: AlertPayload{Kind: "down", Context: templates.Alert{Service: templates.Service{ID: "web"}}},
+		},
+		{
+			name:    "missing service id",
+			channel: config.Channel{Type: "pagerduty", RoutingKey: "key", APIURL: server.URL},
+			payload: AlertPayload{Kind: "down"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			res := NewChannelSender().Send(context.Background(), tc.channel, tc.payload)
+			if res.Success || res.Error == nil {
+				t.Errorf("Send = %+v, want an error", res)
+			}
+		})
+	}
+}
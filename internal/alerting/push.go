@@ -0,0 +1,67 @@
+package alerting
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"uptiq/internal/config"
+)
+
+// ntfy tags shown as emoji before the title.
+const (
+	ntfyDownTag     = "rotating_light"
+	ntfyRecoveryTag = "white_check_mark"
+)
+
+// sendNtfy publishes the alert to an ntfy topic.
+func (s *ChannelSender) sendNtfy(ctx context.Context, ch config.Channel, payload AlertPayload) error {
+	if strings.TrimSpace(ch.Topic) == "" {
+		return errors.New("empty ntfy topic")
+	}
+
+	apiURL := strings.TrimRight(ch.APIURL, "/")
+	if apiURL == "" {
+		apiURL = config.DefaultNtfyURL
+	}
+
+	tag := ntfyDownTag
+	if payload.Kind == "recovery" {
+		tag = ntfyRecoveryTag
+	}
+	msg := map[string]any{
+		"topic":   ch.Topic,
+		"title":   alertTitle(payload),
+		"message": payload.WebhookMessage,
+		"tags":    append([]string{tag}, ch.Tags...),
+	}
+	if p, ok := config.NtfyPriorities[ch.Priority]; ok {
+		msg["priority"] = p
+	}
+
+	var headers map[string]string
+	if ch.Token != "" {
+		headers = map[string]string{"Authorization": "Bearer " + ch.Token}
+	}
+	return s.sendJSON(ctx, http.MethodPost, apiURL, msg, headers)
+}
+
+// sendGotify sends the alert as a Gotify application message.
+func (s *ChannelSender) sendGotify(ctx context.Context, ch config.Channel, payload AlertPayload) error {
+	if strings.TrimSpace(ch.APIURL) == "" || strings.TrimSpace(ch.Token) == "" {
+		return errors.New("gotify api_url and token are required")
+	}
+
+	msg := map[string]any{
+		"title":   alertTitle(payload),
+		"message": payload.WebhookMessage,
+	}
+	if p, err := strconv.Atoi(ch.Priority); err == nil {
+		msg["priority"] = p
+	}
+
+	return s.sendJSON(ctx, http.MethodPost, strings.TrimRight(ch.APIURL, "/")+"/message", msg,
+		map[string]string{"X-Gotify-Key": ch.Token})
+}
@@ -10,9 +10,10 @@ import (
 
 // ResolvedRoute contains the computed routing configuration for a service.
 type ResolvedRoute struct {
-	Channels []string
-	Policy   ResolvedPolicy
-	Valid    bool
+	Channels  []string
+	Policy    ResolvedPolicy
+	Templates config.MessageTemplates // Override the channels' templates
+	Valid     bool
 }
 
 // ResolvedPolicy contains the computed policy settings.
@@ -27,6 +28,7 @@ type compiledRoute struct {
 	matchServiceIDs []string
 	channels        []string
 	policy          ResolvedPolicy
+	templates       config.MessageTemplates
 }
 
 // Router resolves which channels and policies apply to services.
@@ -46,6 +48,7 @@ func NewRouter(cfg config.AlertingConfig) *Router {
 			matchServiceIDs: cleanStrings(route.Match.ServiceIDs),
 			channels:        cleanStrings(route.Notify),
 			policy:          compilePolicy(route.Policy),
+			templates:       route.Templates,
 		}
 		r.routes = append(r.routes, compiled)
 
@@ -65,6 +68,7 @@ func NewRouter(cfg config.AlertingConfig) *Router {
 //   - failure_threshold: max (reduces spam)
 //   - cooldown: max (reduces spam)
 //   - recovery_alert: true if any route enables it
+//   - templates: the first route that sets a template wins
 func (r *Router) Resolve(serviceID string) ResolvedRoute {
 	indices := r.routeIndex[serviceID]
 	if len(indices) == 0 {
@@ -72,6 +76,7 @@ func (r *Router) Resolve(serviceID string) ResolvedRoute {
 	}
 
 	var channels []string
+	var tmpl config.MessageTemplates
 	policy := ResolvedPolicy{FailureThreshold: 1} // baseline
 
 	for i, idx := range indices {
@@ -90,6 +95,7 @@ func (r *Router) Resolve(serviceID string) ResolvedRoute {
 		} else {
 			policy = mergePolicy(policy, route.policy)
 		}
+		tmpl = mergeTemplates(tmpl, route.templates)
 	}
 
 	if len(channels) == 0 {
@@ -97,9 +103,10 @@ func (r *Router) Resolve(serviceID string) ResolvedRoute {
 	}
 
 	return ResolvedRoute{
-		Channels: channels,
-		Policy:   policy,
-		Valid:    true,
+		Channels:  channels,
+		Policy:    policy,
+		Templates: tmpl,
+		Valid:     true,
 	}
 }
 
@@ -138,6 +145,26 @@ func mergePolicy(base, other ResolvedPolicy) ResolvedPolicy {
 	return result
 }
 
+// mergeTemplates fills the templates base does not set from other.
+func mergeTemplates(base, other config.MessageTemplates) config.MessageTemplates {
+	result := base
+
+	if result.Message == "" {
+		result.Message = other.Message
+	}
+	if result.Subject == "" {
+		result.Subject = other.Subject
+	}
+	if result.Body == "" {
+		result.Body = other.Body
+	}
+	if result.HTMLBody == "" {
+		result.HTMLBody = other.HTMLBody
+	}
+
+	return result
+}
+
 // cleanStrings trims whitespace and removes empty strings.
 func cleanStrings(in []string) []string {
 	var out []string
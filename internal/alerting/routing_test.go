@@ -91,6 +91,38 @@ func TestRouter_Resolve_MultipleRoutes(t *testing.T) {
 	}
 }
 
+func TestRouter_Resolve_Templates(t *testing.T) {
+	cfg := config.AlertingConfig{
+		Routes: []config.Route{
+			{
+				Match:     config.RouteMatch{ServiceIDs: []string{"svc-1"}},
+				Notify:    []string{"discord"},
+				Templates: config.MessageTemplates{Subject: "first subject"},
+			},
+			{
+				Match:     config.RouteMatch{ServiceIDs: []string{"svc-1", "svc-2"}},
+				Notify:    []string{"slack"},
+				Templates: config.MessageTemplates{Subject: "second subject", Body: "second body"},
+			},
+		},
+	}
+
+	router := NewRouter(cfg)
+
+	// First route that sets a template wins, per template
+	got := router.Resolve("svc-1").Templates
+	want := config.MessageTemplates{Subject: "first subject", Body: "second body"}
+	if got != want {
+		t.Errorf("svc-1 templates = %+v, want %+v", got, want)
+	}
+
+	got = router.Resolve("svc-2").Templates
+	want = config.MessageTemplates{Subject: "second subject", Body: "second body"}
+	if got != want {
+		t.Errorf("svc-2 templates = %+v, want %+v", got, want)
+	}
+}
+
 func TestRouter_Resolve_DuplicateChannels(t *testing.T) {
 	cfg := config.AlertingConfig{
 		Routes: []config.Route{
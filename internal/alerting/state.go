@@ -12,15 +12,22 @@ const (
 	StateUnknown AlertState = "UNKNOWN"
 	StateUp      AlertState = "UP"
 	StateDown    AlertState = "DOWN"
+
+	// StateUnreachable marks a failing service whose parent dependency is down.
+	StateUnreachable AlertState = "UNREACHABLE"
+
+	// StateMaintenance marks a service inside an active maintenance window.
+	StateMaintenance AlertState = "MAINTENANCE"
 )
 
 // ServiceState tracks the alert state for a single service.
 type ServiceState struct {
-	State               AlertState
-	ConsecutiveFailures int
-	LastDownAlertAt     time.Time
-	DownNotified        bool // Whether we sent a DOWN alert for current outage
-	LastResultAt        time.Time
+	State               AlertState `json:"state"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	LastDownAlertAt     time.Time  `json:"last_down_alert_at"`
+	DownNotified        bool       `json:"down_notified"` // Whether we sent a DOWN alert for current outage
+	LastResultAt        time.Time  `json:"last_result_at"`
+	FailingSince        time.Time  `json:"failing_since"` // First failure of the current or last failure streak
 }
 
 // StateManager manages alert state for all services.
@@ -62,3 +69,61 @@ func (m *StateManager) WithState(serviceID string, fn func(*ServiceState)) {
 	}
 	fn(st)
 }
+
+// Lookup returns a copy of the state for a service without creating it.
+func (m *StateManager) Lookup(serviceID string) (ServiceState, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	st, ok := m.state[serviceID]
+	if !ok {
+		return ServiceState{}, false
+	}
+	return *st, true
+}
+
+// Snapshot returns a copy of the state of every service.
+func (m *StateManager) Snapshot() map[string]ServiceState {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	snapshot := make(map[string]ServiceState, len(m.state))
+	for id, st := range m.state {
+		snapshot[id] = *st
+	}
+	return snapshot
+}
+
+// Restore replaces the state of every service with snapshot.
+func (m *StateManager) Restore(snapshot map[string]ServiceState) {
+	state := make(map[string]*ServiceState, len(snapshot))
+	for id, st := range snapshot {
+		state[id] = &st
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.state = state
+}
+
+// Retain drops the state of every service not in serviceIDs and returns
+// how many it dropped.
+func (m *StateManager) Retain(serviceIDs []string) int {
+	keep := make(map[string]struct{}, len(serviceIDs))
+	for _, id := range serviceIDs {
+		keep[id] = struct{}{}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	dropped := 0
+	for id := range m.state {
+		if _, ok := keep[id]; !ok {
+			delete(m.state, id)
+			dropped++
+		}
+	}
+	return dropped
+}
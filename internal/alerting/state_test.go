@@ -326,3 +326,53 @@ func TestServiceState_AlertNotificationTracking(t *testing.T) {
 		t.Error("DownNotified should be cleared after recovery")
 	}
 }
+
+func TestStateManager_SnapshotRestore(t *testing.T) {
+	sm := NewStateManager()
+	sm.WithState("svc-1", func(st *ServiceState) {
+		st.State = StateDown
+		st.DownNotified = true
+	})
+
+	snapshot := sm.Snapshot()
+
+	// The snapshot is a copy
+	sm.WithState("svc-1", func(st *ServiceState) {
+		st.State = StateUp
+	})
+	if snapshot["svc-1"].State != StateDown {
+		t.Errorf("snapshot state = %q, want %q", snapshot["svc-1"].State, StateDown)
+	}
+
+	other := NewStateManager()
+	other.Get("stale")
+	other.Restore(snapshot)
+
+	if _, ok := other.Lookup("stale"); ok {
+		t.Error("Restore should drop services missing from the snapshot")
+	}
+	st, ok := other.Lookup("svc-1")
+	if !ok || st.State != StateDown || !st.DownNotified {
+		t.Errorf("restored state = %+v, want DOWN and notified", st)
+	}
+}
+
+func TestStateManager_Retain(t *testing.T) {
+	sm := NewStateManager()
+	sm.Get("web")
+	sm.Get("db")
+	sm.Get("removed")
+
+	if dropped := sm.Retain([]string{"web", "db", "new"}); dropped != 1 {
+		t.Errorf("dropped = %d, want 1", dropped)
+	}
+	if _, ok := sm.Lookup("removed"); ok {
+		t.Error("state of removed service should be dropped")
+	}
+	if _, ok := sm.Lookup("new"); ok {
+		t.Error("Retain should not create state")
+	}
+	if len(sm.Snapshot()) != 2 {
+		t.Errorf("services = %d, want 2", len(sm.Snapshot()))
+	}
+}
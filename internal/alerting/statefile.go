@@ -0,0 +1,74 @@
+package alerting
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// stateFile is the on-disk form of a state snapshot.
+type stateFile struct {
+	SavedAt  time.Time               `json:"saved_at"`
+	Services map[string]ServiceState `json:"services"`
+}
+
+// SaveState writes snapshot to path, replacing the previous file
+// atomically so a crash mid-write leaves the old snapshot intact.
+func SaveState(path string, snapshot map[string]ServiceState) error {
+	data, err := json.Marshal(stateFile{SavedAt: time.Now().UTC(), Services: snapshot})
+	if err != nil {
+		return fmt.Errorf("encode state: %w", err)
+	}
+
+	// A unique temp file, so concurrent saves cannot interleave
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("create state file: %w", err)
+	}
+	tmp := file.Name()
+
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		os.Remove(tmp)
+		return fmt.Errorf("write state file: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		os.Remove(tmp)
+		return fmt.Errorf("sync state file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("close state file: %w", err)
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("replace state file: %w", err)
+	}
+	return nil
+}
+
+// LoadState reads the snapshot saved at path and when it was saved. A
+// missing file is an empty snapshot.
+func LoadState(path string) (map[string]ServiceState, time.Time, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]ServiceState{}, time.Time{}, nil
+	}
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("read state file: %w", err)
+	}
+
+	var sf stateFile
+	if err := json.Unmarshal(data, &sf); err != nil {
+		return nil, time.Time{}, fmt.Errorf("decode state file %s: %w", path, err)
+	}
+	if sf.Services == nil {
+		sf.Services = map[string]ServiceState{}
+	}
+	return sf.Services, sf.SavedAt, nil
+}
@@ -0,0 +1,79 @@
+package alerting
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSaveLoadState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	downAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	snapshot := map[string]ServiceState{
+		"web": {State: StateDown, ConsecutiveFailures: 4, DownNotified: true, LastDownAlertAt: downAt},
+		"db":  {State: StateUp},
+	}
+	if err := SaveState(path, snapshot); err != nil {
+		t.Fatalf("SaveState: %v", err)
+	}
+	// Saving again replaces the file
+	if err := SaveState(path, snapshot); err != nil {
+		t.Fatalf("SaveState: %v", err)
+	}
+
+	got, savedAt, err := LoadState(path)
+	if err != nil {
+		t.Fatalf("LoadState: %v", err)
+	}
+	if savedAt.IsZero() {
+		t.Error("saved_at should be set")
+	}
+	if len(got) != 2 {
+		t.Fatalf("services = %d, want 2", len(got))
+	}
+	web := got["web"]
+	if web.State != StateDown || web.ConsecutiveFailures != 4 || !web.DownNotified || !web.LastDownAlertAt.Equal(downAt) {
+		t.Errorf("web = %+v, want %+v", web, snapshot["web"])
+	}
+
+	// No temp files are left behind
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("files in data dir = %d, want 1", len(entries))
+	}
+}
+
+func TestLoadState(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string // Empty: no file
+		wantErr  bool
+		wantSize int
+	}{
+		{name: "missing file"},
+		{name: "no services", content: `{"saved_at":"2026-03-01T12:00:00Z"}`},
+		{name: "one service", content: `{"services":{"web":{"state":"DOWN"}}}`, wantSize: 1},
+		{name: "corrupt", content: `{"services":`, wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "state.json")
+			if tc.content != "" {
+				if err := os.WriteFile(path, []byte(tc.content), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			got, _, err := LoadState(path)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("LoadState error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if !tc.wantErr && len(got) != tc.wantSize {
+				t.Errorf("services = %d, want %d", len(got), tc.wantSize)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package alerting
+
+import "sync"
+
+// undeliveredAlerts tracks the alerts queued but not yet delivered or given
+// up for good, so an HA standby is only told about alerts that went out.
+type undeliveredAlerts struct {
+	mu    sync.Mutex
+	keys  map[string]string       // Delivery key to service ID
+	count map[string]int          // Undelivered alerts per service
+	base  map[string]ServiceState // State before the first undelivered alert
+}
+
+func newUndeliveredAlerts() *undeliveredAlerts {
+	return &undeliveredAlerts{
+		keys:  make(map[string]string),
+		count: make(map[string]int),
+		base:  make(map[string]ServiceState),
+	}
+}
+
+// add records the delivery key for an alert about serviceID, sent from
+// before, the service's state ahead of the alert.
+func (u *undeliveredAlerts) add(key, serviceID string, before ServiceState) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if _, ok := u.keys[key]; ok {
+		return
+	}
+	u.keys[key] = serviceID
+	if u.count[serviceID] == 0 {
+		u.base[serviceID] = before
+	}
+	u.count[serviceID]++
+}
+
+// done forgets the delivery key once its alert is delivered or given up
+// for good. Keys it does not know, e.g. from a previous run's outbox, are
+// ignored.
+func (u *undeliveredAlerts) done(key string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	serviceID, ok := u.keys[key]
+	if !ok {
+		return
+	}
+	delete(u.keys, key)
+
+	u.count[serviceID]--
+	if u.count[serviceID] == 0 {
+		delete(u.count, serviceID)
+		delete(u.base, serviceID)
+	}
+}
+
+// apply rolls back the alert bookkeeping in snapshot for services with
+// undelivered alerts: whether DOWN was notified and when the last DOWN
+// alert went out are taken from before the first of them.
+func (u *undeliveredAlerts) apply(snapshot map[string]ServiceState) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	for id, before := range u.base {
+		st, ok := snapshot[id]
+		if !ok {
+			continue
+		}
+		if before.DownNotified && !st.DownNotified {
+			// The recovery is undelivered: the outage is still open
+			st.State = before.State
+		}
+		st.DownNotified = before.DownNotified
+		st.LastDownAlertAt = before.LastDownAlertAt
+		snapshot[id] = st
+	}
+}
@@ -0,0 +1,144 @@
+package alerting
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"uptiq/internal/checks"
+	"uptiq/internal/config"
+	"uptiq/internal/templates"
+)
+
+type webhookRequest struct {
+	Method string
+	Header http.Header
+	Body   []byte
+}
+
+func webhookServer(t *testing.T) (*httptest.Server, *webhookRequest) {
+	t.Helper()
+
+	var last webhookRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		last = webhookRequest{Method: r.Method, Header: r.Header.Clone(), Body: body}
+	}))
+	t.Cleanup(server.Close)
+	return server, &last
+}
+
+func TestSendWebhook_Template(t *testing.T) {
+	server, last := webhookServer(t)
+	ch := config.Channel{
+		Type:       "webhook",
+		WebhookURL: server.URL,
+		Method:     "put",
+		Headers: map[string]string{
+			"X-Service":    "{{ .Service.ID }}",
+			"Content-Type": "application/vnd.incident+json",
+		},
+		Body: `{"id": {{ json .Service.ID }}, "state": "{{ .State }}", "failures": {{ .Failures }}, "summary": {{ json .Subject }}}`,
+	}
+	svc := config.Service{ID: "web", Name: "Web", Type: "http", URL: "https://example.com"}
+	payload := NewMessageBuilder().DownAlert(svc, checks.Result{StatusCode: 502}, 3, 3)
+
+	if res := NewChannelSender().Send(context.Background(), ch, payload); !res.Success {
+		t.Fatalf("Send: %v", res.Error)
+	}
+
+	if last.Method != http.MethodPut {
+		t.Errorf("method = %s, want PUT", last.Method)
+	}
+	if got := last.Header.Get("X-Service"); got != "web" {
+		t.Errorf("X-Service = %q, want web", got)
+	}
+	if got := last.Header.Get("Content-Type"); got != "application/vnd.incident+json" {
+		t.Errorf("Content-Type = %q, want the configured one", got)
+	}
+	if last.Header.Get(webhookSignatureHeader) != "" {
+		t.Error("request without a secret should not be signed")
+	}
+
+	want := `{"id": "web", "state": "DOWN", "failures": 3, "summary": "[DOWN] Web (web)"}`
+	if string(last.Body) != want {
+		t.Errorf("body = %s, want %s", last.Body, want)
+	}
+}
+
+func TestSendWebhook_DefaultBody(t *testing.T) {
+	server, last := webhookServer(t)
+	ch := config.Channel{Type: "webhook", WebhookURL: server.URL}
+	svc := config.Service{ID: "web", Name: "Web", Type: "tcp", Host: "db", Port: 5432}
+
+	if res := NewChannelSender().Send(context.Background(), ch, NewMessageBuilder().RecoveryAlert(svc, checks.Result{Success: true})); !res.Success {
+		t.Fatalf("Send: %v", res.Error)
+	}
+
+	var got templates.Data
+	if err := json.Unmarshal(last.Body, &got); err != nil {
+		t.Fatalf("body is not the alert as JSON: %v\n%s", err, last.Body)
+	}
+	if got.Kind != "recovery" || got.State != "UP" || got.Service.Target != "db:5432" || got.Subject != "[UP] Web (web)" {
+		t.Errorf("body = %+v", got)
+	}
+	if last.Method != http.MethodPost || last.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("request = %s %s, want POST application/json", last.Method, last.Header.Get("Content-Type"))
+	}
+}
+
+func TestSendWebhook_Signature(t *testing.T) {
+	server, last := webhookServer(t)
+	ch := config.Channel{Type: "webhook", WebhookURL: server.URL, Secret: "s3cret"}
+	svc := config.Service{ID: "web", Name: "Web", Type: "http", URL: "https://example.com"}
+
+	if res := NewChannelSender().Send(context.Background(), ch, NewMessageBuilder().DownAlert(svc, checks.Result{}, 1, 1)); !res.Success {
+		t.Fatalf("Send: %v", res.Error)
+	}
+
+	// Verify the way a receiver would
+	timestamp := last.Header.Get(webhookTimestampHeader)
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(last.Body)
+	want := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	if got := last.Header.Get(webhookSignatureHeader); got != want {
+		t.Errorf("signature = %q, want %q", got, want)
+	}
+}
+
+func TestSignWebhook(t *testing.T) {
+	at := time.Unix(1700000000, 0)
+
+	ts, sig := signWebhook("key", at, []byte(`{"a":1}`))
+	if ts != "1700000000" {
+		t.Errorf("timestamp = %s, want 1700000000", ts)
+	}
+	if _, other := signWebhook("other", at, []byte(`{"a":1}`)); other == sig {
+		t.Error("a different secret should give a different signature")
+	}
+	if _, other := signWebhook("key", at, []byte(`{"a":2}`)); other == sig {
+		t.Error("a different body should give a different signature")
+	}
+}
+
+func TestSendWebhook_TemplateErrorNotRetried(t *testing.T) {
+	server, _ := webhookServer(t)
+	ch := config.Channel{Type: "webhook", WebhookURL: server.URL, Body: "{{ .Nope }}"}
+
+	res := NewChannelSender().Send(context.Background(), ch, AlertPayload{Kind: "down"})
+	if res.Success {
+		t.Fatal("expected a render error")
+	}
+	if _, retry := retryDelay(res.Error, time.Second); retry {
+		t.Errorf("render error should not be retried: %v", res.Error)
+	}
+}
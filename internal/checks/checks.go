@@ -12,6 +12,12 @@ type Result struct {
 	StatusCode int // HTTP status code (0 for non-HTTP checks)
 	Latency    time.Duration
 	Error      string
+	Warning    string    // Non-fatal issue worth surfacing on a successful check
+	Expiry     time.Time // Domain registration expiry (zero for non-domain checks)
+
+	// NTP-specific fields (zero for non-NTP checks or when no reply was received)
+	ClockOffset time.Duration
+	Stratum     int
 }
 
 // Checker performs health checks on services.
@@ -21,15 +27,19 @@ type Checker interface {
 
 // Factory creates appropriate checkers for different service types.
 type Factory struct {
-	http *HTTPChecker
-	tcp  *TCPChecker
+	http   *HTTPChecker
+	tcp    *TCPChecker
+	domain *DomainChecker
+	ntp    *NTPChecker
 }
 
 // NewFactory creates a new Checker factory with initialized checkers.
 func NewFactory() *Factory {
 	return &Factory{
-		http: NewHTTPChecker(),
-		tcp:  NewTCPChecker(),
+		http:   NewHTTPChecker(),
+		tcp:    NewTCPChecker(),
+		domain: NewDomainChecker(),
+		ntp:    NewNTPChecker(),
 	}
 }
 
@@ -41,6 +51,12 @@ func (f *Factory) CheckerFor(svc config.Service) Checker {
 	if svc.IsTCP() {
 		return f.tcp
 	}
+	if svc.IsDomain() {
+		return f.domain
+	}
+	if svc.IsNTP() {
+		return f.ntp
+	}
 	return nil
 }
 
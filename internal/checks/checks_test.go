@@ -29,6 +29,16 @@ func TestFactory_CheckerFor(t *testing.T) {
 			service:     config.Service{Type: "tcp"},
 			checkerType: "*checks.TCPChecker",
 		},
+		{
+			name:        "domain service",
+			service:     config.Service{Type: "domain"},
+			checkerType: "*checks.DomainChecker",
+		},
+		{
+			name:        "ntp service",
+			service:     config.Service{Type: "ntp"},
+			checkerType: "*checks.NTPChecker",
+		},
 		{
 			name:        "unknown service",
 			service:     config.Service{Type: "grpc"},
@@ -54,6 +64,10 @@ func TestFactory_CheckerFor(t *testing.T) {
 					gotType = "*checks.HTTPChecker"
 				case *TCPChecker:
 					gotType = "*checks.TCPChecker"
+				case *DomainChecker:
+					gotType = "*checks.DomainChecker"
+				case *NTPChecker:
+					gotType = "*checks.NTPChecker"
 				default:
 					gotType = "unknown"
 				}
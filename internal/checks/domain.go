@@ -0,0 +1,240 @@
+package checks
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"uptiq/internal/config"
+)
+
+// Domain checker configuration constants.
+const (
+	domainDialTimeout     = 5 * time.Second
+	domainMaxResponseBody = 256 * 1024 // 256 KiB
+	domainDay             = 24 * time.Hour
+)
+
+// whoisExpiryKeys lists the WHOIS field names registries use for the expiry date.
+var whoisExpiryKeys = []string{
+	"registry expiry date",
+	"registrar registration expiration date",
+	"expiration date",
+	"expiry date",
+	"expires on",
+	"expires",
+	"expire",
+	"paid-till",
+	"renewal date",
+}
+
+// whoisDateLayouts lists the date formats commonly found in WHOIS responses.
+var whoisDateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05Z",
+	"2006-01-02T15:04:05.0Z",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04:05 MST",
+	"2006-01-02",
+	"2006.01.02",
+	"2006/01/02",
+	"02-Jan-2006",
+	"02.01.2006",
+	"January 2 2006",
+}
+
+// DomainChecker checks domain registration expiry via RDAP or WHOIS.
+type DomainChecker struct {
+	client *http.Client
+	dialer net.Dialer
+	now    func() time.Time
+}
+
+// NewDomainChecker creates a new DomainChecker instance.
+func NewDomainChecker() *DomainChecker {
+	return &DomainChecker{
+		client: &http.Client{},
+		dialer: net.Dialer{Timeout: domainDialTimeout},
+		now:    time.Now,
+	}
+}
+
+func (c *DomainChecker) Check(ctx context.Context, svc config.Service) Result {
+	start := time.Now()
+
+	var (
+		expiry time.Time
+		status int
+		err    error
+	)
+
+	switch config.DomainLookup(svc.Lookup) {
+	case config.DomainLookupWHOIS:
+		expiry, err = c.lookupWHOIS(ctx, svc.LookupServer, svc.Domain)
+	default:
+		expiry, status, err = c.lookupRDAP(ctx, svc.LookupServer, svc.Domain)
+	}
+
+	if err != nil {
+		return Result{
+			Success:    false,
+			StatusCode: status,
+			Latency:    time.Since(start),
+			Error:      err.Error(),
+		}
+	}
+
+	result := Result{
+		Success:    true,
+		StatusCode: status,
+		Latency:    time.Since(start),
+		Expiry:     expiry,
+	}
+	c.evaluateExpiry(&result, svc)
+
+	return result
+}
+
+// evaluateExpiry fails the result within fail_within_days of expiry and
+// adds a warning within warn_within_days. Warnings do not alert: they are
+// logged and shown in the API, like other check warnings.
+func (c *DomainChecker) evaluateExpiry(result *Result, svc config.Service) {
+	warnDays, failDays := svc.ExpiryWindows()
+	remaining := result.Expiry.Sub(c.now())
+	days := int(remaining / domainDay)
+	date := result.Expiry.UTC().Format("2006-01-02")
+
+	switch {
+	case remaining <= 0:
+		result.Success = false
+		result.Error = fmt.Sprintf("domain expired on %s", date)
+	case days < failDays:
+		result.Success = false
+		result.Error = fmt.Sprintf("domain expires in %d days (%s)", days, date)
+	case days < warnDays:
+		result.Warning = fmt.Sprintf("domain expires in %d days (%s)", days, date)
+	}
+}
+
+// rdapResponse is the subset of an RDAP domain object we care about.
+type rdapResponse struct {
+	Events []struct {
+		Action string `json:"eventAction"`
+		Date   string `json:"eventDate"`
+	} `json:"events"`
+}
+
+func (c *DomainChecker) lookupRDAP(ctx context.Context, server, domain string) (time.Time, int, error) {
+	endpoint := strings.TrimRight(server, "/") + "/domain/" + url.PathEscape(domain)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return time.Time{}, 0, fmt.Errorf("build request: %v", err)
+	}
+	req.Header.Set("Accept", "application/rdap+json, application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return time.Time{}, resp.StatusCode, fmt.Errorf("unexpected rdap status %d", resp.StatusCode)
+	}
+
+	var body rdapResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, domainMaxResponseBody)).Decode(&body); err != nil {
+		return time.Time{}, resp.StatusCode, fmt.Errorf("decode rdap response: %v", err)
+	}
+
+	for _, ev := range body.Events {
+		if !strings.EqualFold(ev.Action, "expiration") {
+			continue
+		}
+		expiry, err := time.Parse(time.RFC3339, ev.Date)
+		if err != nil {
+			return time.Time{}, resp.StatusCode, fmt.Errorf("parse rdap expiration %q: %v", ev.Date, err)
+		}
+		return expiry, resp.StatusCode, nil
+	}
+
+	return time.Time{}, resp.StatusCode, errors.New("rdap response has no expiration event")
+}
+
+func (c *DomainChecker) lookupWHOIS(ctx context.Context, server, domain string) (time.Time, error) {
+	addr := server
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		addr = net.JoinHostPort(server, strconv.Itoa(config.DefaultWHOISPort))
+	}
+
+	conn, err := c.dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer func() { _ = conn.Close() }()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	if _, err := io.WriteString(conn, domain+"\r\n"); err != nil {
+		return time.Time{}, fmt.Errorf("write whois query: %v", err)
+	}
+
+	return parseWHOISExpiry(io.LimitReader(conn, domainMaxResponseBody))
+}
+
+// parseWHOISExpiry scans a WHOIS response for the first recognised expiry field.
+func parseWHOISExpiry(r io.Reader) (time.Time, error) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+		if value == "" || !isWHOISExpiryKey(key) {
+			continue
+		}
+
+		if expiry, ok := parseWHOISDate(value); ok {
+			return expiry, nil
+		}
+		return time.Time{}, fmt.Errorf("unrecognised whois expiry date %q", value)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return time.Time{}, fmt.Errorf("read whois response: %v", err)
+	}
+	return time.Time{}, errors.New("whois response has no expiry date")
+}
+
+func isWHOISExpiryKey(key string) bool {
+	for _, k := range whoisExpiryKeys {
+		if key == k {
+			return true
+		}
+	}
+	return false
+}
+
+func parseWHOISDate(value string) (time.Time, bool) {
+	for _, layout := range whoisDateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
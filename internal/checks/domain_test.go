@@ -0,0 +1,290 @@
+package checks
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"uptiq/internal/config"
+)
+
+func newRDAPServer(t *testing.T, expiry string) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/domain/example.com" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/rdap+json")
+		_, _ = fmt.Fprintf(w, `{"ldhName":"example.com","events":[{"eventAction":"registration","eventDate":"1995-08-14T04:00:00Z"},{"eventAction":"expiration","eventDate":%q}]}`, expiry)
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func newWHOISServer(t *testing.T, response string) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to start test server: %v", err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			_, _ = bufio.NewReader(conn).ReadString('\n')
+			_, _ = conn.Write([]byte(response))
+			_ = conn.Close()
+		}
+	}()
+
+	return listener.Addr().String()
+}
+
+func domainService(lookup, server string) config.Service {
+	return config.Service{
+		Type:           "domain",
+		Domain:         "example.com",
+		Lookup:         lookup,
+		LookupServer:   server,
+		WarnWithinDays: intPtr(30),
+		FailWithinDays: intPtr(7),
+	}
+}
+
+func intPtr(n int) *int {
+	return &n
+}
+
+func TestDomainChecker_RDAP(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		expiry      string
+		wantSuccess bool
+		wantWarning bool
+		errContain  string
+	}{
+		{name: "far from expiry", expiry: "2027-01-01T00:00:00Z", wantSuccess: true},
+		{name: "within warning window", expiry: "2026-01-20T00:00:00Z", wantSuccess: true, wantWarning: true},
+		{name: "within failure window", expiry: "2026-01-05T00:00:00Z", wantSuccess: false, errContain: "expires in 4 days"},
+		{name: "already expired", expiry: "2025-12-01T00:00:00Z", wantSuccess: false, errContain: "expired on 2025-12-01"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			server := newRDAPServer(t, tc.expiry)
+
+			checker := NewDomainChecker()
+			checker.now = func() time.Time { return now }
+
+			result := checker.Check(context.Background(), domainService("rdap", server.URL))
+
+			if result.Success != tc.wantSuccess {
+				t.Errorf("Success = %v, want %v (error: %s)", result.Success, tc.wantSuccess, result.Error)
+			}
+			if (result.Warning != "") != tc.wantWarning {
+				t.Errorf("Warning = %q, want warning: %v", result.Warning, tc.wantWarning)
+			}
+			if tc.errContain != "" && !strings.Contains(result.Error, tc.errContain) {
+				t.Errorf("Error = %q, should contain %q", result.Error, tc.errContain)
+			}
+			if result.StatusCode != http.StatusOK {
+				t.Errorf("StatusCode = %d, want 200", result.StatusCode)
+			}
+			if result.Expiry.IsZero() {
+				t.Error("expected expiry to be set")
+			}
+		})
+	}
+}
+
+func TestDomainChecker_ZeroWindows(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		expiry      string
+		wantSuccess bool
+	}{
+		{name: "expiring tomorrow", expiry: "2026-01-02T12:00:00Z", wantSuccess: true},
+		{name: "already expired", expiry: "2025-12-31T00:00:00Z", wantSuccess: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			server := newRDAPServer(t, tc.expiry)
+
+			svc := domainService("rdap", server.URL)
+			svc.WarnWithinDays = intPtr(0)
+			svc.FailWithinDays = intPtr(0)
+
+			checker := NewDomainChecker()
+			checker.now = func() time.Time { return now }
+			result := checker.Check(context.Background(), svc)
+
+			if result.Success != tc.wantSuccess {
+				t.Errorf("Success = %v, want %v (error: %s)", result.Success, tc.wantSuccess, result.Error)
+			}
+			if result.Warning != "" {
+				t.Errorf("Warning = %q, want none with warn_within_days 0", result.Warning)
+			}
+		})
+	}
+}
+
+func TestDomainChecker_RDAPNotFound(t *testing.T) {
+	server := newRDAPServer(t, "2027-01-01T00:00:00Z")
+
+	svc := domainService("rdap", server.URL)
+	svc.Domain = "unknown.example"
+
+	result := NewDomainChecker().Check(context.Background(), svc)
+
+	if result.Success {
+		t.Error("expected failure for unknown domain")
+	}
+	if result.StatusCode != http.StatusNotFound {
+		t.Errorf("StatusCode = %d, want 404", result.StatusCode)
+	}
+}
+
+func TestDomainChecker_RDAPMissingExpiration(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"events":[]}`))
+	}))
+	defer server.Close()
+
+	result := NewDomainChecker().Check(context.Background(), domainService("rdap", server.URL))
+
+	if result.Success {
+		t.Error("expected failure when expiration event is missing")
+	}
+	if !strings.Contains(result.Error, "no expiration event") {
+		t.Errorf("unexpected error: %s", result.Error)
+	}
+}
+
+func TestDomainChecker_WHOIS(t *testing.T) {
+	addr := newWHOISServer(t, "Domain Name: EXAMPLE.COM\r\nRegistry Expiry Date: 2027-08-13T04:00:00Z\r\n")
+
+	checker := NewDomainChecker()
+	checker.now = func() time.Time { return time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC) }
+
+	result := checker.Check(context.Background(), domainService("whois", addr))
+
+	if !result.Success {
+		t.Fatalf("expected success, got failure: %s", result.Error)
+	}
+	want := time.Date(2027, 8, 13, 4, 0, 0, 0, time.UTC)
+	if !result.Expiry.Equal(want) {
+		t.Errorf("Expiry = %v, want %v", result.Expiry, want)
+	}
+	if result.StatusCode != 0 {
+		t.Errorf("WHOIS check should have status 0, got %d", result.StatusCode)
+	}
+}
+
+func TestDomainChecker_WHOISUnreachable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to get free port: %v", err)
+	}
+	addr := listener.Addr().String()
+	_ = listener.Close()
+
+	result := NewDomainChecker().Check(context.Background(), domainService("whois", addr))
+
+	if result.Success {
+		t.Error("expected failure for unreachable whois server")
+	}
+	if result.Error == "" {
+		t.Error("expected error message")
+	}
+}
+
+func TestParseWHOISExpiry(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		want     time.Time
+		wantErr  bool
+	}{
+		{
+			name:     "registry expiry date",
+			response: "Registry Expiry Date: 2027-08-13T04:00:00Z\n",
+			want:     time.Date(2027, 8, 13, 4, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "paid-till",
+			response: "domain: EXAMPLE.RU\npaid-till: 2026-03-01T21:00:00Z\n",
+			want:     time.Date(2026, 3, 1, 21, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "date only",
+			response: "Expiry date: 2026-05-17\n",
+			want:     time.Date(2026, 5, 17, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "day-month-year",
+			response: "Expiration Date: 17-May-2026\n",
+			want:     time.Date(2026, 5, 17, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "no expiry field",
+			response: "Domain Name: EXAMPLE.COM\n",
+			wantErr:  true,
+		},
+		{
+			name:     "unparseable date",
+			response: "Registry Expiry Date: soon\n",
+			wantErr:  true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parseWHOISExpiry(strings.NewReader(tc.response))
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("expected error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !got.Equal(tc.want) {
+				t.Errorf("parseWHOISExpiry() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestNewDomainChecker(t *testing.T) {
+	checker := NewDomainChecker()
+
+	if checker == nil {
+		t.Fatal("NewDomainChecker returned nil")
+	}
+	if checker.client == nil {
+		t.Error("client is nil")
+	}
+	if checker.now == nil {
+		t.Error("now is nil")
+	}
+}
@@ -0,0 +1,158 @@
+package checks
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+	"uptiq/internal/config"
+)
+
+// NTP checker configuration constants.
+const (
+	ntpDialTimeout      = 5 * time.Second
+	ntpPacketSize       = 48
+	ntpEpochOffset      = 2208988800 // Seconds between 1900-01-01 and 1970-01-01
+	ntpVersion          = 4
+	ntpModeClient       = 3
+	ntpModeServer       = 4
+	ntpLeapAlarm        = 3
+	ntpStratumKiss      = 0
+	ntpStratumUnsynced  = 16
+	ntpDefaultMaxOffset = time.Second
+)
+
+// NTPChecker queries an NTP server with SNTP and checks its clock offset.
+type NTPChecker struct {
+	dialer net.Dialer
+	now    func() time.Time
+}
+
+// NewNTPChecker creates a new NTPChecker instance.
+func NewNTPChecker() *NTPChecker {
+	return &NTPChecker{
+		dialer: net.Dialer{Timeout: ntpDialTimeout},
+		now:    time.Now,
+	}
+}
+
+func (c *NTPChecker) Check(ctx context.Context, svc config.Service) Result {
+	start := time.Now()
+
+	reply, err := c.query(ctx, net.JoinHostPort(svc.Host, strconv.Itoa(svc.Port)))
+	if err != nil {
+		return Result{
+			Success: false,
+			Latency: time.Since(start),
+			Error:   err.Error(),
+		}
+	}
+
+	result := Result{
+		Success:     true,
+		Latency:     time.Since(start),
+		ClockOffset: reply.offset,
+		Stratum:     reply.stratum,
+	}
+
+	if err := c.validateReply(reply, svc.MaxOffset); err != nil {
+		result.Success = false
+		result.Error = err.Error()
+	}
+
+	return result
+}
+
+// ntpReply holds the fields of an SNTP response relevant to health checking.
+type ntpReply struct {
+	leap    int
+	stratum int
+	offset  time.Duration
+}
+
+func (c *NTPChecker) query(ctx context.Context, addr string) (ntpReply, error) {
+	conn, err := c.dialer.DialContext(ctx, "udp", addr)
+	if err != nil {
+		return ntpReply{}, err
+	}
+	defer func() { _ = conn.Close() }()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	req := make([]byte, ntpPacketSize)
+	req[0] = ntpVersion<<3 | ntpModeClient
+
+	sent := c.now()
+	binary.BigEndian.PutUint64(req[40:], toNTPTime(sent))
+
+	if _, err := conn.Write(req); err != nil {
+		return ntpReply{}, fmt.Errorf("send ntp request: %v", err)
+	}
+
+	resp := make([]byte, ntpPacketSize)
+	n, err := conn.Read(resp)
+	if err != nil {
+		return ntpReply{}, fmt.Errorf("read ntp response: %v", err)
+	}
+	received := c.now()
+
+	if n < ntpPacketSize {
+		return ntpReply{}, fmt.Errorf("short ntp response (%d bytes)", n)
+	}
+	if mode := resp[0] & 0x07; mode != ntpModeServer {
+		return ntpReply{}, fmt.Errorf("unexpected ntp mode %d", mode)
+	}
+	if origin := binary.BigEndian.Uint64(resp[24:]); origin != binary.BigEndian.Uint64(req[40:]) {
+		return ntpReply{}, errors.New("ntp response does not match request")
+	}
+
+	serverReceive := fromNTPTime(binary.BigEndian.Uint64(resp[32:]))
+	serverTransmit := fromNTPTime(binary.BigEndian.Uint64(resp[40:]))
+
+	return ntpReply{
+		leap:    int(resp[0] >> 6),
+		stratum: int(resp[1]),
+		offset:  (serverReceive.Sub(sent) + serverTransmit.Sub(received)) / 2,
+	}, nil
+}
+
+func (c *NTPChecker) validateReply(reply ntpReply, maxOffset string) error {
+	switch {
+	case reply.stratum == ntpStratumKiss:
+		return errors.New("ntp server sent kiss-of-death (stratum 0)")
+	case reply.stratum >= ntpStratumUnsynced:
+		return fmt.Errorf("ntp server is unsynchronized (stratum %d)", reply.stratum)
+	case reply.leap == ntpLeapAlarm:
+		return errors.New("ntp server clock is unsynchronized (leap indicator alarm)")
+	}
+
+	limit, err := time.ParseDuration(maxOffset)
+	if err != nil || limit <= 0 {
+		limit = ntpDefaultMaxOffset
+	}
+
+	if reply.offset.Abs() > limit {
+		return fmt.Errorf("clock offset %v exceeds %v", reply.offset, limit)
+	}
+
+	return nil
+}
+
+// toNTPTime converts a time to the 64-bit NTP timestamp format.
+func toNTPTime(t time.Time) uint64 {
+	secs := uint64(t.Unix() + ntpEpochOffset)
+	frac := (uint64(t.Nanosecond()) << 32) / uint64(time.Second)
+	return secs<<32 | frac
+}
+
+// fromNTPTime converts a 64-bit NTP timestamp to a time.
+func fromNTPTime(v uint64) time.Time {
+	secs := int64(v>>32) - ntpEpochOffset
+	nanos := (int64(v&0xffffffff) * int64(time.Second)) >> 32
+	return time.Unix(secs, nanos)
+}
@@ -0,0 +1,178 @@
+package checks
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"uptiq/internal/config"
+)
+
+// newNTPServer starts a local SNTP stand-in whose clock is skewed by offset.
+func newNTPServer(t *testing.T, offset time.Duration, stratum byte, leap byte) *net.UDPAddr {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to start test server: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	go func() {
+		buf := make([]byte, ntpPacketSize)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if n < ntpPacketSize {
+				continue
+			}
+
+			resp := make([]byte, ntpPacketSize)
+			resp[0] = leap<<6 | ntpVersion<<3 | ntpModeServer
+			resp[1] = stratum
+			copy(resp[24:32], buf[40:48])
+
+			now := toNTPTime(time.Now().Add(offset))
+			binary.BigEndian.PutUint64(resp[32:], now)
+			binary.BigEndian.PutUint64(resp[40:], now)
+
+			_, _ = conn.WriteTo(resp, addr)
+		}
+	}()
+
+	return conn.LocalAddr().(*net.UDPAddr)
+}
+
+func ntpService(addr *net.UDPAddr, maxOffset string) config.Service {
+	return config.Service{
+		Type:      "ntp",
+		Host:      addr.IP.String(),
+		Port:      addr.Port,
+		MaxOffset: maxOffset,
+	}
+}
+
+func TestNTPChecker_Success(t *testing.T) {
+	addr := newNTPServer(t, 0, 2, 0)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	result := NewNTPChecker().Check(ctx, ntpService(addr, "1s"))
+
+	if !result.Success {
+		t.Fatalf("expected success, got failure: %s", result.Error)
+	}
+	if result.Stratum != 2 {
+		t.Errorf("Stratum = %d, want 2", result.Stratum)
+	}
+	if result.ClockOffset.Abs() > 100*time.Millisecond {
+		t.Errorf("ClockOffset = %v, want close to 0", result.ClockOffset)
+	}
+	if result.StatusCode != 0 {
+		t.Errorf("NTP check should have status 0, got %d", result.StatusCode)
+	}
+}
+
+func TestNTPChecker_OffsetExceeded(t *testing.T) {
+	addr := newNTPServer(t, 3*time.Second, 2, 0)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	result := NewNTPChecker().Check(ctx, ntpService(addr, "1s"))
+
+	if result.Success {
+		t.Error("expected failure when offset exceeds max_offset")
+	}
+	if !strings.Contains(result.Error, "exceeds") {
+		t.Errorf("unexpected error: %s", result.Error)
+	}
+	if result.ClockOffset < 2900*time.Millisecond || result.ClockOffset > 3100*time.Millisecond {
+		t.Errorf("ClockOffset = %v, want ~3s", result.ClockOffset)
+	}
+	if result.Stratum != 2 {
+		t.Errorf("Stratum = %d, want 2 (offset should be reported on failure)", result.Stratum)
+	}
+}
+
+func TestNTPChecker_Unsynchronized(t *testing.T) {
+	tests := []struct {
+		name       string
+		stratum    byte
+		leap       byte
+		errContain string
+	}{
+		{name: "stratum 16", stratum: 16, errContain: "stratum 16"},
+		{name: "kiss of death", stratum: 0, errContain: "kiss-of-death"},
+		{name: "leap alarm", stratum: 3, leap: 3, errContain: "leap indicator"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			addr := newNTPServer(t, 0, tc.stratum, tc.leap)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+
+			result := NewNTPChecker().Check(ctx, ntpService(addr, "1s"))
+
+			if result.Success {
+				t.Error("expected failure")
+			}
+			if !strings.Contains(result.Error, tc.errContain) {
+				t.Errorf("Error = %q, should contain %q", result.Error, tc.errContain)
+			}
+		})
+	}
+}
+
+func TestNTPChecker_Unreachable(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to get free port: %v", err)
+	}
+	addr := conn.LocalAddr().(*net.UDPAddr)
+	_ = conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+
+	result := NewNTPChecker().Check(ctx, ntpService(addr, "1s"))
+
+	if result.Success {
+		t.Error("expected failure for unreachable server")
+	}
+	if result.Error == "" {
+		t.Error("expected error message")
+	}
+	if result.Stratum != 0 {
+		t.Errorf("Stratum = %d, want 0 when no reply was received", result.Stratum)
+	}
+}
+
+func TestNTPTimeConversion(t *testing.T) {
+	want := time.Date(2026, 10, 18, 12, 30, 45, 500_000_000, time.UTC)
+
+	got := fromNTPTime(toNTPTime(want))
+
+	if diff := got.Sub(want).Abs(); diff > time.Microsecond {
+		t.Errorf("round trip = %v, want %v (diff %v)", got, want, diff)
+	}
+}
+
+func TestNewNTPChecker(t *testing.T) {
+	checker := NewNTPChecker()
+
+	if checker == nil {
+		t.Fatal("NewNTPChecker returned nil")
+	}
+	if checker.now == nil {
+		t.Error("now is nil")
+	}
+}
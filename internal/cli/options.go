@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+
+	"uptiq/internal/config"
 )
 
 // Options holds CLI flags.
@@ -18,27 +20,52 @@ type Options struct {
 func DefaultOptions() Options {
 	return Options{
 		ConfigPath: "configs/example.yaml",
-		LogLevel:   "info",
 	}
 }
 
-// NewLogger creates a structured logger from a log level string.
-func NewLogger(level string) (*slog.Logger, error) {
-	var lvl slog.Level
+// bindAddr returns the server address, preferring the --listen override.
+func (o Options) bindAddr(cfg *config.Config) string {
+	if o.Listen != "" {
+		return o.Listen
+	}
+	return cfg.Global.ScrapeBind
+}
+
+// logLevel returns the log level, preferring the --log-level override.
+func (o Options) logLevel(cfg *config.Config) string {
+	if o.LogLevel != "" {
+		return o.LogLevel
+	}
+	return cfg.Global.LogLevel
+}
 
+// ParseLogLevel converts a log level string to a slog.Level.
+func ParseLogLevel(level string) (slog.Level, error) {
 	switch level {
 	case "debug":
-		lvl = slog.LevelDebug
+		return slog.LevelDebug, nil
 	case "info":
-		lvl = slog.LevelInfo
+		return slog.LevelInfo, nil
 	case "warn":
-		lvl = slog.LevelWarn
+		return slog.LevelWarn, nil
 	case "error":
-		lvl = slog.LevelError
+		return slog.LevelError, nil
 	default:
-		return nil, fmt.Errorf("invalid log level: %q (use debug|info|warn|error)", level)
+		return 0, fmt.Errorf("invalid log level: %q (use debug|info|warn|error)", level)
+	}
+}
+
+// NewLogger creates a structured logger from a log level string.
+// The returned LevelVar changes the logger's level at runtime.
+func NewLogger(level string) (*slog.Logger, *slog.LevelVar, error) {
+	lvl, err := ParseLogLevel(level)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	handler := slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: lvl})
-	return slog.New(handler), nil
+	levelVar := new(slog.LevelVar)
+	levelVar.Set(lvl)
+
+	handler := slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: levelVar})
+	return slog.New(handler), levelVar, nil
 }
@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"uptiq/internal/alerting"
+	"uptiq/internal/config"
+)
+
+// outboxFile is the alert outbox inside global.data_dir.
+const outboxFile = "outbox.jsonl"
+
+// outboxPath returns where the outbox lives for cfg, or "" if data_dir is unset.
+func outboxPath(cfg *config.Config) string {
+	if cfg.Global.DataDir == "" {
+		return ""
+	}
+	return filepath.Join(cfg.Global.DataDir, outboxFile)
+}
+
+// newOutboxCommand returns the commands to inspect and purge the alert outbox.
+func newOutboxCommand(opts *Options) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "outbox",
+		Short: "Inspect or purge alerts waiting in the outbox",
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "List alerts not yet delivered",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := loadOutboxPath(opts)
+			if err != nil {
+				return err
+			}
+			entries, err := alerting.ReadOutbox(path)
+			if err != nil {
+				return err
+			}
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "KEY\tQUEUED\tCHANNEL\tSERVICE\tKIND")
+			for _, e := range entries {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", e.Key, e.QueuedAt.Format(time.RFC3339), e.Channel, e.ServiceID, e.Payload.Kind)
+			}
+			return w.Flush()
+		},
+	})
+
+	var all, force bool
+	purge := &cobra.Command{
+		Use:   "purge [key...]",
+		Short: "Remove alerts from the outbox without sending them",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 && !all {
+				return errors.New("give the keys to purge, or --all")
+			}
+			if len(args) > 0 && all {
+				return errors.New("give either keys or --all, not both")
+			}
+
+			path, err := loadOutboxPath(opts)
+			if err != nil {
+				return err
+			}
+			removed, err := alerting.PurgeOutbox(path, args, force)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "purged %d alert(s)\n", removed)
+			return nil
+		},
+	}
+	purge.Flags().BoolVar(&all, "all", false, "Purge every alert in the outbox")
+	purge.Flags().BoolVar(&force, "force", false, "Purge even if the outbox looks in use (e.g. after a crash)")
+	cmd.AddCommand(purge)
+
+	return cmd
+}
+
+func loadOutboxPath(opts *Options) (string, error) {
+	cfg, err := config.Load(opts.ConfigPath)
+	if err != nil {
+		return "", err
+	}
+
+	path := outboxPath(cfg)
+	if path == "" {
+		return "", errors.New("global.data_dir is not set; there is no outbox")
+	}
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("no outbox at %s", path)
+	}
+	return path, nil
+}
@@ -8,14 +8,19 @@ import (
 	"math/rand"
 	"os"
 	"os/signal"
+	"reflect"
 	"syscall"
 	"time"
 
 	"github.com/spf13/cobra"
 
 	"uptiq/internal/alerting"
+	"uptiq/internal/cluster"
 	"uptiq/internal/config"
+	"uptiq/internal/ha"
+	"uptiq/internal/maintenance"
 	"uptiq/internal/metrics"
+	"uptiq/internal/probe"
 	"uptiq/internal/scheduler"
 	"uptiq/internal/server"
 )
@@ -35,9 +40,11 @@ func Execute() {
 	flags := cmd.PersistentFlags()
 	flags.StringVarP(&opts.ConfigPath, "config", "c", opts.ConfigPath, "Path to YAML config file")
 	flags.StringVarP(&opts.Listen, "listen", "l", "", "Override bind address for /healthz and /metrics (e.g. 0.0.0.0:8080)")
-	flags.StringVar(&opts.LogLevel, "log-level", opts.LogLevel, "Log level: debug|info|warn|error")
+	flags.StringVar(&opts.LogLevel, "log-level", "", "Override global.log_level: debug|info|warn|error")
 	flags.BoolVar(&opts.Watch, "watch", false, "Watch config file and reload on changes")
 
+	cmd.AddCommand(newOutboxCommand(&opts))
+
 	if err := cmd.Execute(); err != nil {
 		os.Exit(1)
 	}
@@ -46,56 +53,106 @@ func Execute() {
 func run(opts Options) error {
 	rand.New(rand.NewSource(time.Now().UnixNano()))
 
-	log, err := NewLogger(opts.LogLevel)
+	cfg, err := config.Load(opts.ConfigPath)
 	if err != nil {
 		return err
 	}
 
-	cfg, err := config.Load(opts.ConfigPath)
+	log, level, err := NewLogger(opts.logLevel(cfg))
 	if err != nil {
 		return err
 	}
 
 	app := &application{
-		log:  log,
-		opts: opts,
-		cfg:  cfg,
+		log:   log,
+		level: level,
+		opts:  opts,
+		cfg:   cfg,
 	}
 
 	return app.run()
 }
 
 type application struct {
-	log  *slog.Logger
-	opts Options
-	cfg  *config.Config
+	log   *slog.Logger
+	level *slog.LevelVar
+	opts  Options
+	cfg   *config.Config
 
 	metrics     *metrics.Bundle
 	alertEngine *alerting.Engine
+	maintenance *maintenance.Manager
+	outbox      *alerting.Outbox // Set when global.data_dir is configured
+	statePath   string           // Set when global.data_dir is configured
 	server      *server.Server
 	scheduler   *scheduler.Scheduler
 	watcher     *ConfigWatcher
+
+	// Set depending on probing.mode
+	aggregator *probe.Aggregator
+	reporter   *probe.Reporter
+
+	// Set when cluster.peers is configured
+	membership *cluster.Membership
+
+	// Set when ha.peer_url is configured
+	elector *ha.Elector
 }
 
 func (a *application) run() error {
 	// Initialize components
 	a.metrics = metrics.NewBundle()
-	a.metrics.Collector.EnsureServices(a.cfg.Services)
 	a.metrics.Collector.ConfigReloadSuccess.Set(1)
 
-	a.alertEngine = alerting.NewEngine(a.cfg.Alerting, a.log)
+	a.alertEngine = alerting.NewEngine(a.cfg.Alerting, a.log, a.metrics.Collector)
+	if err := a.setupState(); err != nil {
+		return err
+	}
+	a.alertEngine.SetServices(a.cfg.Services)
+	if a.cfg.HA.Enabled() {
+		// Stand by until elected, so the outbox is not sent on both peers
+		a.alertEngine.SetStandby(true)
+	}
 
-	bind := a.cfg.Global.ScrapeBind
-	if a.opts.Listen != "" {
-		bind = a.opts.Listen
+	if err := a.setupOutbox(); err != nil {
+		return err
+	}
+	defer a.closeOutbox()
+
+	mm, err := maintenance.NewManager(a.cfg.Maintenance)
+	if err != nil {
+		return err
 	}
+	a.maintenance = mm
+	a.alertEngine.SetMaintenance(a.maintenance)
+
+	bind := a.opts.bindAddr(a.cfg)
 	a.server = server.New(bind, a.log, a.metrics.Registry)
+	a.server.SetAPIToken(a.cfg.Global.APIToken)
+	a.server.HandleDependencies(a.alertEngine)
+	a.server.HandleMaintenance(a.maintenance)
 
-	sched, err := scheduler.New(a.cfg, a.log, a.metrics.Collector, a.alertEngine)
+	handler, err := a.setupProbing()
+	if err != nil {
+		return err
+	}
+
+	if err := a.setupCluster(); err != nil {
+		return err
+	}
+	if err := a.setupHA(); err != nil {
+		return err
+	}
+	owned := a.ownedServices(a.cfg.Services)
+	a.metrics.Collector.EnsureServices(owned)
+
+	sched, err := scheduler.New(a.cfg, a.log, a.metrics.Collector, handler)
 	if err != nil {
 		return err
 	}
 	a.scheduler = sched
+	a.server.HandleSchedule(a.scheduler)
+	a.server.HandleServices(a.scheduler)
 
 	// Setup context and signals
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
@@ -131,7 +188,18 @@ func (a *application) run() error {
 	}
 
 	// Start components
-	errCh := make(chan error, 2)
+	if a.membership != nil {
+		go a.membership.Run(ctx)
+	}
+	if a.elector != nil {
+		go a.elector.Run(ctx)
+	}
+	if a.statePath != "" {
+		go a.persistState(ctx)
+	}
+
+	errCh := make(chan error, 1)
+	schedDone := make(chan error, 1)
 
 	go func() {
 		a.log.Info("starting server", "addr", bind)
@@ -147,22 +215,168 @@ func (a *application) run() error {
 			"workers", a.cfg.Global.WorkerCount,
 			"jitter", a.cfg.Global.Jitter,
 		)
-		if err := a.scheduler.Start(ctx, a.cfg.Services); err != nil {
-			errCh <- fmt.Errorf("scheduler failed: %w", err)
+		if err := a.scheduler.Start(ctx, owned); err != nil {
+			schedDone <- fmt.Errorf("scheduler failed: %w", err)
 			return
 		}
-		errCh <- nil
+		schedDone <- nil
 	}()
 
 	// Main event loop
-	return a.eventLoop(ctx, hupCh, reloadCh, errCh)
+	return a.eventLoop(ctx, stop, hupCh, reloadCh, errCh, schedDone)
+}
+
+// setupProbing wires multi-location probing for probing.mode and returns
+// the handler check results go to: the alerting engine when standalone,
+// the quorum aggregator in front of it on a primary, or the reporter on a
+// probe.
+func (a *application) setupProbing() (scheduler.ResultHandler, error) {
+	probing := a.cfg.Probing
+
+	switch config.ProbeMode(probing.Mode) {
+	case config.ProbeModePrimary:
+		agg, err := probe.NewAggregator(probing, a.alertEngine, a.metrics.Collector, a.log)
+		if err != nil {
+			return nil, err
+		}
+		agg.SetServices(a.cfg.Services)
+		a.aggregator = agg
+
+		a.server.SetProbeToken(probing.Token)
+		a.server.HandleProbeReports(agg)
+		a.log.Info("probing as primary", "location", probing.Location, "quorum", probing.Quorum, "stale_after", probing.StaleAfter)
+		return agg, nil
+
+	case config.ProbeModeProbe:
+		a.reporter = probe.NewReporter(probing, a.log)
+		a.log.Info("probing as probe", "location", probing.Location, "primary", probing.Primary)
+		return a.reporter, nil
+
+	default:
+		return a.alertEngine, nil
+	}
+}
+
+// setupOutbox persists alerts under global.data_dir until delivered, and
+// sends the ones left over from the previous run.
+func (a *application) setupOutbox() error {
+	path := outboxPath(a.cfg)
+	if path == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(a.cfg.Global.DataDir, 0o755); err != nil {
+		return fmt.Errorf("create data_dir: %w", err)
+	}
+	outbox, err := alerting.OpenOutbox(path)
+	if err != nil {
+		return err
+	}
+	a.outbox = outbox
+	a.alertEngine.SetOutbox(outbox)
+	a.log.Info("alert outbox enabled", "path", path, "pending", outbox.Len())
+	return nil
+}
+
+func (a *application) closeOutbox() {
+	if a.outbox == nil {
+		return
+	}
+	if err := a.outbox.Close(); err != nil {
+		a.log.Warn("outbox close error", "error", err.Error())
+	}
+}
+
+// setupCluster joins the cluster when cluster.peers is configured.
+func (a *application) setupCluster() error {
+	if !a.cfg.Cluster.Enabled() {
+		return nil
+	}
+
+	membership, err := cluster.New(a.cfg.Cluster, a.log, a.metrics.Collector)
+	if err != nil {
+		return err
+	}
+	a.membership = membership
+
+	a.server.SetClusterToken(a.cfg.Cluster.Token)
+	a.server.HandleClusterHeartbeat(membership)
+	a.log.Info("cluster mode enabled", "self", a.cfg.Cluster.Self, "peers", len(a.cfg.Cluster.Peers))
+	return nil
+}
+
+// setupHA pairs this instance with ha.peer_url when configured. Alerts are
+// held back until this instance holds the lease.
+func (a *application) setupHA() error {
+	if !a.cfg.HA.Enabled() {
+		return nil
+	}
+
+	elector, err := ha.New(a.cfg.HA, a.alertEngine, a.log, a.metrics.Collector)
+	if err != nil {
+		return err
+	}
+	a.elector = elector
+	a.alertEngine.OnAlert(elector.Sync)
+
+	a.server.SetHAToken(a.cfg.HA.Token)
+	a.server.HandleHALease(elector)
+	a.log.Info("ha mode enabled; standing by until the peer's lease expires",
+		"name", a.cfg.HA.Name,
+		"peer", a.cfg.HA.PeerURL,
+		"lease_duration", a.cfg.HA.LeaseDuration,
+	)
+	return nil
 }
 
-func (a *application) eventLoop(ctx context.Context, hupCh <-chan os.Signal, reloadCh <-chan struct{}, errCh <-chan error) error {
+// ownedServices returns the services this instance should check: all of
+// them, or in cluster mode the ones the hash ring assigns to it.
+func (a *application) ownedServices(services []config.Service) []config.Service {
+	if a.membership == nil {
+		return services
+	}
+
+	owned := a.membership.Owned(services)
+	a.metrics.Collector.OwnedServices.Set(float64(len(owned)))
+	return owned
+}
+
+// rebalance reschedules after cluster membership changed. Metrics of
+// services that moved to another member are dropped, so each member's
+// /metrics only reports what it checks.
+func (a *application) rebalance() {
+	owned := a.ownedServices(a.cfg.Services)
+	a.metrics.Collector.RetainServices(owned)
+	a.metrics.Collector.EnsureServices(owned)
+	a.scheduler.UpdateServices(owned)
+	a.log.Info("cluster ownership updated", "owned", len(owned), "services", len(a.cfg.Services))
+}
+
+func (a *application) eventLoop(ctx context.Context, stopSignals context.CancelFunc, hupCh <-chan os.Signal, reloadCh <-chan struct{}, errCh, schedDone <-chan error) error {
+	// Nil when not clustered, so its case never fires
+	var membershipCh <-chan struct{}
+	if a.membership != nil {
+		membershipCh = a.membership.Changes()
+	}
+
 	for {
 		select {
 		case <-ctx.Done():
-			return a.shutdown()
+			// A second signal now terminates immediately
+			stopSignals()
+			a.logShutdown()
+			return a.shutdown(<-schedDone)
+
+		case err := <-schedDone:
+			if ctx.Err() != nil {
+				stopSignals()
+				a.logShutdown()
+				return a.shutdown(err)
+			}
+			if err == nil {
+				err = errors.New("scheduler stopped unexpectedly")
+			}
+			return err
 
 		case sig := <-hupCh:
 			a.log.Info("received signal", "signal", sig.String(), "action", "reload")
@@ -172,6 +386,9 @@ func (a *application) eventLoop(ctx context.Context, hupCh <-chan os.Signal, rel
 			a.log.Info("config file change detected", "action", "reload")
 			a.applyReload()
 
+		case <-membershipCh:
+			a.rebalance()
+
 		case err := <-errCh:
 			if err != nil {
 				return err
@@ -181,32 +398,142 @@ func (a *application) eventLoop(ctx context.Context, hupCh <-chan os.Signal, rel
 	}
 }
 
+// applyReload loads the config file and applies it. Everything that can fail
+// is prepared before anything is changed, so a reload applies completely or
+// not at all.
 func (a *application) applyReload() {
 	newCfg, err := config.Load(a.opts.ConfigPath)
 	if err != nil {
-		a.metrics.Collector.ConfigReloadSuccess.Set(0)
-		a.log.Warn("config reload failed; keeping current config",
-			"path", a.opts.ConfigPath,
-			"error", err.Error(),
-		)
+		a.reloadFailed(err)
 		return
 	}
 
-	a.metrics.Collector.ConfigReloadSuccess.Set(1)
-	a.metrics.Collector.EnsureServices(newCfg.Services)
-	a.scheduler.UpdateServices(newCfg.Services)
+	windows, err := maintenance.Compile(newCfg.Maintenance)
+	if err != nil {
+		a.reloadFailed(err)
+		return
+	}
+
+	settings, err := scheduler.SettingsFromConfig(newCfg)
+	if err != nil {
+		a.reloadFailed(err)
+		return
+	}
+
+	level, err := ParseLogLevel(a.opts.logLevel(newCfg))
+	if err != nil {
+		a.reloadFailed(err)
+		return
+	}
+
+	if newCfg.Probing.Mode != a.cfg.Probing.Mode || newCfg.Probing.Location != a.cfg.Probing.Location {
+		a.reloadFailed(errors.New("probing.mode and probing.location cannot change without a restart"))
+		return
+	}
+	if !reflect.DeepEqual(newCfg.Cluster, a.cfg.Cluster) {
+		a.reloadFailed(errors.New("cluster settings cannot change without a restart"))
+		return
+	}
+	if newCfg.Global.DataDir != a.cfg.Global.DataDir {
+		a.reloadFailed(errors.New("global.data_dir cannot change without a restart"))
+		return
+	}
+	if newCfg.HA != a.cfg.HA {
+		a.reloadFailed(errors.New("ha settings cannot change without a restart"))
+		return
+	}
+	quorum, err := probe.SettingsFromConfig(newCfg.Probing)
+	if err != nil {
+		a.reloadFailed(err)
+		return
+	}
 
-	// Note: global settings changes (worker_count/jitter/bind) require restart
+	// Last fallible step: on error the server keeps its current listener
+	if err := a.server.Rebind(a.opts.bindAddr(newCfg)); err != nil {
+		a.reloadFailed(fmt.Errorf("rebind server: %w", err))
+		return
+	}
+
+	a.level.Set(level)
+	a.maintenance.SetWindows(windows)
+	a.alertEngine.UpdateConfig(newCfg.Alerting)
+	a.alertEngine.SetServices(newCfg.Services)
+	a.server.SetAPIToken(newCfg.Global.APIToken)
+	a.server.SetProbeToken(newCfg.Probing.Token)
+	if a.aggregator != nil {
+		a.aggregator.UpdateSettings(quorum)
+		a.aggregator.SetServices(newCfg.Services)
+	}
+	if a.reporter != nil {
+		a.reporter.UpdateConfig(newCfg.Probing)
+	}
+	owned := a.ownedServices(newCfg.Services)
+	a.metrics.Collector.EnsureServices(owned)
+	if a.membership != nil {
+		a.metrics.Collector.RetainServices(owned)
+	}
+	a.scheduler.UpdateSettings(settings)
+	a.scheduler.UpdateServices(owned)
+	a.cfg = newCfg
+
+	a.metrics.Collector.ConfigReloadSuccess.Set(1)
 	a.log.Info("config reload applied",
 		"path", a.opts.ConfigPath,
 		"services", len(newCfg.Services),
+		"workers", settings.WorkerCount,
+		"jitter", settings.Jitter,
+		"log_level", level.String(),
+		"addr", a.server.Addr(),
 	)
 }
 
-func (a *application) shutdown() error {
-	a.log.Info("shutdown requested")
+func (a *application) reloadFailed(err error) {
+	a.metrics.Collector.ConfigReloadSuccess.Set(0)
+	a.log.Warn("config reload failed; keeping current config",
+		"path", a.opts.ConfigPath,
+		"error", err.Error(),
+	)
+}
+
+func (a *application) logShutdown() {
+	a.log.Info("shutdown requested",
+		"drain_timeout", a.cfg.Global.Shutdown.DrainTimeout,
+		"alert_timeout", a.cfg.Global.Shutdown.AlertTimeout,
+		"server_timeout", a.cfg.Global.Shutdown.ServerTimeout,
+	)
+}
+
+// shutdown stops the HTTP server once the scheduler has drained its
+// in-flight checks and their alerts have been sent, so metrics and health
+// stay available until the end.
+func (a *application) shutdown(schedErr error) error {
+	if schedErr != nil {
+		a.log.Warn("scheduler shutdown error", "error", schedErr.Error())
+	}
+	a.log.Info("scheduler stopped")
+
+	alertTimeout, err := time.ParseDuration(a.cfg.Global.Shutdown.AlertTimeout)
+	if err != nil {
+		alertTimeout, _ = time.ParseDuration(config.DefaultAlertTimeout)
+	}
+	alertCtx, cancelAlerts := context.WithTimeout(context.Background(), alertTimeout)
+	defer cancelAlerts()
+
+	if err := a.alertEngine.Close(alertCtx); err != nil {
+		a.log.Warn("alert timeout reached; abandoning queued alerts", "timeout", alertTimeout)
+	}
+	if a.reporter != nil {
+		if err := a.reporter.Close(alertCtx); err != nil {
+			a.log.Warn("alert timeout reached; abandoning queued probe reports", "timeout", alertTimeout)
+		}
+	}
+	a.saveState()
 
-	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	serverTimeout, err := time.ParseDuration(a.cfg.Global.Shutdown.ServerTimeout)
+	if err != nil {
+		serverTimeout, _ = time.ParseDuration(config.DefaultServerTimeout)
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), serverTimeout)
 	defer cancel()
 
 	if err := a.server.Shutdown(shutdownCtx); err != nil {
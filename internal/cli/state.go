@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"uptiq/internal/alerting"
+	"uptiq/internal/config"
+)
+
+// Alert state persistence constants.
+const (
+	stateFile         = "state.json" // Inside global.data_dir
+	stateSaveInterval = 30 * time.Second
+)
+
+// statePath returns where alert state is saved for cfg, or "" if data_dir
+// is unset.
+func statePath(cfg *config.Config) string {
+	if cfg.Global.DataDir == "" {
+		return ""
+	}
+	return filepath.Join(cfg.Global.DataDir, stateFile)
+}
+
+// setupState restores the alert state saved by the previous run, so an
+// outage that spans a restart is neither alerted again nor left without
+// its recovery alert. Call before the engine's services are set, which
+// drops the state of services removed from the config meanwhile.
+func (a *application) setupState() error {
+	path := statePath(a.cfg)
+	if path == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(a.cfg.Global.DataDir, 0o755); err != nil {
+		return fmt.Errorf("create data_dir: %w", err)
+	}
+	a.statePath = path
+
+	snapshot, savedAt, err := alerting.LoadState(path)
+	if err != nil {
+		// Monitoring matters more than the old state: start from UNKNOWN
+		a.log.Warn("alert state not restored", "path", path, "error", err.Error())
+		return nil
+	}
+	a.alertEngine.Restore(snapshot)
+	if len(snapshot) > 0 {
+		a.log.Info("alert state restored", "path", path, "services", len(snapshot), "saved_at", savedAt)
+	}
+	return nil
+}
+
+// persistState saves the alert state every stateSaveInterval until ctx is
+// done. shutdown saves it a last time.
+func (a *application) persistState(ctx context.Context) {
+	ticker := time.NewTicker(stateSaveInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			a.saveState()
+		}
+	}
+}
+
+func (a *application) saveState() {
+	if a.statePath == "" {
+		return
+	}
+	if err := alerting.SaveState(a.statePath, a.alertEngine.Snapshot()); err != nil {
+		a.log.Warn("alert state not saved", "path", a.statePath, "error", err.Error())
+	}
+}
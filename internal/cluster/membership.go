@@ -0,0 +1,242 @@
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"uptiq/internal/config"
+	"uptiq/internal/metrics"
+)
+
+// HeartbeatPath is where members accept heartbeats, relative to their base URL.
+const HeartbeatPath = "/api/cluster/heartbeat"
+
+// ErrUnknownPeer is returned for a heartbeat from a peer not in the config.
+var ErrUnknownPeer = errors.New("unknown cluster peer")
+
+// Heartbeat is the body of a heartbeat request and its response.
+type Heartbeat struct {
+	Peer string `json:"peer"`
+}
+
+// Membership tracks which peers are alive and which services this instance
+// owns. Every peer is assumed alive at startup, so a restart does not make
+// the restarted member check everything until it hears from the others.
+type Membership struct {
+	self     string
+	peers    []config.ClusterPeer // Excluding self
+	token    string
+	interval time.Duration
+	timeout  time.Duration
+
+	client  *http.Client
+	log     *slog.Logger
+	metrics *metrics.Collector
+
+	mu       sync.RWMutex
+	lastSeen map[string]time.Time
+	alive    []string // Sorted, always including self
+	ring     *Ring
+
+	changes chan struct{}
+}
+
+// New creates the membership for cfg.Self.
+func New(cfg config.ClusterConfig, log *slog.Logger, m *metrics.Collector) (*Membership, error) {
+	if log == nil {
+		log = slog.Default()
+	}
+
+	interval, err := time.ParseDuration(cfg.HeartbeatInterval)
+	if err != nil {
+		return nil, fmt.Errorf("parse cluster.heartbeat_interval: %w", err)
+	}
+	timeout, err := time.ParseDuration(cfg.PeerTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("parse cluster.peer_timeout: %w", err)
+	}
+
+	ms := &Membership{
+		self:     cfg.Self,
+		token:    cfg.Token,
+		interval: interval,
+		timeout:  timeout,
+		client:   &http.Client{Timeout: interval},
+		log:      log,
+		metrics:  m,
+		lastSeen: make(map[string]time.Time),
+		changes:  make(chan struct{}, 1),
+	}
+
+	now := time.Now()
+	for _, peer := range cfg.Peers {
+		if peer.Name == cfg.Self {
+			continue
+		}
+		ms.peers = append(ms.peers, peer)
+		ms.lastSeen[peer.Name] = now
+	}
+	ms.evaluate(now)
+	return ms, nil
+}
+
+// Self returns this instance's peer name.
+func (m *Membership) Self() string {
+	return m.self
+}
+
+// Changes signals when the set of live members, and so ownership, changes.
+func (m *Membership) Changes() <-chan struct{} {
+	return m.changes
+}
+
+// Alive returns the live members in order, including this instance.
+func (m *Membership) Alive() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return slices.Clone(m.alive)
+}
+
+// Owned returns the services this instance is responsible for.
+func (m *Membership) Owned(services []config.Service) []config.Service {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var owned []config.Service
+	for _, svc := range services {
+		if m.ring.Owner(svc.ID) == m.self {
+			owned = append(owned, svc)
+		}
+	}
+	return owned
+}
+
+// Heartbeat records a heartbeat received from peer.
+func (m *Membership) Heartbeat(peer string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.lastSeen[peer]; !ok {
+		return ErrUnknownPeer
+	}
+	m.lastSeen[peer] = time.Now()
+	return nil
+}
+
+// Run sends heartbeats to every peer each interval and rebuilds the ring
+// when a peer dies or comes back, until ctx is done.
+func (m *Membership) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.sendHeartbeats(ctx)
+			m.evaluate(time.Now())
+		}
+	}
+}
+
+func (m *Membership) sendHeartbeats(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, peer := range m.peers {
+		wg.Go(func() {
+			if err := m.sendHeartbeat(ctx, peer); err != nil {
+				m.log.Debug("cluster heartbeat failed", "peer", peer.Name, "error", err.Error())
+				return
+			}
+
+			m.mu.Lock()
+			m.lastSeen[peer.Name] = time.Now()
+			m.mu.Unlock()
+		})
+	}
+	wg.Wait()
+}
+
+func (m *Membership) sendHeartbeat(ctx context.Context, peer config.ClusterPeer) error {
+	body, err := json.Marshal(Heartbeat{Peer: m.self})
+	if err != nil {
+		return fmt.Errorf("encode heartbeat: %w", err)
+	}
+
+	url := strings.TrimSuffix(peer.URL, "/") + HeartbeatPath
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+m.token)
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("send heartbeat: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("peer returned status %d", resp.StatusCode)
+	}
+
+	// A peer URL pointing at the wrong instance must not count as alive
+	var reply Heartbeat
+	if err := json.NewDecoder(resp.Body).Decode(&reply); err != nil {
+		return fmt.Errorf("decode reply: %w", err)
+	}
+	if reply.Peer != peer.Name {
+		return fmt.Errorf("reply from %q, want %q", reply.Peer, peer.Name)
+	}
+	return nil
+}
+
+// evaluate recomputes the live members and rebuilds the ring if they changed.
+func (m *Membership) evaluate(now time.Time) {
+	m.mu.Lock()
+
+	alive := []string{m.self}
+	for _, peer := range m.peers {
+		up := now.Sub(m.lastSeen[peer.Name]) <= m.timeout
+		if up {
+			alive = append(alive, peer.Name)
+		}
+		if m.metrics != nil {
+			m.metrics.SetClusterMember(peer.Name, up)
+		}
+	}
+	slices.Sort(alive)
+
+	if m.ring != nil && slices.Equal(alive, m.alive) {
+		m.mu.Unlock()
+		return
+	}
+
+	first := m.ring == nil
+	m.alive = alive
+	m.ring = NewRing(alive)
+	m.mu.Unlock()
+
+	if m.metrics != nil {
+		m.metrics.SetClusterMember(m.self, true)
+	}
+	if first {
+		return
+	}
+
+	m.log.Warn("cluster membership changed; rebalancing services", "alive", alive)
+	select {
+	case m.changes <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,115 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"uptiq/internal/config"
+	"uptiq/internal/metrics"
+)
+
+// peerServer answers heartbeats as the named peer.
+func peerServer(t *testing.T, name string) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != HeartbeatPath || r.Header.Get("Authorization") != "Bearer s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(Heartbeat{Peer: name})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func newTestMembership(t *testing.T, peers []config.ClusterPeer) *Membership {
+	t.Helper()
+
+	ms, err := New(config.ClusterConfig{
+		Self:              "a",
+		Peers:             append([]config.ClusterPeer{{Name: "a", URL: "http://127.0.0.1:1"}}, peers...),
+		Token:             "s3cret",
+		HeartbeatInterval: "20ms",
+		PeerTimeout:       "100ms",
+	}, nil, metrics.NewBundle().Collector)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	return ms
+}
+
+func TestMembership_AllPeersAliveAtStartup(t *testing.T) {
+	ms := newTestMembership(t, []config.ClusterPeer{{Name: "b", URL: "http://127.0.0.1:1"}})
+
+	if alive := ms.Alive(); !slices.Equal(alive, []string{"a", "b"}) {
+		t.Errorf("alive = %v, want [a b]", alive)
+	}
+
+	var services []config.Service
+	for i := range 100 {
+		services = append(services, config.Service{ID: fmt.Sprintf("svc-%d", i)})
+	}
+	if owned := len(ms.Owned(services)); owned == 0 || owned == 100 {
+		t.Errorf("owned %d of 100 services, want a share", owned)
+	}
+}
+
+func TestMembership_RebalancesWhenPeerDies(t *testing.T) {
+	b := peerServer(t, "b")
+	ms := newTestMembership(t, []config.ClusterPeer{
+		{Name: "b", URL: b.URL},
+		{Name: "c", URL: "http://127.0.0.1:1"}, // Never answers
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go ms.Run(ctx)
+
+	select {
+	case <-ms.Changes():
+	case <-time.After(2 * time.Second):
+		t.Fatal("no membership change after peer c went silent")
+	}
+
+	if alive := ms.Alive(); !slices.Equal(alive, []string{"a", "b"}) {
+		t.Errorf("alive = %v, want [a b]", alive)
+	}
+}
+
+func TestMembership_Heartbeat(t *testing.T) {
+	ms := newTestMembership(t, []config.ClusterPeer{{Name: "b", URL: "http://127.0.0.1:1"}})
+
+	if err := ms.Heartbeat("b"); err != nil {
+		t.Errorf("Heartbeat(b) error: %v", err)
+	}
+	if err := ms.Heartbeat("z"); !errors.Is(err, ErrUnknownPeer) {
+		t.Errorf("Heartbeat(z) error = %v, want ErrUnknownPeer", err)
+	}
+
+	// A heartbeat keeps a peer alive even if ours to it fail
+	time.Sleep(60 * time.Millisecond)
+	_ = ms.Heartbeat("b")
+	time.Sleep(60 * time.Millisecond)
+	ms.evaluate(time.Now())
+	if alive := ms.Alive(); !slices.Equal(alive, []string{"a", "b"}) {
+		t.Errorf("alive = %v, want b kept alive by its heartbeat", alive)
+	}
+}
+
+func TestMembership_SendHeartbeatChecksPeerName(t *testing.T) {
+	imposter := peerServer(t, "c")
+	ms := newTestMembership(t, nil)
+
+	err := ms.sendHeartbeat(context.Background(), config.ClusterPeer{Name: "b", URL: imposter.URL})
+	if err == nil {
+		t.Error("expected an error when another peer answers")
+	}
+}
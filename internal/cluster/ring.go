@@ -0,0 +1,58 @@
+// Package cluster shards services across uptiq instances. Live members are
+// placed on a consistent-hash ring and each service is checked by the
+// member that owns its ID, so a member joining or leaving only moves the
+// services it owned or takes over.
+package cluster
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"slices"
+	"strconv"
+)
+
+// virtualNodes is how many points each member gets on the ring. More points
+// spread services more evenly across members.
+const virtualNodes = 128
+
+// Ring maps keys to members by consistent hashing.
+type Ring struct {
+	points []uint64          // Sorted ring positions
+	owners map[uint64]string // Position → member
+}
+
+// NewRing builds a ring over members.
+func NewRing(members []string) *Ring {
+	r := &Ring{owners: make(map[uint64]string, len(members)*virtualNodes)}
+	for _, member := range members {
+		for i := range virtualNodes {
+			p := position(member + "#" + strconv.Itoa(i))
+			if _, taken := r.owners[p]; taken {
+				continue
+			}
+			r.owners[p] = member
+			r.points = append(r.points, p)
+		}
+	}
+	slices.Sort(r.points)
+	return r
+}
+
+// Owner returns the member owning key, or "" for an empty ring.
+func (r *Ring) Owner(key string) string {
+	if len(r.points) == 0 {
+		return ""
+	}
+
+	p := position(key)
+	i, _ := slices.BinarySearch(r.points, p)
+	if i == len(r.points) {
+		i = 0 // Wrap around
+	}
+	return r.owners[r.points[i]]
+}
+
+func position(key string) uint64 {
+	sum := sha256.Sum256([]byte(key))
+	return binary.BigEndian.Uint64(sum[:8])
+}
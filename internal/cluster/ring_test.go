@@ -0,0 +1,45 @@
+package cluster
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestRing_Owner(t *testing.T) {
+	if owner := NewRing(nil).Owner("svc"); owner != "" {
+		t.Errorf("empty ring owner = %q, want empty", owner)
+	}
+
+	ring := NewRing([]string{"a", "b", "c"})
+	again := NewRing([]string{"c", "a", "b"})
+
+	counts := make(map[string]int)
+	for i := range 3000 {
+		key := fmt.Sprintf("svc-%d", i)
+		owner := ring.Owner(key)
+		if owner != again.Owner(key) {
+			t.Fatalf("owner of %s depends on member order", key)
+		}
+		counts[owner]++
+	}
+
+	// Each of 3 members should get roughly a third
+	for _, member := range []string{"a", "b", "c"} {
+		if counts[member] < 700 || counts[member] > 1300 {
+			t.Errorf("member %s owns %d of 3000 keys, want about 1000", member, counts[member])
+		}
+	}
+}
+
+func TestRing_RemovingMemberOnlyMovesItsKeys(t *testing.T) {
+	full := NewRing([]string{"a", "b", "c"})
+	reduced := NewRing([]string{"a", "b"})
+
+	for i := range 1000 {
+		key := fmt.Sprintf("svc-%d", i)
+		before, after := full.Owner(key), reduced.Owner(key)
+		if before != "c" && before != after {
+			t.Fatalf("%s moved from %s to %s though %s is still alive", key, before, after, before)
+		}
+	}
+}
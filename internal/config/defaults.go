@@ -1,5 +1,7 @@
 package config
 
+import "strings"
+
 const (
 	DefaultScrapeBind  = "0.0.0.0:8080"
 	DefaultLogLevel    = "info"
@@ -7,8 +9,41 @@ const (
 	DefaultInterval    = "30s"
 	DefaultWorkerCount = 10
 	DefaultJitter      = "0s"
+	DefaultSpread      = string(SpreadRandom)
 	DefaultHTTPMethod  = "GET"
 
+	DefaultProbeStaleAfter = "5m"
+
+	DefaultHeartbeatInterval = "5s"
+	DefaultPeerTimeout       = "15s"
+
+	DefaultLeaseDuration = "15s"
+	DefaultRenewInterval = "5s"
+
+	DefaultDrainTimeout  = "30s"
+	DefaultAlertTimeout  = "10s"
+	DefaultServerTimeout = "10s"
+
+	DefaultDomainLookup   = string(DomainLookupRDAP)
+	DefaultRDAPServer     = "https://rdap.org"
+	DefaultWHOISPort      = 43
+	DefaultDomainWarnDays = 30
+	DefaultDomainFailDays = 7
+
+	DefaultNTPPort      = 123
+	DefaultNTPMaxOffset = "1s"
+
+	DefaultPagerDutyURL      = "https://events.pagerduty.com/v2/enqueue"
+	DefaultPagerDutySeverity = string(PagerDutyCritical)
+
+	DefaultOpsgenieURL      = "https://api.opsgenie.com" // EU: https://api.eu.opsgenie.com
+	DefaultOpsgeniePriority = "P3"
+
+	DefaultTelegramURL = "https://api.telegram.org"
+	DefaultNtfyURL     = "https://ntfy.sh"
+
+	DefaultWebhookMethod = "POST"
+
 	MinWorkerCount = 1
 	MaxWorkerCount = 1000
 	MinPort        = 1
@@ -18,6 +53,82 @@ const (
 func applyDefaults(cfg *Config) {
 	applyGlobalDefaults(&cfg.Global)
 	applyServiceDefaults(cfg)
+	applyProbingDefaults(&cfg.Probing)
+	applyClusterDefaults(&cfg.Cluster)
+	applyHADefaults(&cfg.HA)
+	applyChannelDefaults(cfg.Alerting.Channels)
+}
+
+func applyChannelDefaults(channels map[string]Channel) {
+	for name, ch := range channels {
+		switch ChannelType(strings.ToLower(ch.Type)) {
+		case ChannelTypePagerDuty:
+			applyPagerDutyDefaults(&ch)
+		case ChannelTypeOpsgenie:
+			applyOpsgenieDefaults(&ch)
+		case ChannelTypeTelegram:
+			if ch.APIURL == "" {
+				ch.APIURL = DefaultTelegramURL
+			}
+		case ChannelTypeNtfy:
+			if ch.APIURL == "" {
+				ch.APIURL = DefaultNtfyURL
+			}
+		case ChannelTypeWebhook:
+			if ch.Method == "" {
+				ch.Method = DefaultWebhookMethod
+			}
+		}
+		channels[name] = ch
+	}
+}
+
+func applyPagerDutyDefaults(ch *Channel) {
+	if ch.APIURL == "" {
+		ch.APIURL = DefaultPagerDutyURL
+	}
+	if ch.Severity == "" {
+		ch.Severity = DefaultPagerDutySeverity
+	}
+}
+
+func applyOpsgenieDefaults(ch *Channel) {
+	if ch.APIURL == "" {
+		ch.APIURL = DefaultOpsgenieURL
+	}
+	if ch.Priority == "" {
+		ch.Priority = DefaultOpsgeniePriority
+	}
+}
+
+func applyHADefaults(ha *HAConfig) {
+	if !ha.Enabled() {
+		return
+	}
+	if ha.LeaseDuration == "" {
+		ha.LeaseDuration = DefaultLeaseDuration
+	}
+	if ha.RenewInterval == "" {
+		ha.RenewInterval = DefaultRenewInterval
+	}
+}
+
+func applyClusterDefaults(cluster *ClusterConfig) {
+	if !cluster.Enabled() {
+		return
+	}
+	if cluster.HeartbeatInterval == "" {
+		cluster.HeartbeatInterval = DefaultHeartbeatInterval
+	}
+	if cluster.PeerTimeout == "" {
+		cluster.PeerTimeout = DefaultPeerTimeout
+	}
+}
+
+func applyProbingDefaults(probing *ProbingConfig) {
+	if ProbeMode(probing.Mode) == ProbeModePrimary && probing.StaleAfter == "" {
+		probing.StaleAfter = DefaultProbeStaleAfter
+	}
 }
 
 func applyGlobalDefaults(global *GlobalConfig) {
@@ -39,6 +150,18 @@ func applyGlobalDefaults(global *GlobalConfig) {
 	if global.Jitter == "" {
 		global.Jitter = DefaultJitter
 	}
+	if global.Spread == "" {
+		global.Spread = DefaultSpread
+	}
+	if global.Shutdown.DrainTimeout == "" {
+		global.Shutdown.DrainTimeout = DefaultDrainTimeout
+	}
+	if global.Shutdown.AlertTimeout == "" {
+		global.Shutdown.AlertTimeout = DefaultAlertTimeout
+	}
+	if global.Shutdown.ServerTimeout == "" {
+		global.Shutdown.ServerTimeout = DefaultServerTimeout
+	}
 }
 
 func applyServiceDefaults(cfg *Config) {
@@ -48,11 +171,45 @@ func applyServiceDefaults(cfg *Config) {
 		if svc.Timeout == "" {
 			svc.Timeout = cfg.Global.DefaultTimeout
 		}
-		if svc.Interval == "" {
+		if svc.Interval == "" && !svc.IsScheduled() {
 			svc.Interval = cfg.Global.DefaultInterval
 		}
+		if svc.MaxFailureInterval == "" && svc.FailureBackoff > 0 && !svc.IsScheduled() {
+			svc.MaxFailureInterval = svc.Interval
+		}
 		if svc.Method == "" && svc.IsHTTP() {
 			svc.Method = DefaultHTTPMethod
 		}
+		if svc.IsDomain() {
+			applyDomainDefaults(svc)
+		}
+		if svc.IsNTP() {
+			applyNTPDefaults(svc)
+		}
+	}
+}
+
+// applyDomainDefaults also normalizes lookup to lower case, so validation
+// and the checker compare it as is.
+func applyDomainDefaults(svc *Service) {
+	svc.Lookup = strings.ToLower(strings.TrimSpace(svc.Lookup))
+	if svc.Lookup == "" {
+		svc.Lookup = DefaultDomainLookup
+	}
+	if svc.LookupServer == "" && DomainLookup(svc.Lookup) == DomainLookupRDAP {
+		svc.LookupServer = DefaultRDAPServer
+	}
+
+	warn, fail := svc.ExpiryWindows()
+	svc.WarnWithinDays = &warn
+	svc.FailWithinDays = &fail
+}
+
+func applyNTPDefaults(svc *Service) {
+	if svc.Port == 0 {
+		svc.Port = DefaultNTPPort
+	}
+	if svc.MaxOffset == "" {
+		svc.MaxOffset = DefaultNTPMaxOffset
 	}
 }
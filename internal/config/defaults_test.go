@@ -18,6 +18,12 @@ func TestApplyGlobalDefaults(t *testing.T) {
 				DefaultInterval: DefaultInterval,
 				WorkerCount:     DefaultWorkerCount,
 				Jitter:          DefaultJitter,
+				Spread:          DefaultSpread,
+				Shutdown: ShutdownConfig{
+					DrainTimeout:  DefaultDrainTimeout,
+					AlertTimeout:  DefaultAlertTimeout,
+					ServerTimeout: DefaultServerTimeout,
+				},
 			},
 		},
 		{
@@ -33,6 +39,12 @@ func TestApplyGlobalDefaults(t *testing.T) {
 				DefaultInterval: DefaultInterval,
 				WorkerCount:     DefaultWorkerCount,
 				Jitter:          DefaultJitter,
+				Spread:          DefaultSpread,
+				Shutdown: ShutdownConfig{
+					DrainTimeout:  DefaultDrainTimeout,
+					AlertTimeout:  DefaultAlertTimeout,
+					ServerTimeout: DefaultServerTimeout,
+				},
 			},
 		},
 		{
@@ -44,6 +56,12 @@ func TestApplyGlobalDefaults(t *testing.T) {
 				DefaultInterval: "1m",
 				WorkerCount:     20,
 				Jitter:          "1s",
+				Spread:          "hash",
+				Shutdown: ShutdownConfig{
+					DrainTimeout:  "1m",
+					AlertTimeout:  "5s",
+					ServerTimeout: "0s",
+				},
 			},
 			expect: GlobalConfig{
 				ScrapeBind:      "0.0.0.0:3000",
@@ -52,6 +70,12 @@ func TestApplyGlobalDefaults(t *testing.T) {
 				DefaultInterval: "1m",
 				WorkerCount:     20,
 				Jitter:          "1s",
+				Spread:          "hash",
+				Shutdown: ShutdownConfig{
+					DrainTimeout:  "1m",
+					AlertTimeout:  "5s",
+					ServerTimeout: "0s",
+				},
 			},
 		},
 	}
@@ -79,6 +103,12 @@ func TestApplyGlobalDefaults(t *testing.T) {
 			if global.Jitter != tc.expect.Jitter {
 				t.Errorf("Jitter = %q, want %q", global.Jitter, tc.expect.Jitter)
 			}
+			if global.Spread != tc.expect.Spread {
+				t.Errorf("Spread = %q, want %q", global.Spread, tc.expect.Spread)
+			}
+			if global.Shutdown != tc.expect.Shutdown {
+				t.Errorf("Shutdown = %+v, want %+v", global.Shutdown, tc.expect.Shutdown)
+			}
 		})
 	}
 }
@@ -149,6 +179,198 @@ func TestApplyServiceDefaults(t *testing.T) {
 	}
 }
 
+func TestApplyServiceDefaults_Domain(t *testing.T) {
+	cfg := &Config{
+		Global: GlobalConfig{
+			DefaultTimeout:  "5s",
+			DefaultInterval: "30s",
+		},
+		Services: []Service{
+			{ID: "rdap", Type: "domain", Domain: "example.com"},
+			{ID: "whois", Type: "domain", Domain: "example.com", Lookup: " WHOIS", WarnWithinDays: intPtr(60), FailWithinDays: intPtr(14)},
+			{ID: "zero", Type: "domain", Domain: "example.com", WarnWithinDays: intPtr(0), FailWithinDays: intPtr(0)},
+			{ID: "long", Type: "domain", Domain: "example.com", FailWithinDays: intPtr(45)},
+		},
+	}
+
+	applyServiceDefaults(cfg)
+
+	rdap := cfg.Services[0]
+	if rdap.Lookup != DefaultDomainLookup {
+		t.Errorf("rdap Lookup = %q, want %q", rdap.Lookup, DefaultDomainLookup)
+	}
+	if rdap.LookupServer != DefaultRDAPServer {
+		t.Errorf("rdap LookupServer = %q, want %q", rdap.LookupServer, DefaultRDAPServer)
+	}
+	if rdap.WarnWithinDays == nil || *rdap.WarnWithinDays != DefaultDomainWarnDays {
+		t.Errorf("rdap WarnWithinDays = %v, want %d", rdap.WarnWithinDays, DefaultDomainWarnDays)
+	}
+	if rdap.FailWithinDays == nil || *rdap.FailWithinDays != DefaultDomainFailDays {
+		t.Errorf("rdap FailWithinDays = %v, want %d", rdap.FailWithinDays, DefaultDomainFailDays)
+	}
+	if rdap.Method != "" {
+		t.Errorf("rdap Method = %q, want empty (domain service)", rdap.Method)
+	}
+
+	whois := cfg.Services[1]
+	if whois.Lookup != string(DomainLookupWHOIS) {
+		t.Errorf("whois Lookup = %q, want it normalized to %q", whois.Lookup, DomainLookupWHOIS)
+	}
+	if whois.LookupServer != "" {
+		t.Errorf("whois LookupServer = %q, want empty (no default WHOIS server)", whois.LookupServer)
+	}
+	if warn, fail := whois.ExpiryWindows(); warn != 60 || fail != 14 {
+		t.Errorf("whois windows = %d/%d, want 60/14", warn, fail)
+	}
+
+	// An explicit 0 is kept rather than replaced by the default
+	if warn, fail := cfg.Services[2].ExpiryWindows(); warn != 0 || fail != 0 {
+		t.Errorf("zero windows = %d/%d, want 0/0", warn, fail)
+	}
+
+	// The default warn window grows to a longer fail window
+	if warn, fail := cfg.Services[3].ExpiryWindows(); warn != 45 || fail != 45 {
+		t.Errorf("long windows = %d/%d, want 45/45", warn, fail)
+	}
+}
+
+func intPtr(n int) *int {
+	return &n
+}
+
+func TestApplyServiceDefaults_NTP(t *testing.T) {
+	cfg := &Config{
+		Global: GlobalConfig{
+			DefaultTimeout:  "5s",
+			DefaultInterval: "30s",
+		},
+		Services: []Service{
+			{ID: "ntp-default", Type: "ntp", Host: "pool.ntp.org"},
+			{ID: "ntp-custom", Type: "ntp", Host: "time.internal", Port: 1123, MaxOffset: "100ms"},
+		},
+	}
+
+	applyServiceDefaults(cfg)
+
+	if cfg.Services[0].Port != DefaultNTPPort {
+		t.Errorf("ntp-default Port = %d, want %d", cfg.Services[0].Port, DefaultNTPPort)
+	}
+	if cfg.Services[0].MaxOffset != DefaultNTPMaxOffset {
+		t.Errorf("ntp-default MaxOffset = %q, want %q", cfg.Services[0].MaxOffset, DefaultNTPMaxOffset)
+	}
+	if cfg.Services[1].Port != 1123 {
+		t.Errorf("ntp-custom Port = %d, want 1123", cfg.Services[1].Port)
+	}
+	if cfg.Services[1].MaxOffset != "100ms" {
+		t.Errorf("ntp-custom MaxOffset = %q, want %q", cfg.Services[1].MaxOffset, "100ms")
+	}
+}
+
+func TestApplyChannelDefaults_PagerDuty(t *testing.T) {
+	channels := map[string]Channel{
+		"pd":       {Type: "pagerduty", RoutingKey: "key"},
+		"pd-local": {Type: "PagerDuty", RoutingKey: "key", APIURL: "http://127.0.0.1:9999/enqueue", Severity: "warning"},
+		"slack":    {Type: "slack", WebhookURL: "https://hooks.slack.com/x"},
+	}
+
+	applyChannelDefaults(channels)
+
+	if got := channels["pd"]; got.APIURL != DefaultPagerDutyURL || got.Severity != DefaultPagerDutySeverity {
+		t.Errorf("pd = (%q, %q), want (%q, %q)", got.APIURL, got.Severity, DefaultPagerDutyURL, DefaultPagerDutySeverity)
+	}
+	if got := channels["pd-local"]; got.APIURL != "http://127.0.0.1:9999/enqueue" || got.Severity != "warning" {
+		t.Errorf("pd-local = (%q, %q), want its own settings kept", got.APIURL, got.Severity)
+	}
+	if got := channels["slack"]; got.APIURL != "" {
+		t.Errorf("slack APIURL = %q, want empty", got.APIURL)
+	}
+}
+
+func TestApplyChannelDefaults_Opsgenie(t *testing.T) {
+	channels := map[string]Channel{
+		"og":    {Type: "opsgenie", APIKey: "key"},
+		"og-eu": {Type: "opsgenie", APIKey: "key", APIURL: "https://api.eu.opsgenie.com", Priority: "P1"},
+	}
+
+	applyChannelDefaults(channels)
+
+	if got := channels["og"]; got.APIURL != DefaultOpsgenieURL || got.Priority != DefaultOpsgeniePriority {
+		t.Errorf("og = (%q, %q), want (%q, %q)", got.APIURL, got.Priority, DefaultOpsgenieURL, DefaultOpsgeniePriority)
+	}
+	if got := channels["og-eu"]; got.APIURL != "https://api.eu.opsgenie.com" || got.Priority != "P1" {
+		t.Errorf("og-eu = (%q, %q), want its own settings kept", got.APIURL, got.Priority)
+	}
+}
+
+func TestApplyChannelDefaults_ChatAndPush(t *testing.T) {
+	channels := map[string]Channel{
+		"telegram": {Type: "telegram", BotToken: "123:abc", ChatID: "1"},
+		"ntfy":     {Type: "ntfy", Topic: "alerts"},
+		"gotify":   {Type: "gotify", Token: "t"},
+	}
+
+	applyChannelDefaults(channels)
+
+	if got := channels["telegram"].APIURL; got != DefaultTelegramURL {
+		t.Errorf("telegram APIURL = %q, want %q", got, DefaultTelegramURL)
+	}
+	if got := channels["ntfy"].APIURL; got != DefaultNtfyURL {
+		t.Errorf("ntfy APIURL = %q, want %q", got, DefaultNtfyURL)
+	}
+	// A self-hosted server has no sensible default
+	if got := channels["gotify"].APIURL; got != "" {
+		t.Errorf("gotify APIURL = %q, want empty", got)
+	}
+}
+
+func TestApplyServiceDefaults_Schedule(t *testing.T) {
+	cfg := &Config{
+		Global: GlobalConfig{
+			DefaultTimeout:  "5s",
+			DefaultInterval: "30s",
+		},
+		Services: []Service{
+			{ID: "cron", Type: "http", URL: "https://example.com", Schedule: "@hourly"},
+			{ID: "interval", Type: "http", URL: "https://example.com"},
+		},
+	}
+
+	applyServiceDefaults(cfg)
+
+	if cfg.Services[0].Interval != "" {
+		t.Errorf("scheduled service Interval = %q, want empty", cfg.Services[0].Interval)
+	}
+	if cfg.Services[1].Interval != "30s" {
+		t.Errorf("interval service Interval = %q, want %q", cfg.Services[1].Interval, "30s")
+	}
+}
+
+func TestApplyServiceDefaults_MaxFailureInterval(t *testing.T) {
+	cfg := &Config{
+		Global: GlobalConfig{
+			DefaultTimeout:  "5s",
+			DefaultInterval: "30s",
+		},
+		Services: []Service{
+			{ID: "backoff", Type: "tcp", Interval: "5m", FailureInterval: "15s", FailureBackoff: 2},
+			{ID: "no-backoff", Type: "tcp", Interval: "5m", FailureInterval: "15s"},
+			{ID: "scheduled", Type: "tcp", Schedule: "@hourly", FailureInterval: "15s", FailureBackoff: 2},
+		},
+	}
+
+	applyServiceDefaults(cfg)
+
+	if got := cfg.Services[0].MaxFailureInterval; got != "5m" {
+		t.Errorf("backoff MaxFailureInterval = %q, want the service interval %q", got, "5m")
+	}
+	if got := cfg.Services[1].MaxFailureInterval; got != "" {
+		t.Errorf("no-backoff MaxFailureInterval = %q, want empty", got)
+	}
+	if got := cfg.Services[2].MaxFailureInterval; got != "" {
+		t.Errorf("scheduled MaxFailureInterval = %q, want empty (no interval to default to)", got)
+	}
+}
+
 func TestApplyDefaults_Integration(t *testing.T) {
 	cfg := &Config{
 		Global:   GlobalConfig{},
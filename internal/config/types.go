@@ -2,9 +2,14 @@ package config
 
 // Config is the root configuration structure for uptiq.
 type Config struct {
-	Global   GlobalConfig   `yaml:"global"`
-	Services []Service      `yaml:"services"`
-	Alerting AlertingConfig `yaml:"alerting"`
+	Global      GlobalConfig        `yaml:"global"`
+	Services    []Service           `yaml:"services"`
+	Alerting    AlertingConfig      `yaml:"alerting"`
+	Maintenance []MaintenanceWindow `yaml:"maintenance"`
+	Limits      []Limit             `yaml:"limits"`
+	Probing     ProbingConfig       `yaml:"probing"`
+	Cluster     ClusterConfig       `yaml:"cluster"`
+	HA          HAConfig            `yaml:"ha"`
 }
 
 // GlobalConfig contains daemon-wide settings.
@@ -15,20 +20,68 @@ type GlobalConfig struct {
 	DefaultInterval string `yaml:"default_interval"`
 	WorkerCount     int    `yaml:"worker_count"`
 	Jitter          string `yaml:"jitter"`
+
+	// Spread selects how interval checks are phased: "random" adds Jitter
+	// to each run, "hash" derives a stable offset from the service ID.
+	Spread string `yaml:"spread"`
+
+	// APIToken enables the write endpoints of the HTTP API; requests must
+	// send it as a bearer token. Write endpoints are disabled when empty.
+	APIToken string `yaml:"api_token"`
+
+	// DataDir holds state kept across restarts, such as the alert outbox.
+	// Nothing is persisted when empty.
+	DataDir string `yaml:"data_dir"`
+
+	Shutdown ShutdownConfig `yaml:"shutdown"`
+}
+
+// ShutdownConfig bounds the phases of a graceful shutdown. On SIGTERM the
+// scheduler stops dispatching, in-flight checks get DrainTimeout to finish,
+// alerts they triggered get AlertTimeout to be sent, and the HTTP server
+// is stopped last within ServerTimeout.
+type ShutdownConfig struct {
+	DrainTimeout  string `yaml:"drain_timeout"`
+	AlertTimeout  string `yaml:"alert_timeout"`
+	ServerTimeout string `yaml:"server_timeout"`
 }
 
+// SpreadMode represents how checks are distributed within their interval.
+type SpreadMode string
+
+const (
+	SpreadRandom SpreadMode = "random"
+	SpreadHash   SpreadMode = "hash"
+)
+
 // ServiceType represents the type of service check.
 type ServiceType string
 
 const (
-	ServiceTypeHTTP ServiceType = "http"
-	ServiceTypeTCP  ServiceType = "tcp"
+	ServiceTypeHTTP   ServiceType = "http"
+	ServiceTypeTCP    ServiceType = "tcp"
+	ServiceTypeDomain ServiceType = "domain"
+	ServiceTypeNTP    ServiceType = "ntp"
+)
+
+// DomainLookup represents the protocol used to query domain registration data.
+type DomainLookup string
+
+const (
+	DomainLookupRDAP  DomainLookup = "rdap"
+	DomainLookupWHOIS DomainLookup = "whois"
 )
 
 type Service struct {
-	ID   string `yaml:"id"`
-	Name string `yaml:"name"`
-	Type string `yaml:"type"` // "http" or "tcp"
+	ID   string   `yaml:"id"`
+	Name string   `yaml:"name"`
+	Type string   `yaml:"type"` // "http", "tcp", "domain" or "ntp"
+	Tags []string `yaml:"tags"`
+
+	// Labels and Links are free-form metadata for alert templates, e.g.
+	// the owning team and the runbook URL.
+	Labels map[string]string `yaml:"labels"`
+	Links  map[string]string `yaml:"links"`
 
 	// HTTP-specific fields
 	URL            string            `yaml:"url"`
@@ -37,12 +90,39 @@ type Service struct {
 	Contains       string            `yaml:"contains"`
 	Headers        map[string]string `yaml:"headers"`
 
-	// TCP-specific fields
+	// TCP/NTP-specific fields
 	Host string `yaml:"host"`
 	Port int    `yaml:"port"`
 
+	// NTP-specific fields
+	MaxOffset string `yaml:"max_offset"`
+
+	// Domain-specific fields
+	Domain         string `yaml:"domain"`
+	Lookup         string `yaml:"lookup"`           // "rdap" (default) or "whois"
+	LookupServer   string `yaml:"lookup_server"`    // RDAP base URL or WHOIS host[:port]
+	WarnWithinDays *int   `yaml:"warn_within_days"` // Unset = DefaultDomainWarnDays, or fail_within_days if longer; 0 never warns
+	FailWithinDays *int   `yaml:"fail_within_days"` // Unset = DefaultDomainFailDays; 0 fails only once expired
+
 	Interval string `yaml:"interval"`
 	Timeout  string `yaml:"timeout"`
+
+	// Schedule is a cron expression (optionally with a leading seconds
+	// field) used instead of Interval, evaluated in Timezone (default UTC).
+	Schedule string `yaml:"schedule"`
+	Timezone string `yaml:"timezone"`
+
+	// FailureInterval re-checks a failing service faster until it recovers
+	// or its outage is confirmed. FailureBackoff (> 1) then multiplies the
+	// delay after each check while down, up to MaxFailureInterval.
+	FailureInterval    string  `yaml:"failure_interval"`
+	FailureBackoff     float64 `yaml:"failure_backoff"`
+	MaxFailureInterval string  `yaml:"max_failure_interval"`
+
+	// DependsOn lists parent service IDs; while a parent is down this
+	// service is reported UNREACHABLE and its alerts are suppressed. Not
+	// supported with cluster.
+	DependsOn []string `yaml:"depends_on"`
 }
 
 // IsHTTP returns true if the service type is HTTP.
@@ -55,6 +135,36 @@ func (s Service) IsTCP() bool {
 	return ServiceType(s.Type) == ServiceTypeTCP
 }
 
+// IsDomain returns true if the service type is domain.
+func (s Service) IsDomain() bool {
+	return ServiceType(s.Type) == ServiceTypeDomain
+}
+
+// IsNTP returns true if the service type is NTP.
+func (s Service) IsNTP() bool {
+	return ServiceType(s.Type) == ServiceTypeNTP
+}
+
+// ExpiryWindows returns warn_within_days and fail_within_days, with the
+// defaults for those not set. An unset warn window is never shorter than
+// the fail window.
+func (s Service) ExpiryWindows() (warn, fail int) {
+	fail = DefaultDomainFailDays
+	if s.FailWithinDays != nil {
+		fail = *s.FailWithinDays
+	}
+	warn = max(DefaultDomainWarnDays, fail)
+	if s.WarnWithinDays != nil {
+		warn = *s.WarnWithinDays
+	}
+	return warn, fail
+}
+
+// IsScheduled returns true if the service runs on a cron schedule instead of an interval.
+func (s Service) IsScheduled() bool {
+	return s.Schedule != ""
+}
+
 // AlertingConfig holds all alerting-related configuration.
 type AlertingConfig struct {
 	Channels map[string]Channel `yaml:"channels"`
@@ -65,15 +175,67 @@ type AlertingConfig struct {
 type ChannelType string
 
 const (
-	ChannelTypeDiscord ChannelType = "discord"
-	ChannelTypeSlack   ChannelType = "slack"
-	ChannelTypeEmail   ChannelType = "email"
+	ChannelTypeDiscord   ChannelType = "discord"
+	ChannelTypeSlack     ChannelType = "slack"
+	ChannelTypeEmail     ChannelType = "email"
+	ChannelTypePagerDuty ChannelType = "pagerduty"
+	ChannelTypeOpsgenie  ChannelType = "opsgenie"
+	ChannelTypeTeams     ChannelType = "teams"
+	ChannelTypeTelegram  ChannelType = "telegram"
+	ChannelTypeMatrix    ChannelType = "matrix"
+	ChannelTypeNtfy      ChannelType = "ntfy"
+	ChannelTypeGotify    ChannelType = "gotify"
+	ChannelTypeWebhook   ChannelType = "webhook"
 )
 
 // Channel supports multiple types; keep a superset of fields.
 type Channel struct {
-	Type       string `yaml:"type"` // "discord" | "slack" | "email"
-	WebhookURL string `yaml:"webhook_url"`
+	Type       string `yaml:"type"`        // See ChannelType
+	WebhookURL string `yaml:"webhook_url"` // discord, slack, teams, webhook
+
+	// APIURL is the service's API endpoint: the homeserver for matrix and
+	// the server for gotify, which have no default; for the other types it
+	// overrides the default, e.g. to test against a local stand-in.
+	APIURL string `yaml:"api_url"`
+
+	// Token authenticates to matrix (access token), ntfy (access token,
+	// optional) and gotify (application token).
+	Token string `yaml:"token"`
+
+	// Telegram-specific fields
+	BotToken string `yaml:"bot_token"`
+	ChatID   string `yaml:"chat_id"` // Numeric ID or @channelname
+
+	// Matrix-specific fields
+	RoomID string `yaml:"room_id"` // e.g. !abc123:example.org
+
+	// ntfy-specific fields
+	Topic string `yaml:"topic"`
+
+	// PagerDuty-specific fields
+	RoutingKey string `yaml:"routing_key"` // Events API v2 integration key
+	Severity   string `yaml:"severity"`    // "critical" | "error" | "warning" | "info"
+
+	// Opsgenie-specific fields
+	APIKey     string              `yaml:"api_key"` // API integration key
+	Responders []OpsgenieResponder `yaml:"responders"`
+
+	// Priority is "P1" (highest) to "P5" for opsgenie, "min", "low",
+	// "default", "high" or "urgent" for ntfy, and "0" to "10" for gotify.
+	Priority string `yaml:"priority"`
+
+	// Tags for opsgenie, and ntfy (which shows emoji shortcodes as emoji)
+	Tags []string `yaml:"tags"`
+
+	// Webhook-specific fields. Header values and the body are text/template
+	// templates executed with the alert (see internal/templates).
+	Method  string            `yaml:"method"` // "POST" | "PUT" | "PATCH"
+	Headers map[string]string `yaml:"headers"`
+	Body    string            `yaml:"body"`   // Default: the alert as JSON
+	Secret  string            `yaml:"secret"` // Signs requests with HMAC-SHA256 when set
+
+	// Templates override the built-in alert messages sent to this channel.
+	Templates MessageTemplates `yaml:"templates"`
 
 	// Email-specific fields
 	SMTPHost string   `yaml:"smtp_host"`
@@ -84,11 +246,55 @@ type Channel struct {
 	To       []string `yaml:"to"`
 }
 
+// PagerDutySeverity is the severity of a PagerDuty event.
+type PagerDutySeverity string
+
+const (
+	PagerDutyCritical PagerDutySeverity = "critical"
+	PagerDutyError    PagerDutySeverity = "error"
+	PagerDutyWarning  PagerDutySeverity = "warning"
+	PagerDutyInfo     PagerDutySeverity = "info"
+)
+
+// OpsgenieResponder is a team, user, escalation or schedule notified of an
+// Opsgenie alert.
+type OpsgenieResponder struct {
+	Type string `yaml:"type"` // "team" | "user" | "escalation" | "schedule"
+	Name string `yaml:"name"` // Username (email) for type=user
+}
+
+// OpsgenieResponderType is the kind of an Opsgenie responder.
+type OpsgenieResponderType string
+
+const (
+	OpsgenieTeam       OpsgenieResponderType = "team"
+	OpsgenieUser       OpsgenieResponderType = "user"
+	OpsgenieEscalation OpsgenieResponderType = "escalation"
+	OpsgenieSchedule   OpsgenieResponderType = "schedule"
+)
+
+// NtfyPriorities maps ntfy's priority names to its numeric levels.
+var NtfyPriorities = map[string]int{"min": 1, "low": 2, "default": 3, "high": 4, "urgent": 5}
+
 // Route defines how alerts are routed based on service matches.
 type Route struct {
 	Match  RouteMatch  `yaml:"match"`
 	Policy RoutePolicy `yaml:"policy"`
 	Notify []string    `yaml:"notify"`
+
+	// Templates override the built-in alert messages of the matched
+	// services, and take precedence over the channels' templates.
+	Templates MessageTemplates `yaml:"templates"`
+}
+
+// MessageTemplates are Go templates replacing the built-in alert messages;
+// an empty one keeps the built-in. They are executed with the alert (see
+// internal/templates).
+type MessageTemplates struct {
+	Message  string `yaml:"message"`   // Chat and push message
+	Subject  string `yaml:"subject"`   // Email subject; title or summary elsewhere
+	Body     string `yaml:"body"`      // Plain text email body; details elsewhere
+	HTMLBody string `yaml:"html_body"` // HTML email body, sent alongside the plain text
 }
 
 // RouteMatch specifies which services a route applies to.
@@ -102,3 +308,119 @@ type RoutePolicy struct {
 	Cooldown         string `yaml:"cooldown"`
 	RecoveryAlert    bool   `yaml:"recovery_alert"`
 }
+
+// MaintenanceWindow suppresses notifications for matching services.
+// A window is either one-off (start/end) or recurring (schedule/duration).
+type MaintenanceWindow struct {
+	Name  string           `yaml:"name"`
+	Match MaintenanceMatch `yaml:"match"`
+
+	// One-off window (RFC 3339 timestamps)
+	Start string `yaml:"start"`
+	End   string `yaml:"end"`
+
+	// Recurring window: cron expression, window length and time zone
+	Schedule string `yaml:"schedule"`
+	Duration string `yaml:"duration"`
+	Timezone string `yaml:"timezone"`
+}
+
+// MaintenanceMatch specifies which services a maintenance window applies to.
+type MaintenanceMatch struct {
+	ServiceIDs []string `yaml:"service_ids"`
+	Tags       []string `yaml:"tags"`
+}
+
+// IsRecurring returns true if the window repeats on a cron schedule.
+func (w MaintenanceWindow) IsRecurring() bool {
+	return w.Schedule != ""
+}
+
+// Limit caps how hard the scheduler hits a group of targets. All services
+// matching a limit share its concurrency slots and request budget.
+type Limit struct {
+	Name          string     `yaml:"name"`
+	Match         LimitMatch `yaml:"match"`
+	MaxConcurrent int        `yaml:"max_concurrent"` // 0 = unlimited
+	RPS           float64    `yaml:"rps"`            // Checks started per second; 0 = unlimited
+}
+
+// LimitMatch specifies which services a limit applies to.
+// Hosts match the service's target host (URL host, host, or domain).
+type LimitMatch struct {
+	Hosts []string `yaml:"hosts"`
+	Tags  []string `yaml:"tags"`
+}
+
+// ProbeMode is the role of an instance in multi-location probing.
+type ProbeMode string
+
+const (
+	ProbeModeStandalone ProbeMode = ""
+	ProbeModePrimary    ProbeMode = "primary"
+	ProbeModeProbe      ProbeMode = "probe"
+)
+
+// ProbingConfig runs uptiq in several locations. Probes check the same
+// services as the primary and report their results to it over HTTP; the
+// primary alerts on a service only when Quorum locations see it failing.
+type ProbingConfig struct {
+	Mode     string `yaml:"mode"`     // "" (standalone), "primary" or "probe"
+	Location string `yaml:"location"` // Name of this instance's location
+
+	// Token authenticates probe reports (sent as a bearer token).
+	Token string `yaml:"token"`
+
+	// Probe mode: base URL of the primary's HTTP server.
+	Primary string `yaml:"primary"`
+
+	// Primary mode: failing locations needed to mark a service down
+	// (0 = a majority), and how long a location's last result counts.
+	Quorum     int    `yaml:"quorum"`
+	StaleAfter string `yaml:"stale_after"`
+}
+
+// ClusterConfig shards services across instances sharing the same config.
+// Each live member checks the services it owns on a consistent-hash ring;
+// members exchange HTTP heartbeats and the ring is rebuilt when one dies.
+type ClusterConfig struct {
+	Self  string        `yaml:"self"` // Name of this instance in Peers
+	Peers []ClusterPeer `yaml:"peers"`
+
+	// Token authenticates heartbeats (sent as a bearer token).
+	Token string `yaml:"token"`
+
+	HeartbeatInterval string `yaml:"heartbeat_interval"`
+	PeerTimeout       string `yaml:"peer_timeout"` // Silence after which a peer is dead
+}
+
+// ClusterPeer is a member of the cluster.
+type ClusterPeer struct {
+	Name string `yaml:"name"`
+	URL  string `yaml:"url"` // Base URL of the peer's HTTP server
+}
+
+// Enabled reports whether clustering is configured.
+func (c ClusterConfig) Enabled() bool {
+	return len(c.Peers) > 0
+}
+
+// HAConfig runs two instances as an active/passive pair. Both check every
+// service, but only the holder of the lease sends alerts. The leader renews
+// its lease on the standby over HTTP and replicates its alert state with
+// each renewal; the standby takes over when the lease expires.
+type HAConfig struct {
+	Name    string `yaml:"name"`     // This instance; the lower name wins a tie
+	PeerURL string `yaml:"peer_url"` // Base URL of the other instance's HTTP server
+
+	// Token authenticates lease renewals (sent as a bearer token).
+	Token string `yaml:"token"`
+
+	LeaseDuration string `yaml:"lease_duration"` // Silence after which the standby takes over
+	RenewInterval string `yaml:"renew_interval"`
+}
+
+// Enabled reports whether HA is configured.
+func (h HAConfig) Enabled() bool {
+	return h.PeerURL != ""
+}
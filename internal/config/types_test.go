@@ -46,6 +46,27 @@ func TestService_IsTCP(t *testing.T) {
 	}
 }
 
+func TestService_IsDomain(t *testing.T) {
+	tests := []struct {
+		serviceType string
+		expected    bool
+	}{
+		{"domain", true},
+		{"DOMAIN", false}, // case sensitive
+		{"http", false},
+		{"", false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.serviceType, func(t *testing.T) {
+			svc := Service{Type: tc.serviceType}
+			if got := svc.IsDomain(); got != tc.expected {
+				t.Errorf("IsDomain() for type %q = %v, want %v", tc.serviceType, got, tc.expected)
+			}
+		})
+	}
+}
+
 func TestServiceType_Constants(t *testing.T) {
 	if ServiceTypeHTTP != "http" {
 		t.Errorf("ServiceTypeHTTP = %q, want %q", ServiceTypeHTTP, "http")
@@ -3,15 +3,25 @@ package config
 import (
 	"fmt"
 	"net"
+	"net/http"
+	"net/url"
 	"regexp"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
+
+	"uptiq/internal/cron"
+	"uptiq/internal/templates"
 )
 
 var (
 	// idRegex validates service IDs contain only safe characters
 	idRegex = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+	// headerNameRegex validates HTTP header names (RFC 9110 tokens)
+	headerNameRegex = regexp.MustCompile("^[!#$%&'*+.^_`|~0-9A-Za-z-]+$")
 )
 
 // ValidationError contains multiple validation failures
@@ -30,6 +40,11 @@ func (cfg *Config) Validate() error {
 	v.validateGlobal(cfg.Global)
 	v.validateServices(cfg.Services)
 	v.validateAlerting(cfg.Alerting)
+	v.validateMaintenance(cfg.Maintenance, cfg.Services)
+	v.validateLimits(cfg.Limits)
+	v.validateProbing(cfg.Probing)
+	v.validateCluster(cfg.Cluster, cfg.Probing, cfg.Services)
+	v.validateHA(cfg.HA, cfg.Cluster, cfg.Probing)
 
 	if len(v.errors) > 0 {
 		sort.Strings(v.errors)
@@ -56,9 +71,42 @@ func (v *validator) validateGlobal(global GlobalConfig) {
 	v.validateDuration("global.default_interval", global.DefaultInterval)
 	v.validateDuration("global.jitter", global.Jitter)
 
+	switch SpreadMode(global.Spread) {
+	case "", SpreadRandom, SpreadHash:
+	default:
+		v.addError("global.spread must be 'random' or 'hash' (got %q)", global.Spread)
+	}
+
 	if global.WorkerCount < MinWorkerCount || global.WorkerCount > MaxWorkerCount {
 		v.addError("global.worker_count must be between %d and %d (got %d)", MinWorkerCount, MaxWorkerCount, global.WorkerCount)
 	}
+
+	v.validateShutdown(global.Shutdown)
+}
+
+func (v *validator) validateShutdown(shutdown ShutdownConfig) {
+	timeouts := []struct {
+		field string
+		value string
+	}{
+		{"global.shutdown.drain_timeout", shutdown.DrainTimeout},
+		{"global.shutdown.alert_timeout", shutdown.AlertTimeout},
+		{"global.shutdown.server_timeout", shutdown.ServerTimeout},
+	}
+
+	for _, t := range timeouts {
+		if t.value == "" {
+			continue
+		}
+		d, err := time.ParseDuration(t.value)
+		if err != nil {
+			v.addError("%s must be a valid duration %q: %v", t.field, t.value, err)
+			continue
+		}
+		if d < 0 {
+			v.addError("%s must not be negative (got %q)", t.field, t.value)
+		}
+	}
 }
 
 func (v *validator) validateDuration(field, value string) {
@@ -74,6 +122,81 @@ func (v *validator) validateServices(services []Service) {
 		prefix := fmt.Sprintf("services[%d]", i)
 		v.validateService(prefix, svc, seen)
 	}
+
+	v.validateDependencies(services, seen)
+}
+
+func (v *validator) validateDependencies(services []Service, known map[string]struct{}) {
+	graph := make(map[string][]string)
+
+	for i, svc := range services {
+		prefix := fmt.Sprintf("services[%d]", i)
+
+		for _, dep := range svc.DependsOn {
+			switch _, exists := known[dep]; {
+			case dep == svc.ID:
+				v.addError("%s.depends_on cannot reference itself (%q)", prefix, dep)
+			case !exists:
+				v.addError("%s.depends_on references unknown service %q", prefix, dep)
+			default:
+				graph[svc.ID] = append(graph[svc.ID], dep)
+			}
+		}
+	}
+
+	for _, cycle := range findDependencyCycles(graph) {
+		v.addError("services dependency cycle detected: %s", strings.Join(cycle, " -> "))
+	}
+}
+
+// findDependencyCycles returns every cycle reachable in the dependency graph,
+// each as a path that starts and ends with the same service ID.
+func findDependencyCycles(graph map[string][]string) [][]string {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	ids := make([]string, 0, len(graph))
+	for id := range graph {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var (
+		cycles [][]string
+		stack  []string
+		state  = make(map[string]int)
+		visit  func(id string)
+	)
+
+	visit = func(id string) {
+		state[id] = visiting
+		stack = append(stack, id)
+
+		for _, dep := range graph[id] {
+			switch state[dep] {
+			case visiting:
+				start := slices.Index(stack, dep)
+				cycle := append(slices.Clone(stack[start:]), dep)
+				cycles = append(cycles, cycle)
+			case unvisited:
+				visit(dep)
+			}
+		}
+
+		stack = stack[:len(stack)-1]
+		state[id] = visited
+	}
+
+	for _, id := range ids {
+		if state[id] == unvisited {
+			visit(id)
+		}
+	}
+
+	return cycles
 }
 
 func (v *validator) validateService(prefix string, svc Service, seenIDs map[string]struct{}) {
@@ -93,17 +216,85 @@ func (v *validator) validateService(prefix string, svc Service, seenIDs map[stri
 		v.addError("%s.name is required", prefix)
 	}
 
+	for _, tag := range svc.Tags {
+		if !idRegex.MatchString(tag) {
+			v.addError("%s.tags entry %q contains invalid characters (use letters, numbers, underscores, or hyphens)", prefix, tag)
+		}
+	}
+
+	for name, link := range svc.Links {
+		u, err := url.Parse(link)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			v.addError("%s.links[%q] must be an http(s) URL (got %q)", prefix, name, link)
+		}
+	}
+
 	switch strings.ToLower(svc.Type) {
 	case string(ServiceTypeHTTP):
 		v.validateHTTPService(prefix, svc)
 	case string(ServiceTypeTCP):
 		v.validateTCPService(prefix, svc)
+	case string(ServiceTypeDomain):
+		v.validateDomainService(prefix, svc)
+	case string(ServiceTypeNTP):
+		v.validateNTPService(prefix, svc)
 	default:
-		v.addError("%s.type must be 'http' or 'tcp' (got %q)", prefix, svc.Type)
+		v.addError("%s.type must be 'http', 'tcp', 'domain' or 'ntp' (got %q)", prefix, svc.Type)
 	}
 
-	v.validateDuration(prefix+".interval", svc.Interval)
+	if svc.IsScheduled() {
+		v.validateServiceSchedule(prefix, svc)
+	} else {
+		v.validateDuration(prefix+".interval", svc.Interval)
+		if svc.Timezone != "" {
+			v.addError("%s.timezone only applies to services with a schedule", prefix)
+		}
+	}
 	v.validateDuration(prefix+".timeout", svc.Timeout)
+	v.validateFailureInterval(prefix, svc)
+}
+
+func (v *validator) validateFailureInterval(prefix string, svc Service) {
+	if svc.FailureInterval == "" {
+		if svc.FailureBackoff != 0 || svc.MaxFailureInterval != "" {
+			v.addError("%s.failure_backoff and max_failure_interval require failure_interval", prefix)
+		}
+		return
+	}
+
+	if d, err := time.ParseDuration(svc.FailureInterval); err != nil || d <= 0 {
+		v.addError("%s.failure_interval must be a positive duration (got %q)", prefix, svc.FailureInterval)
+	} else if interval, err := time.ParseDuration(svc.Interval); err == nil && d > interval {
+		// Slowing down while failing is what failure_backoff is for
+		v.addError("%s.failure_interval must not be longer than interval (%s > %s)", prefix, svc.FailureInterval, svc.Interval)
+	}
+
+	if svc.FailureBackoff == 0 {
+		return
+	}
+	if svc.FailureBackoff <= 1 {
+		v.addError("%s.failure_backoff must be greater than 1 (got %g)", prefix, svc.FailureBackoff)
+	}
+	if svc.MaxFailureInterval == "" {
+		// Interval services default it to their interval
+		if svc.IsScheduled() {
+			v.addError("%s.max_failure_interval is required with failure_backoff on scheduled services", prefix)
+		}
+	} else if d, err := time.ParseDuration(svc.MaxFailureInterval); err != nil || d <= 0 {
+		v.addError("%s.max_failure_interval must be a positive duration (got %q)", prefix, svc.MaxFailureInterval)
+	}
+}
+
+func (v *validator) validateServiceSchedule(prefix string, svc Service) {
+	if svc.Interval != "" {
+		v.addError("%s.interval and schedule are mutually exclusive", prefix)
+	}
+	if _, err := cron.Parse(svc.Schedule); err != nil {
+		v.addError("%s.schedule is invalid: %v", prefix, err)
+	}
+	if _, err := time.LoadLocation(svc.Timezone); err != nil {
+		v.addError("%s.timezone is invalid: %v", prefix, err)
+	}
 }
 
 func (v *validator) validateHTTPService(prefix string, svc Service) {
@@ -121,6 +312,46 @@ func (v *validator) validateTCPService(prefix string, svc Service) {
 	}
 }
 
+func (v *validator) validateDomainService(prefix string, svc Service) {
+	if strings.TrimSpace(svc.Domain) == "" {
+		v.addError("%s.domain is required for type=domain", prefix)
+	}
+
+	switch DomainLookup(svc.Lookup) {
+	case DomainLookupRDAP:
+		u, err := url.Parse(svc.LookupServer)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			v.addError("%s.lookup_server must be an http(s) URL for lookup=rdap (got %q)", prefix, svc.LookupServer)
+		}
+	case DomainLookupWHOIS:
+		if strings.TrimSpace(svc.LookupServer) == "" {
+			v.addError("%s.lookup_server is required for lookup=whois", prefix)
+		}
+	default:
+		v.addError("%s.lookup must be 'rdap' or 'whois' (got %q)", prefix, svc.Lookup)
+	}
+
+	warn, fail := svc.ExpiryWindows()
+	if fail < 0 {
+		v.addError("%s.fail_within_days must not be negative (got %d)", prefix, fail)
+	}
+	if warn < fail {
+		v.addError("%s.warn_within_days must be >= fail_within_days (got %d < %d)", prefix, warn, fail)
+	}
+}
+
+func (v *validator) validateNTPService(prefix string, svc Service) {
+	if svc.Host == "" {
+		v.addError("%s.host is required for type=ntp", prefix)
+	}
+	if svc.Port < MinPort || svc.Port > MaxPort {
+		v.addError("%s.port must be between %d and %d for type=ntp (got %d)", prefix, MinPort, MaxPort, svc.Port)
+	}
+	if d, err := time.ParseDuration(svc.MaxOffset); err != nil || d <= 0 {
+		v.addError("%s.max_offset must be a positive duration for type=ntp (got %q)", prefix, svc.MaxOffset)
+	}
+}
+
 func (v *validator) validateAlerting(alerting AlertingConfig) {
 	v.validateChannels(alerting.Channels)
 	v.validateRoutes(alerting.Routes, alerting.Channels)
@@ -135,6 +366,7 @@ func (v *validator) validateChannels(channels map[string]Channel) {
 
 		prefix := fmt.Sprintf("alerting.channels[%q]", name)
 		v.validateChannel(prefix, name, ch)
+		v.validateMessageTemplates(prefix+".templates", ch.Templates)
 	}
 }
 
@@ -142,14 +374,166 @@ func (v *validator) validateChannel(prefix, name string, ch Channel) {
 	switch ChannelType(strings.ToLower(ch.Type)) {
 	case "":
 		// Empty line is allowed (no alerting)
-	case ChannelTypeDiscord, ChannelTypeSlack:
+	case ChannelTypeDiscord, ChannelTypeSlack, ChannelTypeTeams:
 		if strings.TrimSpace(ch.WebhookURL) == "" {
 			v.addError("%s.webhook_url is required for type=%s", prefix, ch.Type)
 		}
 	case ChannelTypeEmail:
 		v.validateEmailChannel(prefix, name, ch)
+	case ChannelTypePagerDuty:
+		v.validatePagerDutyChannel(prefix, ch)
+	case ChannelTypeOpsgenie:
+		v.validateOpsgenieChannel(prefix, ch)
+	case ChannelTypeTelegram:
+		v.validateTelegramChannel(prefix, ch)
+	case ChannelTypeMatrix:
+		v.validateMatrixChannel(prefix, ch)
+	case ChannelTypeNtfy:
+		v.validateNtfyChannel(prefix, ch)
+	case ChannelTypeGotify:
+		v.validateGotifyChannel(prefix, ch)
+	case ChannelTypeWebhook:
+		v.validateWebhookChannel(prefix, ch)
 	default:
-		v.addError("%s.type must be 'discord', 'slack', or 'email' (got %q)", prefix, ch.Type)
+		v.addError("%s.type must be one of 'discord', 'slack', 'teams', 'email', 'pagerduty', 'opsgenie', 'telegram', 'matrix', 'ntfy', 'gotify', or 'webhook' (got %q)", prefix, ch.Type)
+	}
+}
+
+func (v *validator) validatePagerDutyChannel(prefix string, ch Channel) {
+	if strings.TrimSpace(ch.RoutingKey) == "" {
+		v.addError("%s.routing_key is required for type=pagerduty", prefix)
+	}
+
+	switch PagerDutySeverity(ch.Severity) {
+	case "", PagerDutyCritical, PagerDutyError, PagerDutyWarning, PagerDutyInfo:
+	default:
+		v.addError("%s.severity must be 'critical', 'error', 'warning', or 'info' (got %q)", prefix, ch.Severity)
+	}
+
+	v.validateAPIURL(prefix, ch.APIURL)
+}
+
+func (v *validator) validateOpsgenieChannel(prefix string, ch Channel) {
+	if strings.TrimSpace(ch.APIKey) == "" {
+		v.addError("%s.api_key is required for type=opsgenie", prefix)
+	}
+
+	switch ch.Priority {
+	case "", "P1", "P2", "P3", "P4", "P5":
+	default:
+		v.addError("%s.priority must be one of P1 to P5 (got %q)", prefix, ch.Priority)
+	}
+
+	for i, r := range ch.Responders {
+		rPrefix := fmt.Sprintf("%s.responders[%d]", prefix, i)
+		switch OpsgenieResponderType(r.Type) {
+		case OpsgenieTeam, OpsgenieUser, OpsgenieEscalation, OpsgenieSchedule:
+		default:
+			v.addError("%s.type must be 'team', 'user', 'escalation', or 'schedule' (got %q)", rPrefix, r.Type)
+		}
+		if strings.TrimSpace(r.Name) == "" {
+			v.addError("%s.name is required", rPrefix)
+		}
+	}
+
+	v.validateAPIURL(prefix, ch.APIURL)
+}
+
+func (v *validator) validateTelegramChannel(prefix string, ch Channel) {
+	if strings.TrimSpace(ch.BotToken) == "" {
+		v.addError("%s.bot_token is required for type=telegram", prefix)
+	}
+	if strings.TrimSpace(ch.ChatID) == "" {
+		v.addError("%s.chat_id is required for type=telegram", prefix)
+	}
+	v.validateAPIURL(prefix, ch.APIURL)
+}
+
+func (v *validator) validateMatrixChannel(prefix string, ch Channel) {
+	if ch.APIURL == "" {
+		v.addError("%s.api_url (the homeserver URL) is required for type=matrix", prefix)
+	}
+	if strings.TrimSpace(ch.Token) == "" {
+		v.addError("%s.token is required for type=matrix", prefix)
+	}
+	if !strings.HasPrefix(ch.RoomID, "!") || !strings.Contains(ch.RoomID, ":") {
+		v.addError("%s.room_id must be a room ID like !abc:example.org for type=matrix (got %q)", prefix, ch.RoomID)
+	}
+	v.validateAPIURL(prefix, ch.APIURL)
+}
+
+func (v *validator) validateNtfyChannel(prefix string, ch Channel) {
+	if strings.TrimSpace(ch.Topic) == "" {
+		v.addError("%s.topic is required for type=ntfy", prefix)
+	}
+	if _, ok := NtfyPriorities[ch.Priority]; ch.Priority != "" && !ok {
+		v.addError("%s.priority must be 'min', 'low', 'default', 'high', or 'urgent' for type=ntfy (got %q)", prefix, ch.Priority)
+	}
+	v.validateAPIURL(prefix, ch.APIURL)
+}
+
+func (v *validator) validateGotifyChannel(prefix string, ch Channel) {
+	if ch.APIURL == "" {
+		v.addError("%s.api_url (the server URL) is required for type=gotify", prefix)
+	}
+	if strings.TrimSpace(ch.Token) == "" {
+		v.addError("%s.token is required for type=gotify", prefix)
+	}
+	if p, err := strconv.Atoi(ch.Priority); ch.Priority != "" && (err != nil || p < 0 || p > 10) {
+		v.addError("%s.priority must be between 0 and 10 for type=gotify (got %q)", prefix, ch.Priority)
+	}
+	v.validateAPIURL(prefix, ch.APIURL)
+}
+
+func (v *validator) validateWebhookChannel(prefix string, ch Channel) {
+	u, err := url.Parse(ch.WebhookURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		v.addError("%s.webhook_url must be an http(s) URL for type=webhook (got %q)", prefix, ch.WebhookURL)
+	}
+
+	switch strings.ToUpper(ch.Method) {
+	case "", http.MethodPost, http.MethodPut, http.MethodPatch:
+	default:
+		v.addError("%s.method must be POST, PUT, or PATCH (got %q)", prefix, ch.Method)
+	}
+
+	for name, value := range ch.Headers {
+		if !headerNameRegex.MatchString(name) {
+			v.addError("%s.headers has an invalid name %q", prefix, name)
+			continue
+		}
+		if err := templates.Check(name, value); err != nil {
+			v.addError("%s.headers[%q] is not a valid template: %v", prefix, name, err)
+		}
+	}
+	if err := templates.Check("body", ch.Body); err != nil {
+		v.addError("%s.body is not a valid template: %v", prefix, err)
+	}
+}
+
+func (v *validator) validateMessageTemplates(prefix string, t MessageTemplates) {
+	for _, field := range []struct{ name, text string }{
+		{"message", t.Message},
+		{"subject", t.Subject},
+		{"body", t.Body},
+	} {
+		if err := templates.Check(field.name, field.text); err != nil {
+			v.addError("%s.%s is not a valid template: %v", prefix, field.name, err)
+		}
+	}
+	if err := templates.CheckHTML("html_body", t.HTMLBody); err != nil {
+		v.addError("%s.html_body is not a valid template: %v", prefix, err)
+	}
+}
+
+// validateAPIURL checks a channel's api_url override, if set.
+func (v *validator) validateAPIURL(prefix, apiURL string) {
+	if apiURL == "" {
+		return
+	}
+	u, err := url.Parse(apiURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		v.addError("%s.api_url must be an http(s) URL (got %q)", prefix, apiURL)
 	}
 }
 
@@ -191,6 +575,235 @@ func (v *validator) validateRoutes(routes []Route, channels map[string]Channel)
 		if r.Policy.Cooldown != "" {
 			v.validateDuration(prefix+".policy.cooldown", r.Policy.Cooldown)
 		}
+		v.validateMessageTemplates(prefix+".templates", r.Templates)
+	}
+}
+
+func (v *validator) validateMaintenance(windows []MaintenanceWindow, services []Service) {
+	known := make(map[string]struct{}, len(services))
+	for _, svc := range services {
+		known[svc.ID] = struct{}{}
+	}
+
+	for i, w := range windows {
+		prefix := fmt.Sprintf("maintenance[%d]", i)
+
+		if strings.TrimSpace(w.Name) == "" {
+			v.addError("%s.name is required", prefix)
+		}
+
+		if len(w.Match.ServiceIDs) == 0 && len(w.Match.Tags) == 0 {
+			v.addError("%s.match must list at least one service_id or tag", prefix)
+		}
+		for _, id := range w.Match.ServiceIDs {
+			if _, exists := known[id]; !exists {
+				v.addError("%s.match.service_ids references unknown service %q", prefix, id)
+			}
+		}
+
+		if w.IsRecurring() {
+			v.validateRecurringWindow(prefix, w)
+		} else {
+			v.validateOneOffWindow(prefix, w)
+		}
+	}
+}
+
+func (v *validator) validateLimits(limits []Limit) {
+	seen := make(map[string]struct{}, len(limits))
+
+	for i, l := range limits {
+		prefix := fmt.Sprintf("limits[%d]", i)
+
+		if strings.TrimSpace(l.Name) == "" {
+			v.addError("%s.name is required", prefix)
+		} else if _, dup := seen[l.Name]; dup {
+			v.addError("%s.name %q is duplicated", prefix, l.Name)
+		}
+		seen[l.Name] = struct{}{}
+
+		if len(l.Match.Hosts) == 0 && len(l.Match.Tags) == 0 {
+			v.addError("%s.match must list at least one host or tag", prefix)
+		}
+		for _, host := range l.Match.Hosts {
+			if strings.TrimSpace(host) == "" {
+				v.addError("%s.match.hosts contains an empty host", prefix)
+			}
+		}
+
+		if l.MaxConcurrent < 0 {
+			v.addError("%s.max_concurrent must not be negative (got %d)", prefix, l.MaxConcurrent)
+		}
+		if l.RPS < 0 {
+			v.addError("%s.rps must not be negative (got %g)", prefix, l.RPS)
+		}
+		if l.MaxConcurrent == 0 && l.RPS == 0 {
+			v.addError("%s must set max_concurrent or rps", prefix)
+		}
+	}
+}
+
+func (v *validator) validateProbing(probing ProbingConfig) {
+	mode := ProbeMode(probing.Mode)
+	switch mode {
+	case ProbeModeStandalone:
+		return
+	case ProbeModePrimary, ProbeModeProbe:
+	default:
+		v.addError("probing.mode must be 'primary' or 'probe' (got %q)", probing.Mode)
+		return
+	}
+
+	if !isSafeID(probing.Location) {
+		v.addError("probing.location must be a non-empty identifier (got %q)", probing.Location)
+	}
+	if strings.TrimSpace(probing.Token) == "" {
+		v.addError("probing.token is required in %s mode", mode)
+	}
+
+	if mode == ProbeModeProbe {
+		u, err := url.Parse(probing.Primary)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			v.addError("probing.primary must be an http(s) URL (got %q)", probing.Primary)
+		}
+		return
+	}
+
+	if probing.Quorum < 0 {
+		v.addError("probing.quorum must not be negative (got %d)", probing.Quorum)
+	}
+	if probing.StaleAfter != "" {
+		v.validateDuration("probing.stale_after", probing.StaleAfter)
+	}
+}
+
+func (v *validator) validateCluster(cluster ClusterConfig, probing ProbingConfig, services []Service) {
+	if !cluster.Enabled() {
+		if cluster.Self != "" {
+			v.addError("cluster.peers is required when cluster.self is set")
+		}
+		return
+	}
+
+	if ProbeMode(probing.Mode) != ProbeModeStandalone {
+		v.addError("cluster cannot be combined with probing")
+	}
+	if strings.TrimSpace(cluster.Token) == "" {
+		v.addError("cluster.token is required")
+	}
+
+	// Dependency state is not shared between members, and a parent may be
+	// checked on another one
+	for i, svc := range services {
+		if len(svc.DependsOn) > 0 {
+			v.addError("services[%d].depends_on cannot be combined with cluster", i)
+		}
+	}
+
+	seen := make(map[string]struct{}, len(cluster.Peers))
+	for i, peer := range cluster.Peers {
+		prefix := fmt.Sprintf("cluster.peers[%d]", i)
+
+		if !isSafeID(peer.Name) {
+			v.addError("%s.name must be a non-empty identifier (got %q)", prefix, peer.Name)
+		} else if _, dup := seen[peer.Name]; dup {
+			v.addError("%s.name %q is duplicated", prefix, peer.Name)
+		}
+		seen[peer.Name] = struct{}{}
+
+		u, err := url.Parse(peer.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			v.addError("%s.url must be an http(s) URL (got %q)", prefix, peer.URL)
+		}
+	}
+
+	if _, ok := seen[cluster.Self]; !ok {
+		v.addError("cluster.self %q must name one of cluster.peers", cluster.Self)
+	}
+
+	var interval, timeout time.Duration
+	if cluster.HeartbeatInterval != "" {
+		v.validateDuration("cluster.heartbeat_interval", cluster.HeartbeatInterval)
+		interval, _ = time.ParseDuration(cluster.HeartbeatInterval)
+	}
+	if cluster.PeerTimeout != "" {
+		v.validateDuration("cluster.peer_timeout", cluster.PeerTimeout)
+		timeout, _ = time.ParseDuration(cluster.PeerTimeout)
+	}
+	if interval > 0 && timeout > 0 && timeout <= interval {
+		v.addError("cluster.peer_timeout (%s) must be longer than cluster.heartbeat_interval (%s)", cluster.PeerTimeout, cluster.HeartbeatInterval)
+	}
+}
+
+func (v *validator) validateHA(ha HAConfig, cluster ClusterConfig, probing ProbingConfig) {
+	if !ha.Enabled() {
+		if ha.Name != "" {
+			v.addError("ha.peer_url is required when ha.name is set")
+		}
+		return
+	}
+
+	if cluster.Enabled() {
+		v.addError("ha cannot be combined with cluster")
+	}
+	if ProbeMode(probing.Mode) != ProbeModeStandalone {
+		v.addError("ha cannot be combined with probing")
+	}
+	if !isSafeID(ha.Name) {
+		v.addError("ha.name must be a non-empty identifier (got %q)", ha.Name)
+	}
+	if strings.TrimSpace(ha.Token) == "" {
+		v.addError("ha.token is required")
+	}
+
+	u, err := url.Parse(ha.PeerURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		v.addError("ha.peer_url must be an http(s) URL (got %q)", ha.PeerURL)
+	}
+
+	var lease, renew time.Duration
+	if ha.LeaseDuration != "" {
+		v.validateDuration("ha.lease_duration", ha.LeaseDuration)
+		lease, _ = time.ParseDuration(ha.LeaseDuration)
+	}
+	if ha.RenewInterval != "" {
+		v.validateDuration("ha.renew_interval", ha.RenewInterval)
+		renew, _ = time.ParseDuration(ha.RenewInterval)
+	}
+	if lease > 0 && renew > 0 && lease <= renew {
+		v.addError("ha.lease_duration (%s) must be longer than ha.renew_interval (%s)", ha.LeaseDuration, ha.RenewInterval)
+	}
+}
+
+func (v *validator) validateRecurringWindow(prefix string, w MaintenanceWindow) {
+	if w.Start != "" || w.End != "" {
+		v.addError("%s cannot combine schedule with start/end", prefix)
+	}
+	if _, err := cron.Parse(w.Schedule); err != nil {
+		v.addError("%s.schedule is invalid: %v", prefix, err)
+	}
+	if d, err := time.ParseDuration(w.Duration); err != nil || d <= 0 {
+		v.addError("%s.duration must be a positive duration for a recurring window (got %q)", prefix, w.Duration)
+	}
+	if _, err := time.LoadLocation(w.Timezone); err != nil {
+		v.addError("%s.timezone is invalid: %v", prefix, err)
+	}
+}
+
+func (v *validator) validateOneOffWindow(prefix string, w MaintenanceWindow) {
+	start, startErr := time.Parse(time.RFC3339, w.Start)
+	if startErr != nil {
+		v.addError("%s.start must be an RFC 3339 timestamp (got %q)", prefix, w.Start)
+	}
+	end, endErr := time.Parse(time.RFC3339, w.End)
+	if endErr != nil {
+		v.addError("%s.end must be an RFC 3339 timestamp (got %q)", prefix, w.End)
+	}
+	if startErr == nil && endErr == nil && !end.After(start) {
+		v.addError("%s.end must be after start", prefix)
+	}
+	if w.Duration != "" || w.Timezone != "" {
+		v.addError("%s.duration and timezone only apply to recurring windows (set schedule)", prefix)
 	}
 }
 
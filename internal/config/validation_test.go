@@ -94,6 +94,81 @@ func TestValidateGlobal_InvalidDurations(t *testing.T) {
 	}
 }
 
+func TestValidateGlobal_Spread(t *testing.T) {
+	tests := []struct {
+		spread     string
+		shouldFail bool
+	}{
+		{"random", false},
+		{"hash", false},
+		{"round-robin", true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.spread, func(t *testing.T) {
+			cfg := &Config{
+				Global: GlobalConfig{
+					ScrapeBind:      "0.0.0.0:8080",
+					LogLevel:        "info",
+					DefaultTimeout:  "5s",
+					DefaultInterval: "30s",
+					WorkerCount:     10,
+					Jitter:          "0s",
+					Spread:          tc.spread,
+				},
+			}
+
+			err := cfg.Validate()
+			if tc.shouldFail && (err == nil || !strings.Contains(err.Error(), "global.spread")) {
+				t.Errorf("expected global.spread error, got %v", err)
+			}
+			if !tc.shouldFail && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateGlobal_Shutdown(t *testing.T) {
+	tests := []struct {
+		name      string
+		shutdown  ShutdownConfig
+		wantError string
+	}{
+		{name: "unset", shutdown: ShutdownConfig{}},
+		{name: "valid", shutdown: ShutdownConfig{DrainTimeout: "45s", AlertTimeout: "5s", ServerTimeout: "0s"}},
+		{name: "invalid drain", shutdown: ShutdownConfig{DrainTimeout: "soon"}, wantError: "global.shutdown.drain_timeout must be a valid duration"},
+		{name: "negative server", shutdown: ShutdownConfig{ServerTimeout: "-1s"}, wantError: "global.shutdown.server_timeout must not be negative"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &Config{
+				Global: GlobalConfig{
+					ScrapeBind:      "0.0.0.0:8080",
+					LogLevel:        "info",
+					DefaultTimeout:  "5s",
+					DefaultInterval: "30s",
+					WorkerCount:     10,
+					Jitter:          "0s",
+					Shutdown:        tc.shutdown,
+				},
+			}
+
+			err := cfg.Validate()
+			if tc.wantError == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantError) {
+				t.Errorf("error = %v, want it to contain %q", err, tc.wantError)
+			}
+		})
+	}
+}
+
 func TestValidateGlobal_WorkerCountBounds(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -190,17 +265,811 @@ func TestValidateService_HTTPService(t *testing.T) {
 			errContain: "name",
 		},
 		{
-			name: "invalid id characters",
-			service: Service{
-				ID:       "web@1!",
-				Name:     "Web Server",
-				Type:     "http",
-				URL:      "https://example.com",
-				Interval: "30s",
-				Timeout:  "5s",
+			name: "invalid id characters",
+			service: Service{
+				ID:       "web@1!",
+				Name:     "Web Server",
+				Type:     "http",
+				URL:      "https://example.com",
+				Interval: "30s",
+				Timeout:  "5s",
+			},
+			shouldFail: true,
+			errContain: "invalid characters",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &Config{
+				Global: GlobalConfig{
+					ScrapeBind:      "0.0.0.0:8080",
+					LogLevel:        "info",
+					DefaultTimeout:  "5s",
+					DefaultInterval: "30s",
+					WorkerCount:     10,
+					Jitter:          "0s",
+				},
+				Services: []Service{tc.service},
+			}
+
+			err := cfg.Validate()
+			if tc.shouldFail && err == nil {
+				t.Error("expected validation error")
+			}
+			if !tc.shouldFail && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if tc.shouldFail && err != nil && tc.errContain != "" {
+				if !strings.Contains(strings.ToLower(err.Error()), tc.errContain) {
+					t.Errorf("error should contain %q: %v", tc.errContain, err)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateService_TCPService(t *testing.T) {
+	tests := []struct {
+		name       string
+		service    Service
+		shouldFail bool
+		errContain string
+	}{
+		{
+			name: "valid tcp service",
+			service: Service{
+				ID:       "db-1",
+				Name:     "Database",
+				Type:     "tcp",
+				Host:     "localhost",
+				Port:     5432,
+				Interval: "30s",
+				Timeout:  "5s",
+			},
+			shouldFail: false,
+		},
+		{
+			name: "missing host",
+			service: Service{
+				ID:       "db-1",
+				Name:     "Database",
+				Type:     "tcp",
+				Host:     "",
+				Port:     5432,
+				Interval: "30s",
+				Timeout:  "5s",
+			},
+			shouldFail: true,
+			errContain: "host",
+		},
+		{
+			name: "port too low",
+			service: Service{
+				ID:       "db-1",
+				Name:     "Database",
+				Type:     "tcp",
+				Host:     "localhost",
+				Port:     0,
+				Interval: "30s",
+				Timeout:  "5s",
+			},
+			shouldFail: true,
+			errContain: "port",
+		},
+		{
+			name: "port too high",
+			service: Service{
+				ID:       "db-1",
+				Name:     "Database",
+				Type:     "tcp",
+				Host:     "localhost",
+				Port:     65536,
+				Interval: "30s",
+				Timeout:  "5s",
+			},
+			shouldFail: true,
+			errContain: "port",
+		},
+		{
+			name: "max valid port",
+			service: Service{
+				ID:       "db-1",
+				Name:     "Database",
+				Type:     "tcp",
+				Host:     "localhost",
+				Port:     65535,
+				Interval: "30s",
+				Timeout:  "5s",
+			},
+			shouldFail: false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &Config{
+				Global: GlobalConfig{
+					ScrapeBind:      "0.0.0.0:8080",
+					LogLevel:        "info",
+					DefaultTimeout:  "5s",
+					DefaultInterval: "30s",
+					WorkerCount:     10,
+					Jitter:          "0s",
+				},
+				Services: []Service{tc.service},
+			}
+
+			err := cfg.Validate()
+			if tc.shouldFail && err == nil {
+				t.Error("expected validation error")
+			}
+			if !tc.shouldFail && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if tc.shouldFail && err != nil && tc.errContain != "" {
+				if !strings.Contains(strings.ToLower(err.Error()), tc.errContain) {
+					t.Errorf("error should contain %q: %v", tc.errContain, err)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateService_DomainService(t *testing.T) {
+	base := Service{
+		ID:             "example-domain",
+		Name:           "Example Domain",
+		Type:           "domain",
+		Domain:         "example.com",
+		Lookup:         "rdap",
+		LookupServer:   "https://rdap.org",
+		WarnWithinDays: intPtr(30),
+		FailWithinDays: intPtr(7),
+		Interval:       "24h",
+		Timeout:        "10s",
+	}
+
+	tests := []struct {
+		name       string
+		mutate     func(*Service)
+		shouldFail bool
+		errContain string
+	}{
+		{
+			name:       "valid rdap service",
+			mutate:     func(s *Service) {},
+			shouldFail: false,
+		},
+		{
+			name: "valid whois service",
+			mutate: func(s *Service) {
+				s.Lookup = "whois"
+				s.LookupServer = "whois.verisign-grs.com"
+			},
+			shouldFail: false,
+		},
+		{
+			name:       "missing domain",
+			mutate:     func(s *Service) { s.Domain = "" },
+			shouldFail: true,
+			errContain: "domain is required",
+		},
+		{
+			name:       "invalid lookup",
+			mutate:     func(s *Service) { s.Lookup = "dns" },
+			shouldFail: true,
+			errContain: "lookup must be",
+		},
+		{
+			name:       "rdap server not a url",
+			mutate:     func(s *Service) { s.LookupServer = "rdap.org" },
+			shouldFail: true,
+			errContain: "lookup_server",
+		},
+		{
+			name: "whois without server",
+			mutate: func(s *Service) {
+				s.Lookup = "whois"
+				s.LookupServer = ""
+			},
+			shouldFail: true,
+			errContain: "lookup_server is required",
+		},
+		{
+			name:       "warn window shorter than fail window",
+			mutate:     func(s *Service) { s.WarnWithinDays = intPtr(3) },
+			shouldFail: true,
+			errContain: "warn_within_days",
+		},
+		{
+			name:       "negative fail window",
+			mutate:     func(s *Service) { s.FailWithinDays = intPtr(-1) },
+			shouldFail: true,
+			errContain: "fail_within_days",
+		},
+		{
+			name: "fail window longer than default warn window",
+			mutate: func(s *Service) {
+				s.WarnWithinDays = nil
+				s.FailWithinDays = intPtr(45)
+			},
+			shouldFail: false,
+		},
+		{
+			name: "fail only once expired",
+			mutate: func(s *Service) {
+				s.WarnWithinDays = intPtr(0)
+				s.FailWithinDays = intPtr(0)
+			},
+			shouldFail: false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := base
+			tc.mutate(&svc)
+
+			cfg := &Config{
+				Global: GlobalConfig{
+					ScrapeBind:      "0.0.0.0:8080",
+					LogLevel:        "info",
+					DefaultTimeout:  "5s",
+					DefaultInterval: "30s",
+					WorkerCount:     10,
+					Jitter:          "0s",
+				},
+				Services: []Service{svc},
+			}
+
+			err := cfg.Validate()
+			if tc.shouldFail && err == nil {
+				t.Error("expected validation error")
+			}
+			if !tc.shouldFail && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if tc.shouldFail && err != nil && tc.errContain != "" {
+				if !strings.Contains(strings.ToLower(err.Error()), tc.errContain) {
+					t.Errorf("error should contain %q: %v", tc.errContain, err)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateService_NTPService(t *testing.T) {
+	tests := []struct {
+		name       string
+		service    Service
+		shouldFail bool
+		errContain string
+	}{
+		{
+			name:       "valid ntp service",
+			service:    Service{ID: "ntp-1", Name: "NTP", Type: "ntp", Host: "pool.ntp.org", Port: 123, MaxOffset: "500ms", Interval: "1m", Timeout: "5s"},
+			shouldFail: false,
+		},
+		{
+			name:       "missing host",
+			service:    Service{ID: "ntp-1", Name: "NTP", Type: "ntp", Port: 123, MaxOffset: "500ms", Interval: "1m", Timeout: "5s"},
+			shouldFail: true,
+			errContain: "host is required for type=ntp",
+		},
+		{
+			name:       "invalid port",
+			service:    Service{ID: "ntp-1", Name: "NTP", Type: "ntp", Host: "pool.ntp.org", Port: 70000, MaxOffset: "500ms", Interval: "1m", Timeout: "5s"},
+			shouldFail: true,
+			errContain: "port must be between",
+		},
+		{
+			name:       "invalid max_offset",
+			service:    Service{ID: "ntp-1", Name: "NTP", Type: "ntp", Host: "pool.ntp.org", Port: 123, MaxOffset: "soon", Interval: "1m", Timeout: "5s"},
+			shouldFail: true,
+			errContain: "max_offset",
+		},
+		{
+			name:       "zero max_offset",
+			service:    Service{ID: "ntp-1", Name: "NTP", Type: "ntp", Host: "pool.ntp.org", Port: 123, MaxOffset: "0s", Interval: "1m", Timeout: "5s"},
+			shouldFail: true,
+			errContain: "max_offset",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &Config{
+				Global: GlobalConfig{
+					ScrapeBind:      "0.0.0.0:8080",
+					LogLevel:        "info",
+					DefaultTimeout:  "5s",
+					DefaultInterval: "30s",
+					WorkerCount:     10,
+					Jitter:          "0s",
+				},
+				Services: []Service{tc.service},
+			}
+
+			err := cfg.Validate()
+			if tc.shouldFail && err == nil {
+				t.Error("expected validation error")
+			}
+			if !tc.shouldFail && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if tc.shouldFail && err != nil && tc.errContain != "" {
+				if !strings.Contains(strings.ToLower(err.Error()), tc.errContain) {
+					t.Errorf("error should contain %q: %v", tc.errContain, err)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateService_Schedule(t *testing.T) {
+	tests := []struct {
+		name       string
+		service    Service
+		shouldFail bool
+		errContain string
+	}{
+		{
+			name:    "valid five-field schedule",
+			service: Service{ID: "web-1", Name: "Web", Type: "http", URL: "https://example.com", Schedule: "*/5 9-17 * * mon-fri", Timeout: "5s"},
+		},
+		{
+			name:    "valid six-field schedule with timezone",
+			service: Service{ID: "web-1", Name: "Web", Type: "http", URL: "https://example.com", Schedule: "30 0 2 * * *", Timezone: "Europe/Berlin", Timeout: "5s"},
+		},
+		{
+			name:    "valid descriptor",
+			service: Service{ID: "web-1", Name: "Web", Type: "http", URL: "https://example.com", Schedule: "@hourly", Timeout: "5s"},
+		},
+		{
+			name:       "schedule with interval",
+			service:    Service{ID: "web-1", Name: "Web", Type: "http", URL: "https://example.com", Schedule: "@hourly", Interval: "30s", Timeout: "5s"},
+			shouldFail: true,
+			errContain: "interval and schedule are mutually exclusive",
+		},
+		{
+			name:       "invalid schedule",
+			service:    Service{ID: "web-1", Name: "Web", Type: "http", URL: "https://example.com", Schedule: "61 * * * *", Timeout: "5s"},
+			shouldFail: true,
+			errContain: "schedule is invalid",
+		},
+		{
+			name:       "invalid timezone",
+			service:    Service{ID: "web-1", Name: "Web", Type: "http", URL: "https://example.com", Schedule: "@hourly", Timezone: "Mars/Olympus", Timeout: "5s"},
+			shouldFail: true,
+			errContain: "timezone is invalid",
+		},
+		{
+			name:       "timezone without schedule",
+			service:    Service{ID: "web-1", Name: "Web", Type: "http", URL: "https://example.com", Interval: "30s", Timezone: "UTC", Timeout: "5s"},
+			shouldFail: true,
+			errContain: "timezone only applies",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &Config{
+				Global: GlobalConfig{
+					ScrapeBind:      "0.0.0.0:8080",
+					LogLevel:        "info",
+					DefaultTimeout:  "5s",
+					DefaultInterval: "30s",
+					WorkerCount:     10,
+					Jitter:          "0s",
+				},
+				Services: []Service{tc.service},
+			}
+
+			err := cfg.Validate()
+			if tc.shouldFail && err == nil {
+				t.Error("expected validation error")
+			}
+			if !tc.shouldFail && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if tc.shouldFail && err != nil && tc.errContain != "" {
+				if !strings.Contains(strings.ToLower(err.Error()), tc.errContain) {
+					t.Errorf("error should contain %q: %v", tc.errContain, err)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateService_FailureInterval(t *testing.T) {
+	base := Service{ID: "web-1", Name: "Web", Type: "http", URL: "https://example.com", Interval: "5m", Timeout: "5s"}
+	with := func(mod func(*Service)) Service {
+		svc := base
+		mod(&svc)
+		return svc
+	}
+
+	tests := []struct {
+		name       string
+		service    Service
+		shouldFail bool
+		errContain string
+	}{
+		{
+			name:    "failure interval only",
+			service: with(func(s *Service) { s.FailureInterval = "30s" }),
+		},
+		{
+			name: "with backoff",
+			service: with(func(s *Service) {
+				s.FailureInterval, s.FailureBackoff, s.MaxFailureInterval = "30s", 2, "10m"
+			}),
+		},
+		{
+			name:       "failure interval longer than interval",
+			service:    with(func(s *Service) { s.FailureInterval = "10m" }),
+			shouldFail: true,
+			errContain: "must not be longer than interval",
+		},
+		{
+			name:       "invalid failure interval",
+			service:    with(func(s *Service) { s.FailureInterval = "0s" }),
+			shouldFail: true,
+			errContain: "failure_interval must be a positive duration",
+		},
+		{
+			name:       "backoff without failure interval",
+			service:    with(func(s *Service) { s.FailureBackoff = 2 }),
+			shouldFail: true,
+			errContain: "require failure_interval",
+		},
+		{
+			name: "backoff too small",
+			service: with(func(s *Service) {
+				s.FailureInterval, s.FailureBackoff, s.MaxFailureInterval = "30s", 0.5, "10m"
+			}),
+			shouldFail: true,
+			errContain: "failure_backoff must be greater than 1",
+		},
+		{
+			name: "interval service defaults max failure interval",
+			service: with(func(s *Service) {
+				s.FailureInterval, s.FailureBackoff = "30s", 2
+			}),
+		},
+		{
+			name: "scheduled service needs max failure interval",
+			service: with(func(s *Service) {
+				s.Interval, s.Schedule = "", "@hourly"
+				s.FailureInterval, s.FailureBackoff = "30s", 2
+			}),
+			shouldFail: true,
+			errContain: "max_failure_interval is required",
+		},
+		{
+			name: "invalid max failure interval",
+			service: with(func(s *Service) {
+				s.FailureInterval, s.FailureBackoff, s.MaxFailureInterval = "30s", 2, "later"
+			}),
+			shouldFail: true,
+			errContain: "max_failure_interval must be a positive duration",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &Config{
+				Global: GlobalConfig{
+					ScrapeBind:      "0.0.0.0:8080",
+					LogLevel:        "info",
+					DefaultTimeout:  "5s",
+					DefaultInterval: "30s",
+					WorkerCount:     10,
+					Jitter:          "0s",
+				},
+				Services: []Service{tc.service},
+			}
+
+			err := cfg.Validate()
+			if tc.shouldFail && err == nil {
+				t.Error("expected validation error")
+			}
+			if !tc.shouldFail && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if tc.shouldFail && err != nil && tc.errContain != "" {
+				if !strings.Contains(strings.ToLower(err.Error()), tc.errContain) {
+					t.Errorf("error should contain %q: %v", tc.errContain, err)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateService_Dependencies(t *testing.T) {
+	svc := func(id string, deps ...string) Service {
+		return Service{ID: id, Name: id, Type: "tcp", Host: "localhost", Port: 80, Interval: "30s", Timeout: "5s", DependsOn: deps}
+	}
+
+	tests := []struct {
+		name       string
+		services   []Service
+		shouldFail bool
+		errContain string
+	}{
+		{
+			name:       "valid chain",
+			services:   []Service{svc("router"), svc("db", "router"), svc("api", "db", "router")},
+			shouldFail: false,
+		},
+		{
+			name:       "unknown dependency",
+			services:   []Service{svc("api", "db")},
+			shouldFail: true,
+			errContain: `references unknown service "db"`,
+		},
+		{
+			name:       "self dependency",
+			services:   []Service{svc("api", "api")},
+			shouldFail: true,
+			errContain: "cannot reference itself",
+		},
+		{
+			name:       "two service cycle",
+			services:   []Service{svc("a", "b"), svc("b", "a")},
+			shouldFail: true,
+			errContain: "cycle detected: a -> b -> a",
+		},
+		{
+			name:       "longer cycle",
+			services:   []Service{svc("a", "b"), svc("b", "c"), svc("c", "a"), svc("d", "a")},
+			shouldFail: true,
+			errContain: "cycle detected: a -> b -> c -> a",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &Config{
+				Global: GlobalConfig{
+					ScrapeBind:      "0.0.0.0:8080",
+					LogLevel:        "info",
+					DefaultTimeout:  "5s",
+					DefaultInterval: "30s",
+					WorkerCount:     10,
+					Jitter:          "0s",
+				},
+				Services: tc.services,
+			}
+
+			err := cfg.Validate()
+			if tc.shouldFail && err == nil {
+				t.Error("expected validation error")
+			}
+			if !tc.shouldFail && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if tc.shouldFail && err != nil && tc.errContain != "" {
+				if !strings.Contains(err.Error(), tc.errContain) {
+					t.Errorf("error should contain %q: %v", tc.errContain, err)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateLimits(t *testing.T) {
+	tests := []struct {
+		name       string
+		limits     []Limit
+		errContain string
+	}{
+		{
+			name: "valid host and tag limits",
+			limits: []Limit{
+				{Name: "gw", Match: LimitMatch{Hosts: []string{"api.example.com"}}, MaxConcurrent: 4, RPS: 5},
+				{Name: "web", Match: LimitMatch{Tags: []string{"web"}}, RPS: 0.5},
+			},
+		},
+		{
+			name:       "missing name",
+			limits:     []Limit{{Match: LimitMatch{Tags: []string{"web"}}, MaxConcurrent: 1}},
+			errContain: "limits[0].name is required",
+		},
+		{
+			name: "duplicate name",
+			limits: []Limit{
+				{Name: "gw", Match: LimitMatch{Tags: []string{"a"}}, MaxConcurrent: 1},
+				{Name: "gw", Match: LimitMatch{Tags: []string{"b"}}, MaxConcurrent: 1},
+			},
+			errContain: "is duplicated",
+		},
+		{
+			name:       "empty match",
+			limits:     []Limit{{Name: "gw", MaxConcurrent: 1}},
+			errContain: "at least one host or tag",
+		},
+		{
+			name:       "no limit set",
+			limits:     []Limit{{Name: "gw", Match: LimitMatch{Tags: []string{"web"}}}},
+			errContain: "must set max_concurrent or rps",
+		},
+		{
+			name:       "negative rps",
+			limits:     []Limit{{Name: "gw", Match: LimitMatch{Tags: []string{"web"}}, RPS: -1}},
+			errContain: "rps must not be negative",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &Config{
+				Global: GlobalConfig{
+					ScrapeBind:      "0.0.0.0:8080",
+					LogLevel:        "info",
+					DefaultTimeout:  "5s",
+					DefaultInterval: "30s",
+					WorkerCount:     10,
+					Jitter:          "0s",
+				},
+				Limits: tc.limits,
+			}
+
+			err := cfg.Validate()
+			if tc.errContain == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.errContain) {
+				t.Errorf("error should contain %q: %v", tc.errContain, err)
+			}
+		})
+	}
+}
+
+func TestValidateProbing(t *testing.T) {
+	tests := []struct {
+		name       string
+		probing    ProbingConfig
+		errContain string
+	}{
+		{name: "standalone", probing: ProbingConfig{}},
+		{
+			name:    "valid primary",
+			probing: ProbingConfig{Mode: "primary", Location: "eu-west", Token: "s3cret", Quorum: 2, StaleAfter: "5m"},
+		},
+		{
+			name:    "valid probe",
+			probing: ProbingConfig{Mode: "probe", Location: "us-east", Token: "s3cret", Primary: "http://primary:8080"},
+		},
+		{
+			name:       "unknown mode",
+			probing:    ProbingConfig{Mode: "leader"},
+			errContain: "probing.mode must be 'primary' or 'probe'",
+		},
+		{
+			name:       "missing location",
+			probing:    ProbingConfig{Mode: "primary", Token: "s3cret"},
+			errContain: "probing.location must be a non-empty identifier",
+		},
+		{
+			name:       "missing token",
+			probing:    ProbingConfig{Mode: "primary", Location: "eu-west"},
+			errContain: "probing.token is required in primary mode",
+		},
+		{
+			name:       "probe without primary",
+			probing:    ProbingConfig{Mode: "probe", Location: "us-east", Token: "s3cret"},
+			errContain: "probing.primary must be an http(s) URL",
+		},
+		{
+			name:       "negative quorum",
+			probing:    ProbingConfig{Mode: "primary", Location: "eu-west", Token: "s3cret", Quorum: -1},
+			errContain: "probing.quorum must not be negative",
+		},
+		{
+			name:       "invalid stale_after",
+			probing:    ProbingConfig{Mode: "primary", Location: "eu-west", Token: "s3cret", StaleAfter: "later"},
+			errContain: "probing.stale_after must be a valid duration",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &Config{
+				Global: GlobalConfig{
+					ScrapeBind:      "0.0.0.0:8080",
+					LogLevel:        "info",
+					DefaultTimeout:  "5s",
+					DefaultInterval: "30s",
+					WorkerCount:     10,
+					Jitter:          "0s",
+				},
+				Probing: tc.probing,
+			}
+
+			err := cfg.Validate()
+			if tc.errContain == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.errContain) {
+				t.Errorf("error should contain %q: %v", tc.errContain, err)
+			}
+		})
+	}
+}
+
+func TestValidateCluster(t *testing.T) {
+	peers := []ClusterPeer{
+		{Name: "a", URL: "http://10.0.0.1:8080"},
+		{Name: "b", URL: "http://10.0.0.2:8080"},
+	}
+
+	tests := []struct {
+		name       string
+		cluster    ClusterConfig
+		probing    ProbingConfig
+		services   []Service
+		errContain string
+	}{
+		{name: "disabled", cluster: ClusterConfig{}},
+		{
+			name:    "valid",
+			cluster: ClusterConfig{Self: "a", Peers: peers, Token: "s3cret", HeartbeatInterval: "5s", PeerTimeout: "15s"},
+		},
+		{
+			name:       "self without peers",
+			cluster:    ClusterConfig{Self: "a"},
+			errContain: "cluster.peers is required",
+		},
+		{
+			name:       "self not a peer",
+			cluster:    ClusterConfig{Self: "c", Peers: peers, Token: "s3cret"},
+			errContain: `cluster.self "c" must name one of cluster.peers`,
+		},
+		{
+			name:       "missing token",
+			cluster:    ClusterConfig{Self: "a", Peers: peers},
+			errContain: "cluster.token is required",
+		},
+		{
+			name: "duplicate peer",
+			cluster: ClusterConfig{Self: "a", Token: "s3cret", Peers: []ClusterPeer{
+				{Name: "a", URL: "http://10.0.0.1:8080"},
+				{Name: "a", URL: "http://10.0.0.2:8080"},
+			}},
+			errContain: `cluster.peers[1].name "a" is duplicated`,
+		},
+		{
+			name:       "bad peer url",
+			cluster:    ClusterConfig{Self: "a", Token: "s3cret", Peers: []ClusterPeer{{Name: "a", URL: "10.0.0.1:8080"}}},
+			errContain: "cluster.peers[0].url must be an http(s) URL",
+		},
+		{
+			name:       "timeout not above interval",
+			cluster:    ClusterConfig{Self: "a", Peers: peers, Token: "s3cret", HeartbeatInterval: "10s", PeerTimeout: "10s"},
+			errContain: "must be longer than cluster.heartbeat_interval",
+		},
+		{
+			name:       "combined with probing",
+			cluster:    ClusterConfig{Self: "a", Peers: peers, Token: "s3cret"},
+			probing:    ProbingConfig{Mode: "primary", Location: "eu", Token: "s3cret"},
+			errContain: "cluster cannot be combined with probing",
+		},
+		{
+			name:    "depends_on",
+			cluster: ClusterConfig{Self: "a", Peers: peers, Token: "s3cret"},
+			services: []Service{
+				{ID: "db", Name: "DB", Type: "tcp", Host: "db", Port: 5432},
+				{ID: "app", Name: "App", Type: "tcp", Host: "app", Port: 80, DependsOn: []string{"db"}},
 			},
-			shouldFail: true,
-			errContain: "invalid characters",
+			errContain: "services[1].depends_on cannot be combined with cluster",
 		},
 	}
 
@@ -215,99 +1084,104 @@ func TestValidateService_HTTPService(t *testing.T) {
 					WorkerCount:     10,
 					Jitter:          "0s",
 				},
-				Services: []Service{tc.service},
+				Services: tc.services,
+				Cluster:  tc.cluster,
+				Probing:  tc.probing,
 			}
 
 			err := cfg.Validate()
-			if tc.shouldFail && err == nil {
-				t.Error("expected validation error")
-			}
-			if !tc.shouldFail && err != nil {
-				t.Errorf("unexpected error: %v", err)
-			}
-			if tc.shouldFail && err != nil && tc.errContain != "" {
-				if !strings.Contains(strings.ToLower(err.Error()), tc.errContain) {
-					t.Errorf("error should contain %q: %v", tc.errContain, err)
+			if tc.errContain == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
 				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.errContain) {
+				t.Errorf("error should contain %q: %v", tc.errContain, err)
 			}
 		})
 	}
 }
 
-func TestValidateService_TCPService(t *testing.T) {
+func TestValidateMaintenance(t *testing.T) {
 	tests := []struct {
 		name       string
-		service    Service
+		window     MaintenanceWindow
 		shouldFail bool
 		errContain string
 	}{
 		{
-			name: "valid tcp service",
-			service: Service{
-				ID:       "db-1",
-				Name:     "Database",
-				Type:     "tcp",
-				Host:     "localhost",
-				Port:     5432,
-				Interval: "30s",
-				Timeout:  "5s",
+			name: "valid one-off window",
+			window: MaintenanceWindow{
+				Name:  "db upgrade",
+				Match: MaintenanceMatch{ServiceIDs: []string{"web-1"}},
+				Start: "2026-10-18T10:00:00Z",
+				End:   "2026-10-18T11:00:00Z",
 			},
-			shouldFail: false,
 		},
 		{
-			name: "missing host",
-			service: Service{
-				ID:       "db-1",
-				Name:     "Database",
-				Type:     "tcp",
-				Host:     "",
-				Port:     5432,
-				Interval: "30s",
-				Timeout:  "5s",
+			name: "valid recurring window",
+			window: MaintenanceWindow{
+				Name:     "nightly deploy",
+				Match:    MaintenanceMatch{Tags: []string{"deploy"}},
+				Schedule: "0 2 * * MON-FRI",
+				Duration: "30m",
+				Timezone: "UTC",
 			},
+		},
+		{
+			name:       "missing name",
+			window:     MaintenanceWindow{Match: MaintenanceMatch{Tags: []string{"x"}}, Start: "2026-10-18T10:00:00Z", End: "2026-10-18T11:00:00Z"},
 			shouldFail: true,
-			errContain: "host",
+			errContain: "name is required",
 		},
 		{
-			name: "port too low",
-			service: Service{
-				ID:       "db-1",
-				Name:     "Database",
-				Type:     "tcp",
-				Host:     "localhost",
-				Port:     0,
-				Interval: "30s",
-				Timeout:  "5s",
-			},
+			name:       "no targets",
+			window:     MaintenanceWindow{Name: "x", Start: "2026-10-18T10:00:00Z", End: "2026-10-18T11:00:00Z"},
 			shouldFail: true,
-			errContain: "port",
+			errContain: "at least one service_id or tag",
 		},
 		{
-			name: "port too high",
-			service: Service{
-				ID:       "db-1",
-				Name:     "Database",
-				Type:     "tcp",
-				Host:     "localhost",
-				Port:     65536,
-				Interval: "30s",
-				Timeout:  "5s",
-			},
+			name:       "unknown service",
+			window:     MaintenanceWindow{Name: "x", Match: MaintenanceMatch{ServiceIDs: []string{"nope"}}, Start: "2026-10-18T10:00:00Z", End: "2026-10-18T11:00:00Z"},
 			shouldFail: true,
-			errContain: "port",
+			errContain: `unknown service "nope"`,
 		},
 		{
-			name: "max valid port",
-			service: Service{
-				ID:       "db-1",
-				Name:     "Database",
-				Type:     "tcp",
-				Host:     "localhost",
-				Port:     65535,
-				Interval: "30s",
-				Timeout:  "5s",
-			},
-			shouldFail: false,
+			name:       "end before start",
+			window:     MaintenanceWindow{Name: "x", Match: MaintenanceMatch{Tags: []string{"x"}}, Start: "2026-10-18T11:00:00Z", End: "2026-10-18T10:00:00Z"},
+			shouldFail: true,
+			errContain: "end must be after start",
+		},
+		{
+			name:       "invalid timestamp",
+			window:     MaintenanceWindow{Name: "x", Match: MaintenanceMatch{Tags: []string{"x"}}, Start: "tomorrow", End: "2026-10-18T10:00:00Z"},
+			shouldFail: true,
+			errContain: "start must be an rfc 3339 timestamp",
+		},
+		{
+			name:       "invalid cron",
+			window:     MaintenanceWindow{Name: "x", Match: MaintenanceMatch{Tags: []string{"x"}}, Schedule: "61 * * * *", Duration: "1h"},
+			shouldFail: true,
+			errContain: "schedule is invalid",
+		},
+		{
+			name:       "missing duration",
+			window:     MaintenanceWindow{Name: "x", Match: MaintenanceMatch{Tags: []string{"x"}}, Schedule: "0 2 * * *"},
+			shouldFail: true,
+			errContain: "duration must be a positive duration",
+		},
+		{
+			name:       "invalid timezone",
+			window:     MaintenanceWindow{Name: "x", Match: MaintenanceMatch{Tags: []string{"x"}}, Schedule: "0 2 * * *", Duration: "1h", Timezone: "Mars/Olympus"},
+			shouldFail: true,
+			errContain: "timezone is invalid",
+		},
+		{
+			name:       "schedule with start",
+			window:     MaintenanceWindow{Name: "x", Match: MaintenanceMatch{Tags: []string{"x"}}, Schedule: "0 2 * * *", Duration: "1h", Start: "2026-10-18T10:00:00Z"},
+			shouldFail: true,
+			errContain: "cannot combine schedule",
 		},
 	}
 
@@ -322,7 +1196,10 @@ func TestValidateService_TCPService(t *testing.T) {
 					WorkerCount:     10,
 					Jitter:          "0s",
 				},
-				Services: []Service{tc.service},
+				Services: []Service{
+					{ID: "web-1", Name: "Web", Type: "http", URL: "https://example.com", Interval: "30s", Timeout: "5s"},
+				},
+				Maintenance: []MaintenanceWindow{tc.window},
 			}
 
 			err := cfg.Validate()
@@ -341,6 +1218,55 @@ func TestValidateService_TCPService(t *testing.T) {
 	}
 }
 
+func TestValidateService_InvalidTag(t *testing.T) {
+	cfg := &Config{
+		Global: GlobalConfig{
+			ScrapeBind:      "0.0.0.0:8080",
+			LogLevel:        "info",
+			DefaultTimeout:  "5s",
+			DefaultInterval: "30s",
+			WorkerCount:     10,
+			Jitter:          "0s",
+		},
+		Services: []Service{
+			{ID: "web-1", Name: "Web", Type: "http", URL: "https://example.com", Interval: "30s", Timeout: "5s", Tags: []string{"ok", "not ok"}},
+		},
+	}
+
+	err := cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), `tags entry "not ok"`) {
+		t.Errorf("expected invalid tag error, got %v", err)
+	}
+}
+
+func TestValidateService_InvalidLink(t *testing.T) {
+	cfg := &Config{
+		Global: GlobalConfig{
+			ScrapeBind:      "0.0.0.0:8080",
+			LogLevel:        "info",
+			DefaultTimeout:  "5s",
+			DefaultInterval: "30s",
+			WorkerCount:     10,
+			Jitter:          "0s",
+		},
+		Services: []Service{
+			{
+				ID: "web-1", Name: "Web", Type: "http", URL: "https://example.com", Interval: "30s", Timeout: "5s",
+				Labels: map[string]string{"team": "platform"},
+				Links:  map[string]string{"runbook": "https://wiki.example.com/web", "dashboard": "grafana/web"},
+			},
+		},
+	}
+
+	err := cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), `links["dashboard"]`) {
+		t.Errorf("expected invalid link error, got %v", err)
+	}
+	if strings.Contains(err.Error(), "runbook") {
+		t.Errorf("valid link reported: %v", err)
+	}
+}
+
 func TestValidateService_DuplicateIDs(t *testing.T) {
 	cfg := &Config{
 		Global: GlobalConfig{
@@ -496,9 +1422,129 @@ func TestValidateAlerting_InvalidChannels(t *testing.T) {
 			},
 			errContain: "username and password",
 		},
+		{
+			name:       "pagerduty missing routing_key",
+			channel:    Channel{Type: "pagerduty"},
+			errContain: "routing_key",
+		},
+		{
+			name:       "pagerduty invalid severity",
+			channel:    Channel{Type: "pagerduty", RoutingKey: "key", Severity: "high"},
+			errContain: "severity",
+		},
+		{
+			name:       "pagerduty invalid api_url",
+			channel:    Channel{Type: "pagerduty", RoutingKey: "key", APIURL: "events.pagerduty.com"},
+			errContain: "api_url",
+		},
+		{
+			name:       "opsgenie missing api_key",
+			channel:    Channel{Type: "opsgenie"},
+			errContain: "api_key",
+		},
+		{
+			name:       "opsgenie invalid priority",
+			channel:    Channel{Type: "opsgenie", APIKey: "key", Priority: "high"},
+			errContain: "priority",
+		},
+		{
+			name:       "opsgenie invalid responder type",
+			channel:    Channel{Type: "opsgenie", APIKey: "key", Responders: []OpsgenieResponder{{Type: "group", Name: "SRE"}}},
+			errContain: "responders[0].type",
+		},
+		{
+			name:       "opsgenie responder without name",
+			channel:    Channel{Type: "opsgenie", APIKey: "key", Responders: []OpsgenieResponder{{Type: "team"}}},
+			errContain: "responders[0].name",
+		},
+		{
+			name:       "teams missing webhook",
+			channel:    Channel{Type: "teams"},
+			errContain: "webhook_url",
+		},
+		{
+			name:       "telegram missing bot_token",
+			channel:    Channel{Type: "telegram", ChatID: "-100200"},
+			errContain: "bot_token",
+		},
+		{
+			name:       "telegram missing chat_id",
+			channel:    Channel{Type: "telegram", BotToken: "123:abc"},
+			errContain: "chat_id",
+		},
+		{
+			name:       "matrix missing homeserver",
+			channel:    Channel{Type: "matrix", Token: "t", RoomID: "!room:example.org"},
+			errContain: "api_url",
+		},
+		{
+			name:       "matrix room alias instead of id",
+			channel:    Channel{Type: "matrix", APIURL: "https://matrix.example.org", Token: "t", RoomID: "#ops:example.org"},
+			errContain: "room_id",
+		},
+		{
+			name:       "ntfy missing topic",
+			channel:    Channel{Type: "ntfy"},
+			errContain: "topic",
+		},
+		{
+			name:       "ntfy invalid priority",
+			channel:    Channel{Type: "ntfy", Topic: "alerts", Priority: "P1"},
+			errContain: "priority",
+		},
+		{
+			name:       "gotify missing token",
+			channel:    Channel{Type: "gotify", APIURL: "https://gotify.example.org"},
+			errContain: "token",
+		},
+		{
+			name:       "gotify priority out of range",
+			channel:    Channel{Type: "gotify", APIURL: "https://gotify.example.org", Token: "t", Priority: "11"},
+			errContain: "priority",
+		},
+		{
+			name:       "webhook missing url",
+			channel:    Channel{Type: "webhook"},
+			errContain: "webhook_url",
+		},
+		{
+			name:       "webhook invalid method",
+			channel:    Channel{Type: "webhook", WebhookURL: "https://bot.internal/hook", Method: "GET"},
+			errContain: "method",
+		},
+		{
+			name:       "webhook invalid header name",
+			channel:    Channel{Type: "webhook", WebhookURL: "https://bot.internal/hook", Headers: map[string]string{"X Bad": "1"}},
+			errContain: "invalid name",
+		},
+		{
+			name:       "webhook body syntax error",
+			channel:    Channel{Type: "webhook", WebhookURL: "https://bot.internal/hook", Body: `{"text": {{ json .Message }`},
+			errContain: "body is not a valid template",
+		},
+		{
+			name:       "webhook body unknown field",
+			channel:    Channel{Type: "webhook", WebhookURL: "https://bot.internal/hook", Body: `{{ .Service.Hostname }}`},
+			errContain: "hostname",
+		},
+		{
+			name:       "webhook header unknown function",
+			channel:    Channel{Type: "webhook", WebhookURL: "https://bot.internal/hook", Headers: map[string]string{"X-Id": "{{ shout .Service.ID }}"}},
+			errContain: "shout",
+		},
+		{
+			name:       "message template unknown field",
+			channel:    Channel{Type: "slack", WebhookURL: "https://hooks.slack.com/x", Templates: MessageTemplates{Message: "{{ .Service.Owner }}"}},
+			errContain: "templates.message is not a valid template",
+		},
+		{
+			name:       "html body template syntax error",
+			channel:    Channel{Type: "email", SMTPHost: "smtp.example.com", SMTPPort: 587, From: "a@example.com", To: []string{"b@example.com"}, Templates: MessageTemplates{HTMLBody: "<p>{{ .Message </p>"}},
+			errContain: "templates.html_body",
+		},
 		{
 			name:       "invalid channel type",
-			channel:    Channel{Type: "telegram"},
+			channel:    Channel{Type: "irc"},
 			errContain: "type",
 		},
 	}
@@ -595,6 +1641,47 @@ func TestValidateAlerting_InvalidCooldown(t *testing.T) {
 	}
 }
 
+func TestValidateAlerting_RouteTemplates(t *testing.T) {
+	cfg := &Config{
+		Global: GlobalConfig{
+			ScrapeBind:      "0.0.0.0:8080",
+			LogLevel:        "info",
+			DefaultTimeout:  "5s",
+			DefaultInterval: "30s",
+			WorkerCount:     10,
+			Jitter:          "0s",
+		},
+		Alerting: AlertingConfig{
+			Channels: map[string]Channel{
+				"discord": {Type: "discord", WebhookURL: "https://discord.com/api/webhooks/123"},
+			},
+			Routes: []Route{
+				{
+					Match:     RouteMatch{ServiceIDs: []string{"svc-1"}},
+					Notify:    []string{"discord"},
+					Templates: MessageTemplates{Subject: "{{ .Service.Name }} down for {{ .Duration }}"},
+				},
+				{
+					Match:     RouteMatch{ServiceIDs: []string{"svc-2"}},
+					Notify:    []string{"discord"},
+					Templates: MessageTemplates{Body: "{{ runbook .Service }}"},
+				},
+			},
+		},
+	}
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("expected validation error for invalid route template")
+	}
+	if !strings.Contains(err.Error(), "routes[1].templates.body") {
+		t.Errorf("error should mention routes[1].templates.body: %v", err)
+	}
+	if strings.Contains(err.Error(), "routes[0]") {
+		t.Errorf("valid route template reported: %v", err)
+	}
+}
+
 func TestValidateAlerting_RoutesWithoutChannels(t *testing.T) {
 	cfg := &Config{
 		Global: GlobalConfig{
@@ -665,3 +1752,79 @@ func TestIsSafeID(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateHA(t *testing.T) {
+	tests := []struct {
+		name       string
+		ha         HAConfig
+		cluster    ClusterConfig
+		errContain string
+	}{
+		{name: "disabled", ha: HAConfig{}},
+		{
+			name: "valid",
+			ha:   HAConfig{Name: "a", PeerURL: "http://10.0.0.2:8080", Token: "s3cret", LeaseDuration: "15s", RenewInterval: "5s"},
+		},
+		{
+			name:       "name without peer",
+			ha:         HAConfig{Name: "a"},
+			errContain: "ha.peer_url is required",
+		},
+		{
+			name:       "missing name",
+			ha:         HAConfig{PeerURL: "http://10.0.0.2:8080", Token: "s3cret"},
+			errContain: "ha.name must be a non-empty identifier",
+		},
+		{
+			name:       "missing token",
+			ha:         HAConfig{Name: "a", PeerURL: "http://10.0.0.2:8080"},
+			errContain: "ha.token is required",
+		},
+		{
+			name:       "bad peer url",
+			ha:         HAConfig{Name: "a", PeerURL: "10.0.0.2:8080", Token: "s3cret"},
+			errContain: "ha.peer_url must be an http(s) URL",
+		},
+		{
+			name:       "lease not above renew interval",
+			ha:         HAConfig{Name: "a", PeerURL: "http://10.0.0.2:8080", Token: "s3cret", LeaseDuration: "5s", RenewInterval: "5s"},
+			errContain: "must be longer than ha.renew_interval",
+		},
+		{
+			name: "combined with cluster",
+			ha:   HAConfig{Name: "a", PeerURL: "http://10.0.0.2:8080", Token: "s3cret"},
+			cluster: ClusterConfig{Self: "a", Token: "s3cret", Peers: []ClusterPeer{
+				{Name: "a", URL: "http://10.0.0.1:8080"},
+			}},
+			errContain: "ha cannot be combined with cluster",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &Config{
+				Global: GlobalConfig{
+					ScrapeBind:      "0.0.0.0:8080",
+					LogLevel:        "info",
+					DefaultTimeout:  "5s",
+					DefaultInterval: "30s",
+					WorkerCount:     10,
+					Jitter:          "0s",
+				},
+				HA:      tc.ha,
+				Cluster: tc.cluster,
+			}
+
+			err := cfg.Validate()
+			if tc.errContain == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.errContain) {
+				t.Errorf("error should contain %q: %v", tc.errContain, err)
+			}
+		})
+	}
+}
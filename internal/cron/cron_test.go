@@ -0,0 +1,173 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse_Invalid(t *testing.T) {
+	tests := []string{
+		"",
+		"* * * *",
+		"* * * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"* * * foo *",
+	}
+
+	for _, expr := range tests {
+		t.Run(expr, func(t *testing.T) {
+			if _, err := Parse(expr); err == nil {
+				t.Errorf("Parse(%q) expected error", expr)
+			}
+		})
+	}
+}
+
+func TestSchedule_Next(t *testing.T) {
+	base := time.Date(2026, 10, 14, 10, 17, 42, 0, time.UTC) // Wednesday
+
+	tests := []struct {
+		name string
+		expr string
+		from time.Time
+		want time.Time
+	}{
+		{
+			name: "every minute",
+			expr: "* * * * *",
+			from: base,
+			want: time.Date(2026, 10, 14, 10, 18, 0, 0, time.UTC),
+		},
+		{
+			name: "every 15 seconds",
+			expr: "*/15 * * * * *",
+			from: base,
+			want: time.Date(2026, 10, 14, 10, 17, 45, 0, time.UTC),
+		},
+		{
+			name: "specific minute each hour",
+			expr: "5 * * * *",
+			from: base,
+			want: time.Date(2026, 10, 14, 11, 5, 0, 0, time.UTC),
+		},
+		{
+			name: "business hours weekdays",
+			expr: "0 */30 9-17 * * MON-FRI",
+			from: time.Date(2026, 10, 16, 17, 45, 0, 0, time.UTC), // Friday
+			want: time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC),   // Monday
+		},
+		{
+			name: "sunday as 7",
+			expr: "0 3 * * 7",
+			from: base,
+			want: time.Date(2026, 10, 18, 3, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "month names and list",
+			expr: "0 0 1 jan,jul *",
+			from: base,
+			want: time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "day of month or day of week",
+			expr: "0 0 13 * FRI",
+			from: base,
+			want: time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "leap day",
+			expr: "0 0 29 2 *",
+			from: base,
+			want: time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "descriptor",
+			expr: "@daily",
+			from: base,
+			want: time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "strictly after exact match",
+			expr: "0 * * * * *",
+			from: time.Date(2026, 10, 14, 10, 17, 0, 0, time.UTC),
+			want: time.Date(2026, 10, 14, 10, 18, 0, 0, time.UTC),
+		},
+		{
+			name: "step from value",
+			expr: "10/20 * * * *",
+			from: base,
+			want: time.Date(2026, 10, 14, 10, 30, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			sched, err := Parse(tc.expr)
+			if err != nil {
+				t.Fatalf("Parse(%q) error: %v", tc.expr, err)
+			}
+			if got := sched.Next(tc.from); !got.Equal(tc.want) {
+				t.Errorf("Next(%v) = %v, want %v", tc.from, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSchedule_Next_TimeZone(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+
+	sched, err := Parse("0 9 * * *")
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+
+	from := time.Date(2026, 10, 14, 6, 0, 0, 0, time.UTC).In(loc)
+	got := sched.Next(from)
+
+	want := time.Date(2026, 10, 14, 7, 0, 0, 0, time.UTC) // 09:00 CEST
+	if !got.Equal(want) {
+		t.Errorf("Next() = %v, want %v", got, want)
+	}
+}
+
+func TestSchedule_Next_DSTFallBack(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+
+	sched, err := Parse("30 3 * * *")
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+
+	// 2026-10-25 02:00-03:00 occurs twice in Berlin
+	from := time.Date(2026, 10, 25, 0, 30, 0, 0, time.UTC).In(loc)
+	got := sched.Next(from)
+
+	want := time.Date(2026, 10, 25, 2, 30, 0, 0, time.UTC) // 03:30 CET
+	if !got.Equal(want) {
+		t.Errorf("Next() = %v, want %v", got, want)
+	}
+}
+
+func TestSchedule_Next_Impossible(t *testing.T) {
+	sched, err := Parse("0 0 31 2 *")
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+
+	if got := sched.Next(time.Now()); !got.IsZero() {
+		t.Errorf("Next() = %v, want zero time for impossible schedule", got)
+	}
+}
@@ -0,0 +1,248 @@
+// Package ha runs two instances as an active/passive pair: one leader that
+// sends alerts and one standby that takes over when the leader's lease runs
+// out.
+package ha
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"uptiq/internal/alerting"
+	"uptiq/internal/config"
+	"uptiq/internal/metrics"
+)
+
+// LeasePath is where instances accept lease renewals, relative to their base URL.
+const LeasePath = "/api/ha/lease"
+
+// ErrOwnLease is returned for a lease that claims to come from this instance,
+// which means ha.peer_url points back at it.
+var ErrOwnLease = errors.New("lease holder is this instance; check ha.peer_url")
+
+// Lease is sent by the leader to renew its lease on the standby. It carries
+// the leader's alert state, so the standby can take over without resending
+// alerts the leader already delivered.
+type Lease struct {
+	Holder string                           `json:"holder"`
+	State  map[string]alerting.ServiceState `json:"state"`
+}
+
+// LeaseReply tells the leader who answered and whether it leads as well.
+type LeaseReply struct {
+	Name   string `json:"name"`
+	Leader bool   `json:"leader"`
+}
+
+// StateStore is the alert state the leader replicates; alerting.Engine
+// implements it. Only delivered alerts are replicated, so a standby taking
+// over sends the ones the leader had not delivered yet.
+type StateStore interface {
+	DeliveredSnapshot() map[string]alerting.ServiceState
+	Restore(snapshot map[string]alerting.ServiceState)
+	SetStandby(standby bool)
+}
+
+// Elector decides whether this instance leads. It starts as standby and
+// only takes over once a full lease passes without a renewal, so a restarted
+// instance does not briefly alert alongside a running leader. If both end up
+// leading, e.g. after a partition heals, the lower name keeps the lease.
+type Elector struct {
+	name     string
+	peerURL  string
+	token    string
+	lease    time.Duration
+	interval time.Duration
+
+	store   StateStore
+	client  *http.Client
+	log     *slog.Logger
+	metrics *metrics.Collector
+
+	mu         sync.Mutex
+	leader     bool
+	leaseUntil time.Time // Peer's lease as leader
+
+	syncCh chan struct{}
+}
+
+// New creates the elector for cfg, in standby.
+func New(cfg config.HAConfig, store StateStore, log *slog.Logger, m *metrics.Collector) (*Elector, error) {
+	if log == nil {
+		log = slog.Default()
+	}
+
+	lease, err := time.ParseDuration(cfg.LeaseDuration)
+	if err != nil {
+		return nil, fmt.Errorf("parse ha.lease_duration: %w", err)
+	}
+	interval, err := time.ParseDuration(cfg.RenewInterval)
+	if err != nil {
+		return nil, fmt.Errorf("parse ha.renew_interval: %w", err)
+	}
+
+	e := &Elector{
+		name:       cfg.Name,
+		peerURL:    strings.TrimSuffix(cfg.PeerURL, "/"),
+		token:      cfg.Token,
+		lease:      lease,
+		interval:   interval,
+		store:      store,
+		client:     &http.Client{Timeout: interval},
+		log:        log,
+		metrics:    m,
+		leaseUntil: time.Now().Add(lease),
+		syncCh:     make(chan struct{}, 1),
+	}
+	e.setLeader(false)
+	return e, nil
+}
+
+// Name returns this instance's name.
+func (e *Elector) Name() string {
+	return e.name
+}
+
+// Leader reports whether this instance currently sends alerts.
+func (e *Elector) Leader() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.leader
+}
+
+// Sync asks the leader to replicate its state now rather than at the next
+// renewal. Called after every alert so a failover right after it does not
+// send it again.
+func (e *Elector) Sync() {
+	select {
+	case e.syncCh <- struct{}{}:
+	default:
+	}
+}
+
+// Renew handles a lease renewal from the peer. A standby extends the lease
+// and adopts the leader's state. A leader yields only to a lower name.
+func (e *Elector) Renew(lease Lease) (LeaseReply, error) {
+	if lease.Holder == e.name {
+		return LeaseReply{}, ErrOwnLease
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.leader {
+		if lease.Holder > e.name {
+			return LeaseReply{Name: e.name, Leader: true}, nil
+		}
+		e.log.Warn("peer also leads; yielding", "peer", lease.Holder)
+		e.setLeader(false)
+	}
+
+	e.leaseUntil = time.Now().Add(e.lease)
+	e.store.Restore(lease.State)
+	return LeaseReply{Name: e.name}, nil
+}
+
+// Run renews the lease while leading and takes over when the peer's lease
+// expires, until ctx is done.
+func (e *Elector) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-e.syncCh:
+		}
+
+		e.tick(ctx, time.Now())
+	}
+}
+
+func (e *Elector) tick(ctx context.Context, now time.Time) {
+	e.mu.Lock()
+	leader := e.leader
+	if !leader && now.After(e.leaseUntil) {
+		e.log.Warn("peer lease expired; taking over as leader", "peer", e.peerURL)
+		e.setLeader(true)
+		if e.metrics != nil {
+			e.metrics.HATakeovers.Inc()
+		}
+		leader = true
+	}
+	e.mu.Unlock()
+
+	if !leader {
+		return
+	}
+
+	reply, err := e.renew(ctx)
+	if err != nil {
+		// Expected while the peer is down; it adopts our lease when back
+		e.log.Debug("ha lease renewal failed", "peer", e.peerURL, "error", err.Error())
+		return
+	}
+
+	if reply.Leader && reply.Name < e.name {
+		e.mu.Lock()
+		if e.leader {
+			e.log.Warn("peer also leads; yielding", "peer", reply.Name)
+			e.setLeader(false)
+			e.leaseUntil = time.Now().Add(e.lease)
+		}
+		e.mu.Unlock()
+	}
+}
+
+func (e *Elector) renew(ctx context.Context) (LeaseReply, error) {
+	body, err := json.Marshal(Lease{Holder: e.name, State: e.store.DeliveredSnapshot()})
+	if err != nil {
+		return LeaseReply{}, fmt.Errorf("encode lease: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.peerURL+LeasePath, bytes.NewReader(body))
+	if err != nil {
+		return LeaseReply{}, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+e.token)
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return LeaseReply{}, fmt.Errorf("send lease: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return LeaseReply{}, fmt.Errorf("peer returned status %d", resp.StatusCode)
+	}
+
+	var reply LeaseReply
+	if err := json.NewDecoder(resp.Body).Decode(&reply); err != nil {
+		return LeaseReply{}, fmt.Errorf("decode reply: %w", err)
+	}
+	return reply, nil
+}
+
+// setLeader switches roles. The caller holds e.mu, except in New.
+func (e *Elector) setLeader(leader bool) {
+	e.leader = leader
+	e.store.SetStandby(!leader)
+
+	if e.metrics != nil {
+		v := 0.0
+		if leader {
+			v = 1
+		}
+		e.metrics.HALeader.Set(v)
+	}
+}
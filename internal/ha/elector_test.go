@@ -0,0 +1,176 @@
+package ha
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"uptiq/internal/alerting"
+	"uptiq/internal/config"
+)
+
+type fakeStore struct {
+	mu      sync.Mutex
+	state   map[string]alerting.ServiceState
+	standby bool
+}
+
+func (s *fakeStore) DeliveredSnapshot() map[string]alerting.ServiceState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state
+}
+
+func (s *fakeStore) Restore(snapshot map[string]alerting.ServiceState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state = snapshot
+}
+
+func (s *fakeStore) SetStandby(standby bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.standby = standby
+}
+
+func newTestElector(t *testing.T, name, peerURL string) (*Elector, *fakeStore) {
+	t.Helper()
+
+	store := &fakeStore{}
+	cfg := config.HAConfig{
+		Name:          name,
+		PeerURL:       peerURL,
+		Token:         "s3cret",
+		LeaseDuration: "15s",
+		RenewInterval: "5s",
+	}
+	e, err := New(cfg, store, slog.New(slog.NewTextHandler(io.Discard, nil)), nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return e, store
+}
+
+// leaseServer serves LeasePath by passing renewals to e.
+func leaseServer(t *testing.T, e *Elector) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var lease Lease
+		if err := json.NewDecoder(r.Body).Decode(&lease); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		reply, err := e.Renew(lease)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(reply)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestElector_StartsAsStandby(t *testing.T) {
+	e, store := newTestElector(t, "a", "http://127.0.0.1:1")
+
+	if e.Leader() {
+		t.Error("new elector should not lead")
+	}
+	if !store.standby {
+		t.Error("store should be on standby")
+	}
+
+	// Still within the initial lease
+	e.tick(context.Background(), time.Now())
+	if e.Leader() {
+		t.Error("elector took over before the lease expired")
+	}
+}
+
+func TestElector_TakesOverWhenLeaseExpires(t *testing.T) {
+	e, store := newTestElector(t, "b", "http://127.0.0.1:1")
+
+	e.tick(context.Background(), time.Now().Add(16*time.Second))
+
+	if !e.Leader() {
+		t.Fatal("elector should lead after the lease expired")
+	}
+	if store.standby {
+		t.Error("store should no longer be on standby")
+	}
+}
+
+func TestElector_LeaderReplicatesState(t *testing.T) {
+	standby, standbyStore := newTestElector(t, "b", "")
+	peer := leaseServer(t, standby)
+
+	leader, leaderStore := newTestElector(t, "a", peer.URL)
+	leaderStore.state = map[string]alerting.ServiceState{
+		"web": {State: alerting.StateDown, DownNotified: true},
+	}
+
+	leader.tick(context.Background(), time.Now().Add(16*time.Second))
+
+	if !leader.Leader() {
+		t.Fatal("a should lead")
+	}
+	if standby.Leader() {
+		t.Error("b should stay on standby")
+	}
+	if st := standbyStore.state["web"]; !st.DownNotified {
+		t.Errorf("replicated state = %+v, want DownNotified", st)
+	}
+
+	// The renewal pushed b's lease forward
+	standby.tick(context.Background(), time.Now().Add(10*time.Second))
+	if standby.Leader() {
+		t.Error("b took over while a's lease was valid")
+	}
+}
+
+func TestElector_LowerNameWinsWhenBothLead(t *testing.T) {
+	tests := []struct {
+		name       string
+		self       string
+		holder     string
+		wantLeader bool
+	}{
+		{name: "yield to lower name", self: "b", holder: "a", wantLeader: false},
+		{name: "keep lease from higher name", self: "a", holder: "b", wantLeader: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			e, store := newTestElector(t, tc.self, "http://127.0.0.1:1")
+			e.tick(context.Background(), time.Now().Add(16*time.Second))
+
+			reply, err := e.Renew(Lease{Holder: tc.holder})
+			if err != nil {
+				t.Fatalf("Renew: %v", err)
+			}
+			if reply.Leader != tc.wantLeader || e.Leader() != tc.wantLeader {
+				t.Errorf("leader = %v (reply %v), want %v", e.Leader(), reply.Leader, tc.wantLeader)
+			}
+			if store.standby == tc.wantLeader {
+				t.Errorf("store standby = %v, want %v", store.standby, !tc.wantLeader)
+			}
+		})
+	}
+}
+
+func TestElector_RejectsOwnLease(t *testing.T) {
+	e, _ := newTestElector(t, "a", "http://127.0.0.1:1")
+
+	if _, err := e.Renew(Lease{Holder: "a"}); !errors.Is(err, ErrOwnLease) {
+		t.Errorf("err = %v, want ErrOwnLease", err)
+	}
+}
@@ -0,0 +1,366 @@
+// Package maintenance tracks scheduled maintenance windows during which
+// alert notifications are suppressed.
+package maintenance
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"uptiq/internal/config"
+	"uptiq/internal/cron"
+)
+
+// Window sources.
+const (
+	SourceConfig = "config"
+	SourceAPI    = "api"
+)
+
+var (
+	// ErrNotFound is returned when cancelling an unknown window.
+	ErrNotFound = errors.New("maintenance window not found")
+
+	// ErrReadOnly is returned when cancelling a window defined in the config file.
+	ErrReadOnly = errors.New("maintenance window is defined in config and cannot be cancelled")
+)
+
+// Window is a compiled maintenance window.
+type Window struct {
+	ID         string
+	Name       string
+	ServiceIDs []string
+	Tags       []string
+	Source     string
+
+	// One-off window bounds
+	Start time.Time
+	End   time.Time
+
+	// Recurring window
+	Schedule string
+	Duration time.Duration
+	Location *time.Location
+	cron     *cron.Schedule
+}
+
+// Status is the API representation of a window at a point in time.
+type Status struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	Source     string    `json:"source"`
+	ServiceIDs []string  `json:"service_ids"`
+	Tags       []string  `json:"tags"`
+	Schedule   string    `json:"schedule,omitempty"`
+	Duration   string    `json:"duration,omitempty"`
+	Timezone   string    `json:"timezone,omitempty"`
+	Active     bool      `json:"active"`
+	Start      time.Time `json:"start"` // Current or next occurrence
+	End        time.Time `json:"end"`
+}
+
+// Request describes a one-off window created at runtime.
+// Start defaults to now; either End or Duration must be set.
+type Request struct {
+	Name       string    `json:"name"`
+	ServiceIDs []string  `json:"service_ids"`
+	Tags       []string  `json:"tags"`
+	Start      time.Time `json:"start"`
+	End        time.Time `json:"end"`
+	Duration   string    `json:"duration"`
+}
+
+// Manager holds config-defined and runtime maintenance windows.
+type Manager struct {
+	mu      sync.RWMutex
+	config  []Window
+	runtime map[string]Window
+	nextID  int
+	now     func() time.Time
+}
+
+// NewManager creates a manager from configured windows.
+func NewManager(windows []config.MaintenanceWindow) (*Manager, error) {
+	m := &Manager{
+		runtime: make(map[string]Window),
+		now:     time.Now,
+	}
+	if err := m.Update(windows); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Update replaces config-defined windows; runtime windows are kept.
+func (m *Manager) Update(windows []config.MaintenanceWindow) error {
+	compiled, err := Compile(windows)
+	if err != nil {
+		return err
+	}
+	m.SetWindows(compiled)
+	return nil
+}
+
+// Compile converts configured windows without installing them, so callers
+// can validate a reload before applying any part of it.
+func Compile(windows []config.MaintenanceWindow) ([]Window, error) {
+	compiled := make([]Window, 0, len(windows))
+	for i, w := range windows {
+		cw, err := compileWindow(fmt.Sprintf("config-%d", i), w)
+		if err != nil {
+			return nil, fmt.Errorf("maintenance[%d]: %w", i, err)
+		}
+		compiled = append(compiled, cw)
+	}
+	return compiled, nil
+}
+
+// SetWindows replaces config-defined windows with already compiled ones.
+func (m *Manager) SetWindows(compiled []Window) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.config = compiled
+}
+
+// InMaintenance reports whether a service is covered by an active window.
+func (m *Manager) InMaintenance(svc config.Service) bool {
+	now := m.now()
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, w := range m.config {
+		if w.matches(svc) && w.activeAt(now) {
+			return true
+		}
+	}
+	for _, w := range m.runtime {
+		if w.matches(svc) && w.activeAt(now) {
+			return true
+		}
+	}
+	return false
+}
+
+// Windows returns the status of all windows, pruning expired runtime windows.
+func (m *Manager) Windows() []Status {
+	now := m.now()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.pruneLocked(now)
+
+	out := make([]Status, 0, len(m.config)+len(m.runtime))
+	for _, w := range m.config {
+		out = append(out, w.status(now))
+	}
+
+	runtime := make([]Status, 0, len(m.runtime))
+	for _, w := range m.runtime {
+		runtime = append(runtime, w.status(now))
+	}
+	sort.Slice(runtime, func(i, j int) bool { return runtime[i].Start.Before(runtime[j].Start) })
+
+	return append(out, runtime...)
+}
+
+// Create adds a one-off window at runtime.
+func (m *Manager) Create(req Request) (Status, error) {
+	now := m.now()
+
+	w, err := compileRequest(req, now)
+	if err != nil {
+		return Status{}, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.pruneLocked(now)
+	m.nextID++
+	w.ID = fmt.Sprintf("api-%d", m.nextID)
+	m.runtime[w.ID] = w
+
+	return w.status(now), nil
+}
+
+// Cancel removes a runtime window.
+func (m *Manager) Cancel(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.runtime[id]; ok {
+		delete(m.runtime, id)
+		return nil
+	}
+	for _, w := range m.config {
+		if w.ID == id {
+			return ErrReadOnly
+		}
+	}
+	return ErrNotFound
+}
+
+func (m *Manager) pruneLocked(now time.Time) {
+	for id, w := range m.runtime {
+		if !w.End.After(now) {
+			delete(m.runtime, id)
+		}
+	}
+}
+
+func compileWindow(id string, w config.MaintenanceWindow) (Window, error) {
+	out := Window{
+		ID:         id,
+		Name:       w.Name,
+		ServiceIDs: slices.Clone(w.Match.ServiceIDs),
+		Tags:       slices.Clone(w.Match.Tags),
+		Source:     SourceConfig,
+	}
+
+	if !w.IsRecurring() {
+		start, err := time.Parse(time.RFC3339, w.Start)
+		if err != nil {
+			return Window{}, fmt.Errorf("parse start: %w", err)
+		}
+		end, err := time.Parse(time.RFC3339, w.End)
+		if err != nil {
+			return Window{}, fmt.Errorf("parse end: %w", err)
+		}
+		out.Start, out.End = start, end
+		return out, nil
+	}
+
+	sched, err := cron.Parse(w.Schedule)
+	if err != nil {
+		return Window{}, fmt.Errorf("parse schedule: %w", err)
+	}
+	duration, err := time.ParseDuration(w.Duration)
+	if err != nil {
+		return Window{}, fmt.Errorf("parse duration: %w", err)
+	}
+	loc, err := time.LoadLocation(w.Timezone)
+	if err != nil {
+		return Window{}, fmt.Errorf("load timezone: %w", err)
+	}
+
+	out.Schedule = w.Schedule
+	out.Duration = duration
+	out.Location = loc
+	out.cron = sched
+	return out, nil
+}
+
+func compileRequest(req Request, now time.Time) (Window, error) {
+	if strings.TrimSpace(req.Name) == "" {
+		return Window{}, errors.New("name is required")
+	}
+	if len(req.ServiceIDs) == 0 && len(req.Tags) == 0 {
+		return Window{}, errors.New("at least one service_id or tag is required")
+	}
+
+	start := req.Start
+	if start.IsZero() {
+		start = now
+	}
+
+	end := req.End
+	switch {
+	case end.IsZero() && req.Duration == "":
+		return Window{}, errors.New("end or duration is required")
+	case !end.IsZero() && req.Duration != "":
+		return Window{}, errors.New("end and duration are mutually exclusive")
+	case req.Duration != "":
+		d, err := time.ParseDuration(req.Duration)
+		if err != nil || d <= 0 {
+			return Window{}, fmt.Errorf("duration must be a positive duration (got %q)", req.Duration)
+		}
+		end = start.Add(d)
+	}
+
+	if !end.After(start) {
+		return Window{}, errors.New("end must be after start")
+	}
+	if !end.After(now) {
+		return Window{}, errors.New("window has already ended")
+	}
+
+	return Window{
+		Name:       req.Name,
+		ServiceIDs: slices.Clone(req.ServiceIDs),
+		Tags:       slices.Clone(req.Tags),
+		Source:     SourceAPI,
+		Start:      start,
+		End:        end,
+	}, nil
+}
+
+func (w Window) matches(svc config.Service) bool {
+	if slices.Contains(w.ServiceIDs, svc.ID) {
+		return true
+	}
+	for _, tag := range w.Tags {
+		if slices.Contains(svc.Tags, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+func (w Window) activeAt(now time.Time) bool {
+	start, _ := w.occurrence(now)
+	return !start.IsZero() && !start.After(now)
+}
+
+// occurrence returns the occurrence that is active at now, or else the next one.
+// The zero time is returned when the window will never be active again.
+func (w Window) occurrence(now time.Time) (time.Time, time.Time) {
+	if w.cron == nil {
+		if !w.End.After(now) {
+			return time.Time{}, time.Time{}
+		}
+		return w.Start, w.End
+	}
+
+	// The first activation after (now - duration) is either running now or next
+	start := w.cron.Next(now.Add(-w.Duration).In(w.Location))
+	if start.IsZero() {
+		return time.Time{}, time.Time{}
+	}
+	return start, start.Add(w.Duration)
+}
+
+func (w Window) status(now time.Time) Status {
+	start, end := w.occurrence(now)
+
+	st := Status{
+		ID:         w.ID,
+		Name:       w.Name,
+		Source:     w.Source,
+		ServiceIDs: nonNil(w.ServiceIDs),
+		Tags:       nonNil(w.Tags),
+		Active:     !start.IsZero() && !start.After(now),
+		Start:      start,
+		End:        end,
+	}
+	if w.cron != nil {
+		st.Schedule = w.Schedule
+		st.Duration = w.Duration.String()
+		st.Timezone = w.Location.String()
+	}
+	return st
+}
+
+// nonNil returns an empty slice instead of nil so JSON encodes [] not null.
+func nonNil(in []string) []string {
+	if in == nil {
+		return []string{}
+	}
+	return in
+}
@@ -0,0 +1,222 @@
+package maintenance
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"uptiq/internal/config"
+)
+
+func newTestManager(t *testing.T, now time.Time, windows ...config.MaintenanceWindow) *Manager {
+	t.Helper()
+
+	m, err := NewManager(windows)
+	if err != nil {
+		t.Fatalf("NewManager() error: %v", err)
+	}
+	m.now = func() time.Time { return now }
+	return m
+}
+
+func TestManager_OneOffWindow(t *testing.T) {
+	window := config.MaintenanceWindow{
+		Name:  "db upgrade",
+		Match: config.MaintenanceMatch{ServiceIDs: []string{"db"}},
+		Start: "2026-10-18T10:00:00Z",
+		End:   "2026-10-18T11:00:00Z",
+	}
+	db := config.Service{ID: "db"}
+	web := config.Service{ID: "web"}
+
+	tests := []struct {
+		name string
+		now  time.Time
+		svc  config.Service
+		want bool
+	}{
+		{name: "before window", now: time.Date(2026, 10, 18, 9, 59, 0, 0, time.UTC), svc: db, want: false},
+		{name: "inside window", now: time.Date(2026, 10, 18, 10, 30, 0, 0, time.UTC), svc: db, want: true},
+		{name: "other service", now: time.Date(2026, 10, 18, 10, 30, 0, 0, time.UTC), svc: web, want: false},
+		{name: "at end", now: time.Date(2026, 10, 18, 11, 0, 0, 0, time.UTC), svc: db, want: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			m := newTestManager(t, tc.now, window)
+			if got := m.InMaintenance(tc.svc); got != tc.want {
+				t.Errorf("InMaintenance() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestManager_RecurringWindowWithTimeZone(t *testing.T) {
+	if _, err := time.LoadLocation("America/New_York"); err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+
+	window := config.MaintenanceWindow{
+		Name:     "nightly deploy",
+		Match:    config.MaintenanceMatch{Tags: []string{"deploy"}},
+		Schedule: "0 2 * * *",
+		Duration: "30m",
+		Timezone: "America/New_York",
+	}
+	svc := config.Service{ID: "api", Tags: []string{"edge", "deploy"}}
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want bool
+	}{
+		{name: "02:10 EDT", now: time.Date(2026, 10, 18, 6, 10, 0, 0, time.UTC), want: true},
+		{name: "02:10 UTC", now: time.Date(2026, 10, 18, 2, 10, 0, 0, time.UTC), want: false},
+		{name: "02:45 EDT", now: time.Date(2026, 10, 18, 6, 45, 0, 0, time.UTC), want: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			m := newTestManager(t, tc.now, window)
+			if got := m.InMaintenance(svc); got != tc.want {
+				t.Errorf("InMaintenance() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestManager_CreateAndCancel(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	m := newTestManager(t, now)
+
+	status, err := m.Create(Request{Name: "deploy", Tags: []string{"web"}, Duration: "15m"})
+	if err != nil {
+		t.Fatalf("Create() error: %v", err)
+	}
+	if !status.Active {
+		t.Error("window starting now should be active")
+	}
+	if status.Source != SourceAPI {
+		t.Errorf("Source = %q, want %q", status.Source, SourceAPI)
+	}
+	if !status.End.Equal(now.Add(15 * time.Minute)) {
+		t.Errorf("End = %v, want %v", status.End, now.Add(15*time.Minute))
+	}
+
+	svc := config.Service{ID: "frontend", Tags: []string{"web"}}
+	if !m.InMaintenance(svc) {
+		t.Error("service should be in maintenance")
+	}
+
+	if err := m.Cancel(status.ID); err != nil {
+		t.Fatalf("Cancel() error: %v", err)
+	}
+	if m.InMaintenance(svc) {
+		t.Error("service should not be in maintenance after cancel")
+	}
+	if err := m.Cancel(status.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("second Cancel() error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestManager_CancelConfigWindow(t *testing.T) {
+	m := newTestManager(t, time.Now(), config.MaintenanceWindow{
+		Name:     "weekly",
+		Match:    config.MaintenanceMatch{ServiceIDs: []string{"db"}},
+		Schedule: "0 3 * * SUN",
+		Duration: "1h",
+	})
+
+	if err := m.Cancel("config-0"); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Cancel() error = %v, want ErrReadOnly", err)
+	}
+}
+
+func TestManager_CreateValidation(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		req  Request
+	}{
+		{name: "missing name", req: Request{ServiceIDs: []string{"a"}, Duration: "1h"}},
+		{name: "missing targets", req: Request{Name: "x", Duration: "1h"}},
+		{name: "missing end", req: Request{Name: "x", ServiceIDs: []string{"a"}}},
+		{name: "end and duration", req: Request{Name: "x", ServiceIDs: []string{"a"}, End: now.Add(time.Hour), Duration: "1h"}},
+		{name: "invalid duration", req: Request{Name: "x", ServiceIDs: []string{"a"}, Duration: "-5m"}},
+		{name: "end before start", req: Request{Name: "x", ServiceIDs: []string{"a"}, Start: now.Add(time.Hour), End: now}},
+		{name: "already ended", req: Request{Name: "x", ServiceIDs: []string{"a"}, Start: now.Add(-2 * time.Hour), End: now.Add(-time.Hour)}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			m := newTestManager(t, now)
+			if _, err := m.Create(tc.req); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestManager_WindowsPrunesExpired(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	m := newTestManager(t, now)
+
+	if _, err := m.Create(Request{Name: "short", ServiceIDs: []string{"a"}, Duration: "1m"}); err != nil {
+		t.Fatalf("Create() error: %v", err)
+	}
+	if got := len(m.Windows()); got != 1 {
+		t.Fatalf("len(Windows()) = %d, want 1", got)
+	}
+
+	m.now = func() time.Time { return now.Add(2 * time.Minute) }
+	if got := len(m.Windows()); got != 0 {
+		t.Errorf("len(Windows()) = %d, want 0 after expiry", got)
+	}
+}
+
+func TestManager_UpdateKeepsRuntimeWindows(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	m := newTestManager(t, now)
+
+	if _, err := m.Create(Request{Name: "deploy", ServiceIDs: []string{"a"}, Duration: "1h"}); err != nil {
+		t.Fatalf("Create() error: %v", err)
+	}
+
+	err := m.Update([]config.MaintenanceWindow{{
+		Name:  "planned",
+		Match: config.MaintenanceMatch{ServiceIDs: []string{"b"}},
+		Start: "2026-10-19T00:00:00Z",
+		End:   "2026-10-19T01:00:00Z",
+	}})
+	if err != nil {
+		t.Fatalf("Update() error: %v", err)
+	}
+
+	windows := m.Windows()
+	if len(windows) != 2 {
+		t.Fatalf("len(Windows()) = %d, want 2", len(windows))
+	}
+	if windows[0].Source != SourceConfig || windows[0].Active {
+		t.Errorf("config window = %+v, want inactive config window", windows[0])
+	}
+	if windows[1].Source != SourceAPI || !windows[1].Active {
+		t.Errorf("runtime window = %+v, want active api window", windows[1])
+	}
+}
+
+func TestCompile(t *testing.T) {
+	windows, err := Compile([]config.MaintenanceWindow{
+		{Name: "nightly", Match: config.MaintenanceMatch{Tags: []string{"db"}}, Schedule: "0 2 * * *", Duration: "1h"},
+	})
+	if err != nil {
+		t.Fatalf("Compile() error: %v", err)
+	}
+	if len(windows) != 1 || windows[0].ID != "config-0" {
+		t.Fatalf("windows = %+v, want one window with ID config-0", windows)
+	}
+
+	if _, err := Compile([]config.MaintenanceWindow{{Name: "bad", Schedule: "nope", Duration: "1h"}}); err == nil {
+		t.Error("expected error for invalid schedule")
+	}
+}
@@ -3,6 +3,8 @@ package metrics
 
 import (
 	"runtime"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -18,6 +20,12 @@ const (
 	LabelServiceName = "service_name"
 	LabelType        = "type"
 	LabelResult      = "result"
+	LabelChange      = "change"
+	LabelSecond      = "second"
+	LabelLimit       = "limit"
+	LabelLocation    = "location"
+	LabelPeer        = "peer"
+	LabelChannel     = "channel"
 )
 
 // Result label values.
@@ -26,14 +34,57 @@ const (
 	ResultFailure = "failure"
 )
 
+// Schedule change label values.
+const (
+	ChangeAdded   = "added"
+	ChangeChanged = "changed"
+	ChangeRemoved = "removed"
+)
+
+// Alert delivery label values.
+const (
+	AlertDelivered  = "delivered"
+	AlertFailed     = "failed"     // Gave up after retries, or not retryable
+	AlertDropped    = "dropped"    // Queue full or shutting down, and no outbox
+	AlertExpired    = "expired"    // Left in the outbox for too long
+	AlertSuperseded = "superseded" // A newer alert for the service was queued on the channel
+)
+
 // Collector contains all uptiq metrics.
 type Collector struct {
 	CheckTotal           *prometheus.CounterVec
 	CheckLatencySeconds  *prometheus.HistogramVec
 	Up                   *prometheus.GaugeVec
 	LastSuccessTimestamp *prometheus.GaugeVec
+	DomainExpiry         *prometheus.GaugeVec
+	NTPOffsetSeconds     *prometheus.GaugeVec
+	NTPStratum           *prometheus.GaugeVec
 	BuildInfo            *prometheus.GaugeVec
 	ConfigReloadSuccess  prometheus.Gauge
+	ScheduleChanges      *prometheus.CounterVec
+	ScheduledServices    prometheus.Gauge
+	ScheduleLoad         *prometheus.GaugeVec
+	SchedulerLagSeconds  prometheus.Histogram
+	SchedulerDelayed     *prometheus.CounterVec
+	SkippedOverlap       *prometheus.CounterVec
+	SkippedPaused        *prometheus.CounterVec
+	ServicePaused        *prometheus.GaugeVec
+	LocationUp           *prometheus.GaugeVec
+	LocationCheckTotal   *prometheus.CounterVec
+	FailingLocations     *prometheus.GaugeVec
+	ClusterMemberUp      *prometheus.GaugeVec
+	OwnedServices        prometheus.Gauge
+	HALeader             prometheus.Gauge
+	HATakeovers          prometheus.Counter
+	AlertQueueDepth      *prometheus.GaugeVec
+	AlertRetries         *prometheus.CounterVec
+	AlertDeliveries      *prometheus.CounterVec
+	AlertOutboxPending   prometheus.Gauge
+	CheckTimeoutRatio    *prometheus.HistogramVec
+	SchedulerQueueDepth  prometheus.Gauge
+	SchedulerWorkers     prometheus.Gauge
+	SchedulerWorkersBusy prometheus.Gauge
+	SchedulerDropped     prometheus.Counter
 
 	mu          sync.Mutex
 	initialized map[string]struct{}
@@ -55,13 +106,43 @@ func NewBundle() *Bundle {
 		col.CheckLatencySeconds,
 		col.Up,
 		col.LastSuccessTimestamp,
+		col.DomainExpiry,
+		col.NTPOffsetSeconds,
+		col.NTPStratum,
 		col.BuildInfo,
 		col.ConfigReloadSuccess,
+		col.ScheduleChanges,
+		col.ScheduledServices,
+		col.ScheduleLoad,
+		col.SchedulerLagSeconds,
+		col.SchedulerDelayed,
+		col.SkippedOverlap,
+		col.SkippedPaused,
+		col.ServicePaused,
+		col.LocationUp,
+		col.LocationCheckTotal,
+		col.FailingLocations,
+		col.ClusterMemberUp,
+		col.OwnedServices,
+		col.HALeader,
+		col.HATakeovers,
+		col.AlertQueueDepth,
+		col.AlertRetries,
+		col.AlertDeliveries,
+		col.AlertOutboxPending,
+		col.CheckTimeoutRatio,
+		col.SchedulerQueueDepth,
+		col.SchedulerWorkers,
+		col.SchedulerWorkersBusy,
+		col.SchedulerDropped,
 	)
 
 	// Set build info immediately
 	col.BuildInfo.WithLabelValues(runtime.Version(), runtime.GOOS, runtime.GOARCH).Set(1)
 	col.ConfigReloadSuccess.Set(1)
+	for _, change := range []string{ChangeAdded, ChangeChanged, ChangeRemoved} {
+		col.ScheduleChanges.WithLabelValues(change).Add(0)
+	}
 
 	return &Bundle{Registry: reg, Collector: col}
 }
@@ -103,6 +184,30 @@ func newCollector() *Collector {
 			serviceLabels,
 		),
 
+		DomainExpiry: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "uptiq_domain_expiry_timestamp",
+				Help: "Unix timestamp at which the domain registration expires.",
+			},
+			serviceLabels,
+		),
+
+		NTPOffsetSeconds: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "uptiq_ntp_offset_seconds",
+				Help: "Offset of the NTP server clock from the local clock in seconds.",
+			},
+			serviceLabels,
+		),
+
+		NTPStratum: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "uptiq_ntp_stratum",
+				Help: "Stratum reported by the NTP server.",
+			},
+			serviceLabels,
+		),
+
 		BuildInfo: prometheus.NewGaugeVec(
 			prometheus.GaugeOpts{
 				Name: "uptiq_build_info",
@@ -118,6 +223,190 @@ func newCollector() *Collector {
 			},
 		),
 
+		ScheduleChanges: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "uptiq_schedule_changes_total",
+				Help: "Services added, changed or removed by schedule rebuilds.",
+			},
+			[]string{LabelChange},
+		),
+
+		ScheduledServices: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "uptiq_scheduled_services",
+				Help: "Number of services currently scheduled.",
+			},
+		),
+
+		ScheduleLoad: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "uptiq_schedule_load",
+				Help: "Projected checks per minute starting in each second of the minute.",
+			},
+			[]string{LabelSecond},
+		),
+
+		SchedulerLagSeconds: prometheus.NewHistogram(
+			prometheus.HistogramOpts{
+				Name:    "uptiq_scheduler_lag_seconds",
+				Help:    "Delay between a check's planned run time and a worker starting it.",
+				Buckets: []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+			},
+		),
+
+		SchedulerDelayed: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "uptiq_scheduler_delayed_total",
+				Help: "Check dispatches delayed by a concurrency or rate limit.",
+			},
+			[]string{LabelLimit},
+		),
+
+		SkippedOverlap: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "uptiq_check_skipped_overlap_total",
+				Help: "Checks skipped because the previous run of the service was still in flight.",
+			},
+			serviceLabels,
+		),
+
+		SkippedPaused: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "uptiq_check_skipped_paused_total",
+				Help: "Scheduled checks skipped because the service was paused.",
+			},
+			serviceLabels,
+		),
+
+		ServicePaused: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "uptiq_service_paused",
+				Help: "1 if the service's checks are paused via the API, else 0.",
+			},
+			serviceLabels,
+		),
+
+		LocationUp: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "uptiq_location_up",
+				Help: "Last check result per probing location (1 = up, 0 = down).",
+			},
+			append(serviceLabels, LabelLocation),
+		),
+
+		LocationCheckTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "uptiq_location_check_total",
+				Help: "Checks per probing location, labeled by result.",
+			},
+			append(serviceLabels, LabelLocation, LabelResult),
+		),
+
+		FailingLocations: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "uptiq_quorum_failing_locations",
+				Help: "Locations with a recent failing result for the service.",
+			},
+			serviceLabels,
+		),
+
+		ClusterMemberUp: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "uptiq_cluster_member_up",
+				Help: "Whether a cluster member is alive as seen by this instance (1 = alive).",
+			},
+			[]string{LabelPeer},
+		),
+
+		OwnedServices: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "uptiq_cluster_owned_services",
+				Help: "Number of services this cluster member is responsible for.",
+			},
+		),
+
+		HALeader: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "uptiq_ha_leader",
+				Help: "Whether this instance holds the HA lease and sends alerts (1 = leader).",
+			},
+		),
+
+		HATakeovers: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Name: "uptiq_ha_takeovers_total",
+				Help: "Times this instance took over as HA leader after the lease expired.",
+			},
+		),
+
+		AlertQueueDepth: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "uptiq_alert_queue_depth",
+				Help: "Alerts waiting to be sent, per channel.",
+			},
+			[]string{LabelChannel},
+		),
+
+		AlertRetries: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "uptiq_alert_retries_total",
+				Help: "Alert sends retried after a transient failure.",
+			},
+			[]string{LabelChannel},
+		),
+
+		AlertDeliveries: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "uptiq_alert_deliveries_total",
+				Help: "Alerts by final outcome (delivered, failed, dropped, expired).",
+			},
+			[]string{LabelChannel, LabelResult},
+		),
+
+		AlertOutboxPending: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "uptiq_alert_outbox_pending",
+				Help: "Alerts persisted in the outbox and not yet delivered.",
+			},
+		),
+
+		CheckTimeoutRatio: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "uptiq_check_timeout_ratio",
+				Help:    "Check duration as a fraction of the service timeout (1 = timed out).",
+				Buckets: []float64{0.1, 0.25, 0.5, 0.75, 0.9, 0.99, 1},
+			},
+			serviceLabels,
+		),
+
+		SchedulerQueueDepth: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "uptiq_scheduler_queue_depth",
+				Help: "Checks dispatched and waiting for a free worker.",
+			},
+		),
+
+		SchedulerWorkers: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "uptiq_scheduler_workers",
+				Help: "Number of check workers.",
+			},
+		),
+
+		SchedulerWorkersBusy: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "uptiq_scheduler_workers_busy",
+				Help: "Number of workers currently running a check.",
+			},
+		),
+
+		SchedulerDropped: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Name: "uptiq_scheduler_dropped_total",
+				Help: "Checks dispatched but dropped at shutdown before a worker started them.",
+			},
+		),
+
 		initialized: make(map[string]struct{}),
 	}
 }
@@ -144,11 +433,65 @@ func (c *Collector) EnsureServices(services []config.Service) {
 		c.CheckTotal.WithLabelValues(svc.ID, svc.Name, svc.Type, ResultSuccess).Add(0)
 		c.CheckTotal.WithLabelValues(svc.ID, svc.Name, svc.Type, ResultFailure).Add(0)
 
+		c.SkippedOverlap.WithLabelValues(labels...).Add(0)
+		c.SkippedPaused.WithLabelValues(labels...).Add(0)
+		c.ServicePaused.WithLabelValues(labels...).Set(0)
+
 		// Touch histogram
 		_, _ = c.CheckLatencySeconds.GetMetricWithLabelValues(labels...)
 	}
 }
 
+// RetainServices drops the series of every service not in the list, e.g.
+// after the services moved to another cluster member.
+func (c *Collector) RetainServices(services []config.Service) {
+	keep := make(map[string]struct{}, len(services))
+	for _, svc := range services {
+		keep[svc.ID] = struct{}{}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key := range c.initialized {
+		id, _, _ := strings.Cut(key, "|")
+		if _, ok := keep[id]; ok {
+			continue
+		}
+		delete(c.initialized, key)
+
+		labels := prometheus.Labels{LabelServiceID: id}
+		for _, vec := range c.serviceVecs() {
+			vec.DeletePartialMatch(labels)
+		}
+	}
+}
+
+// seriesDeleter is implemented by every prometheus metric vector.
+type seriesDeleter interface {
+	DeletePartialMatch(labels prometheus.Labels) int
+}
+
+// serviceVecs lists the metrics labeled by service.
+func (c *Collector) serviceVecs() []seriesDeleter {
+	return []seriesDeleter{
+		c.CheckTotal,
+		c.CheckLatencySeconds,
+		c.Up,
+		c.LastSuccessTimestamp,
+		c.DomainExpiry,
+		c.NTPOffsetSeconds,
+		c.NTPStratum,
+		c.SkippedOverlap,
+		c.SkippedPaused,
+		c.ServicePaused,
+		c.LocationUp,
+		c.LocationCheckTotal,
+		c.FailingLocations,
+		c.CheckTimeoutRatio,
+	}
+}
+
 // Observe records a check result in metrics.
 func (c *Collector) Observe(svc config.Service, res checks.Result) {
 	labels := []string{svc.ID, svc.Name, svc.Type}
@@ -163,4 +506,80 @@ func (c *Collector) Observe(svc config.Service, res checks.Result) {
 		c.CheckTotal.WithLabelValues(svc.ID, svc.Name, svc.Type, ResultFailure).Inc()
 		c.Up.WithLabelValues(labels...).Set(0)
 	}
+
+	if !res.Expiry.IsZero() {
+		c.DomainExpiry.WithLabelValues(labels...).Set(float64(res.Expiry.Unix()))
+	}
+
+	if res.Stratum > 0 {
+		c.NTPOffsetSeconds.WithLabelValues(labels...).Set(res.ClockOffset.Seconds())
+		c.NTPStratum.WithLabelValues(labels...).Set(float64(res.Stratum))
+	}
+}
+
+// ObserveScheduleChanges records the outcome of a schedule rebuild.
+func (c *Collector) ObserveScheduleChanges(added, changed, removed, scheduled int) {
+	c.ScheduleChanges.WithLabelValues(ChangeAdded).Add(float64(added))
+	c.ScheduleChanges.WithLabelValues(ChangeChanged).Add(float64(changed))
+	c.ScheduleChanges.WithLabelValues(ChangeRemoved).Add(float64(removed))
+	c.ScheduledServices.Set(float64(scheduled))
+}
+
+// SetScheduleLoad records the projected per-second load of the schedule.
+func (c *Collector) SetScheduleLoad(load [60]float64) {
+	for second, v := range load {
+		c.ScheduleLoad.WithLabelValues(strconv.Itoa(second)).Set(v)
+	}
+}
+
+// ObserveSkippedOverlap counts a check skipped because its previous run was still in flight.
+func (c *Collector) ObserveSkippedOverlap(svc config.Service) {
+	c.SkippedOverlap.WithLabelValues(svc.ID, svc.Name, svc.Type).Inc()
+}
+
+// ObserveSkippedPaused counts a scheduled check skipped because the service was paused.
+func (c *Collector) ObserveSkippedPaused(svc config.Service) {
+	c.SkippedPaused.WithLabelValues(svc.ID, svc.Name, svc.Type).Inc()
+}
+
+// SetPaused records whether a service's checks are paused.
+func (c *Collector) SetPaused(svc config.Service, paused bool) {
+	v := 0.0
+	if paused {
+		v = 1
+	}
+	c.ServicePaused.WithLabelValues(svc.ID, svc.Name, svc.Type).Set(v)
+}
+
+// ObserveLocation records a check result from a probing location.
+func (c *Collector) ObserveLocation(svc config.Service, location string, res checks.Result) {
+	if res.Success {
+		c.LocationCheckTotal.WithLabelValues(svc.ID, svc.Name, svc.Type, location, ResultSuccess).Inc()
+		c.LocationUp.WithLabelValues(svc.ID, svc.Name, svc.Type, location).Set(1)
+	} else {
+		c.LocationCheckTotal.WithLabelValues(svc.ID, svc.Name, svc.Type, location, ResultFailure).Inc()
+		c.LocationUp.WithLabelValues(svc.ID, svc.Name, svc.Type, location).Set(0)
+	}
+}
+
+// ObserveAlertDelivery records the final outcome of an alert sent to channel.
+func (c *Collector) ObserveAlertDelivery(channel, result string) {
+	c.AlertDeliveries.WithLabelValues(channel, result).Inc()
+}
+
+// SetClusterMember records whether a cluster member is alive.
+func (c *Collector) SetClusterMember(peer string, alive bool) {
+	v := 0.0
+	if alive {
+		v = 1
+	}
+	c.ClusterMemberUp.WithLabelValues(peer).Set(v)
+}
+
+// ObserveTimeoutRatio records how much of its timeout a check used.
+func (c *Collector) ObserveTimeoutRatio(svc config.Service, elapsed, timeout time.Duration) {
+	if timeout <= 0 {
+		return
+	}
+	c.CheckTimeoutRatio.WithLabelValues(svc.ID, svc.Name, svc.Type).Observe(elapsed.Seconds() / timeout.Seconds())
 }
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	io_prometheus_client "github.com/prometheus/client_model/go"
 
 	"uptiq/internal/checks"
@@ -408,6 +409,47 @@ func TestCollector_MultipleServices(t *testing.T) {
 	}
 }
 
+func TestCollector_Observe_NTP(t *testing.T) {
+	bundle := NewBundle()
+
+	svc := config.Service{ID: "ntp", Name: "NTP", Type: "ntp"}
+	bundle.Collector.Observe(svc, checks.Result{
+		Success:     false,
+		ClockOffset: -1500 * time.Millisecond,
+		Stratum:     3,
+	})
+
+	offset := &io_prometheus_client.Metric{}
+	if err := bundle.Collector.NTPOffsetSeconds.WithLabelValues("ntp", "NTP", "ntp").Write(offset); err != nil {
+		t.Fatalf("write offset metric: %v", err)
+	}
+	if got := offset.GetGauge().GetValue(); got != -1.5 {
+		t.Errorf("ntp offset = %v, want -1.5", got)
+	}
+
+	stratum := &io_prometheus_client.Metric{}
+	if err := bundle.Collector.NTPStratum.WithLabelValues("ntp", "NTP", "ntp").Write(stratum); err != nil {
+		t.Fatalf("write stratum metric: %v", err)
+	}
+	if got := stratum.GetGauge().GetValue(); got != 3 {
+		t.Errorf("ntp stratum = %v, want 3", got)
+	}
+}
+
+func TestCollector_Observe_NTPNoReply(t *testing.T) {
+	bundle := NewBundle()
+
+	svc := config.Service{ID: "ntp", Name: "NTP", Type: "ntp"}
+	bundle.Collector.Observe(svc, checks.Result{Success: false, Error: "timeout"})
+
+	families, _ := bundle.Registry.Gather()
+	for _, f := range families {
+		if f.GetName() == "uptiq_ntp_offset_seconds" && len(f.GetMetric()) > 0 {
+			t.Error("ntp offset should not be recorded without a reply")
+		}
+	}
+}
+
 func TestNewCollector(t *testing.T) {
 	col := newCollector()
 
@@ -570,3 +612,170 @@ func TestCollector_MetricHelp(t *testing.T) {
 		}
 	}
 }
+
+func TestCollector_ObserveScheduleChanges(t *testing.T) {
+	bundle := NewBundle()
+
+	bundle.Collector.ObserveScheduleChanges(3, 0, 0, 3)
+	bundle.Collector.ObserveScheduleChanges(1, 2, 1, 3)
+
+	families, err := bundle.Registry.Gather()
+	if err != nil {
+		t.Fatalf("failed to gather metrics: %v", err)
+	}
+
+	changes := make(map[string]float64)
+	var scheduled float64
+	for _, f := range families {
+		switch f.GetName() {
+		case "uptiq_schedule_changes_total":
+			for _, m := range f.GetMetric() {
+				for _, l := range m.GetLabel() {
+					if l.GetName() == LabelChange {
+						changes[l.GetValue()] = m.GetCounter().GetValue()
+					}
+				}
+			}
+		case "uptiq_scheduled_services":
+			scheduled = f.GetMetric()[0].GetGauge().GetValue()
+		}
+	}
+
+	want := map[string]float64{ChangeAdded: 4, ChangeChanged: 2, ChangeRemoved: 1}
+	for change, v := range want {
+		if changes[change] != v {
+			t.Errorf("schedule_changes_total{change=%q} = %v, want %v", change, changes[change], v)
+		}
+	}
+	if scheduled != 3 {
+		t.Errorf("scheduled_services = %v, want 3", scheduled)
+	}
+}
+
+func TestCollector_SetScheduleLoad(t *testing.T) {
+	bundle := NewBundle()
+
+	var load [60]float64
+	load[5] = 2
+	load[35] = 0.5
+	bundle.Collector.SetScheduleLoad(load)
+
+	families, err := bundle.Registry.Gather()
+	if err != nil {
+		t.Fatalf("failed to gather metrics: %v", err)
+	}
+
+	values := make(map[string]float64)
+	for _, f := range families {
+		if f.GetName() != "uptiq_schedule_load" {
+			continue
+		}
+		for _, m := range f.GetMetric() {
+			values[m.GetLabel()[0].GetValue()] = m.GetGauge().GetValue()
+		}
+	}
+
+	if len(values) != 60 {
+		t.Errorf("got %d series, want one per second (60)", len(values))
+	}
+	if values["5"] != 2 || values["35"] != 0.5 || values["0"] != 0 {
+		t.Errorf("unexpected load values: 0=%v 5=%v 35=%v", values["0"], values["5"], values["35"])
+	}
+}
+
+func TestCollector_ObserveTimeoutRatio(t *testing.T) {
+	bundle := NewBundle()
+	svc := config.Service{ID: "svc", Name: "Service", Type: "http"}
+
+	bundle.Collector.ObserveTimeoutRatio(svc, 250*time.Millisecond, time.Second)
+	bundle.Collector.ObserveTimeoutRatio(svc, time.Second, time.Second)
+	bundle.Collector.ObserveTimeoutRatio(svc, time.Second, 0) // No timeout: ignored
+
+	families, err := bundle.Registry.Gather()
+	if err != nil {
+		t.Fatalf("failed to gather metrics: %v", err)
+	}
+
+	for _, f := range families {
+		if f.GetName() != "uptiq_check_timeout_ratio" {
+			continue
+		}
+		h := f.GetMetric()[0].GetHistogram()
+		if h.GetSampleCount() != 2 {
+			t.Errorf("sample count = %d, want 2", h.GetSampleCount())
+		}
+		if h.GetSampleSum() != 1.25 {
+			t.Errorf("sample sum = %v, want 1.25", h.GetSampleSum())
+		}
+		return
+	}
+	t.Error("uptiq_check_timeout_ratio not found")
+}
+
+func TestCollector_ObserveLocation(t *testing.T) {
+	bundle := NewBundle()
+	svc := config.Service{ID: "svc", Name: "Service", Type: "http"}
+
+	bundle.Collector.ObserveLocation(svc, "eu-west", checks.Result{Success: true})
+	bundle.Collector.ObserveLocation(svc, "us-east", checks.Result{Success: false})
+	bundle.Collector.ObserveLocation(svc, "us-east", checks.Result{Success: false})
+
+	if got := testutil.ToFloat64(bundle.Collector.LocationUp.WithLabelValues("svc", "Service", "http", "eu-west")); got != 1 {
+		t.Errorf("location_up{eu-west} = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(bundle.Collector.LocationUp.WithLabelValues("svc", "Service", "http", "us-east")); got != 0 {
+		t.Errorf("location_up{us-east} = %v, want 0", got)
+	}
+	if got := testutil.ToFloat64(bundle.Collector.LocationCheckTotal.WithLabelValues("svc", "Service", "http", "us-east", ResultFailure)); got != 2 {
+		t.Errorf("location_check_total{us-east,failure} = %v, want 2", got)
+	}
+}
+
+func TestCollector_RetainServices(t *testing.T) {
+	bundle := NewBundle()
+	keep := config.Service{ID: "keep", Name: "Keep", Type: "http"}
+	moved := config.Service{ID: "moved", Name: "Moved", Type: "tcp"}
+
+	bundle.Collector.EnsureServices([]config.Service{keep, moved})
+	bundle.Collector.Observe(moved, checks.Result{Success: true})
+	bundle.Collector.ObserveLocation(moved, "eu-west", checks.Result{Success: true})
+
+	bundle.Collector.RetainServices([]config.Service{keep})
+
+	families, err := bundle.Registry.Gather()
+	if err != nil {
+		t.Fatalf("failed to gather metrics: %v", err)
+	}
+	for _, f := range families {
+		for _, m := range f.GetMetric() {
+			for _, l := range m.GetLabel() {
+				if l.GetName() == LabelServiceID && l.GetValue() == "moved" {
+					t.Errorf("%s still has a series for the moved service", f.GetName())
+				}
+			}
+		}
+	}
+	if got := testutil.ToFloat64(bundle.Collector.Up.WithLabelValues("keep", "Keep", "http")); got != 0 {
+		t.Errorf("up{keep} = %v, want the retained series", got)
+	}
+
+	// Ensuring a service again after it moves back recreates its series
+	bundle.Collector.EnsureServices([]config.Service{moved})
+	if n := testutil.CollectAndCount(bundle.Collector.CheckTotal); n != 4 {
+		t.Errorf("check_total series = %d, want 4 after the service returns", n)
+	}
+}
+
+func TestCollector_SetClusterMember(t *testing.T) {
+	bundle := NewBundle()
+
+	bundle.Collector.SetClusterMember("a", true)
+	bundle.Collector.SetClusterMember("b", false)
+
+	if got := testutil.ToFloat64(bundle.Collector.ClusterMemberUp.WithLabelValues("a")); got != 1 {
+		t.Errorf("cluster_member_up{a} = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(bundle.Collector.ClusterMemberUp.WithLabelValues("b")); got != 0 {
+		t.Errorf("cluster_member_up{b} = %v, want 0", got)
+	}
+}
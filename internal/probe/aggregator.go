@@ -0,0 +1,272 @@
+package probe
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"uptiq/internal/checks"
+	"uptiq/internal/config"
+	"uptiq/internal/metrics"
+)
+
+// Report errors.
+var (
+	ErrNoLocation  = errors.New("report has no location")
+	ErrOwnLocation = errors.New("report uses the primary's own location")
+)
+
+// ResultHandler receives quorum-adjusted results, normally the alerting engine.
+type ResultHandler interface {
+	HandleResult(svc config.Service, res checks.Result)
+}
+
+// outageTracker mirrors scheduler.OutageTracker so the aggregator can pass
+// it through from the handler it wraps.
+type outageTracker interface {
+	OutageConfirmed(serviceID string) bool
+}
+
+// Settings holds the quorum settings that can change at runtime.
+type Settings struct {
+	Quorum     int           // Failing locations needed; 0 = a majority
+	StaleAfter time.Duration // How long a location's last result counts
+}
+
+// SettingsFromConfig extracts quorum settings from the probing config.
+func SettingsFromConfig(cfg config.ProbingConfig) (Settings, error) {
+	staleAfter := cfg.StaleAfter
+	if staleAfter == "" {
+		staleAfter = config.DefaultProbeStaleAfter
+	}
+	d, err := time.ParseDuration(staleAfter)
+	if err != nil {
+		return Settings{}, fmt.Errorf("parse probing.stale_after: %w", err)
+	}
+	return Settings{Quorum: cfg.Quorum, StaleAfter: d}, nil
+}
+
+// sample is a location's latest result for a service.
+type sample struct {
+	res checks.Result
+	at  time.Time
+}
+
+// Aggregator runs on the primary between the scheduler and the alerting
+// engine. It records the latest result from every location; each time the
+// primary's own check of a service completes, it forwards a result that is
+// failing only if a quorum of locations with recent results are failing.
+type Aggregator struct {
+	location string
+	next     ResultHandler
+	metrics  *metrics.Collector
+	log      *slog.Logger
+
+	mu       sync.Mutex
+	settings Settings
+	services map[string]config.Service
+	latest   map[string]map[string]sample // Service ID → location → sample
+	down     map[string]bool              // Services last forwarded as failing
+}
+
+// NewAggregator creates an aggregator for the primary's location.
+func NewAggregator(cfg config.ProbingConfig, next ResultHandler, m *metrics.Collector, log *slog.Logger) (*Aggregator, error) {
+	if log == nil {
+		log = slog.Default()
+	}
+
+	settings, err := SettingsFromConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Aggregator{
+		location: cfg.Location,
+		next:     next,
+		metrics:  m,
+		log:      log,
+		settings: settings,
+		services: make(map[string]config.Service),
+		latest:   make(map[string]map[string]sample),
+		down:     make(map[string]bool),
+	}, nil
+}
+
+// UpdateSettings changes the quorum settings. The location is fixed for
+// the aggregator's lifetime.
+func (a *Aggregator) UpdateSettings(settings Settings) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.settings = settings
+}
+
+// SetServices sets the services reports are accepted for. Results for
+// services no longer configured are forgotten.
+func (a *Aggregator) SetServices(services []config.Service) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	clear(a.services)
+	for _, svc := range services {
+		a.services[svc.ID] = svc
+	}
+	for id := range a.latest {
+		if _, ok := a.services[id]; !ok {
+			delete(a.latest, id)
+			delete(a.down, id)
+		}
+	}
+}
+
+// HandleResult records the primary's own result and forwards the quorum
+// decision to the next handler.
+func (a *Aggregator) HandleResult(svc config.Service, res checks.Result) {
+	now := time.Now()
+
+	a.mu.Lock()
+	a.record(svc.ID, a.location, res, now)
+	decided := a.decide(svc, res, now)
+	a.mu.Unlock()
+
+	if a.metrics != nil {
+		a.metrics.ObserveLocation(svc, a.location, res)
+	}
+	a.next.HandleResult(svc, decided)
+}
+
+// Report records results from a remote probe. Results for unknown services
+// are ignored.
+func (a *Aggregator) Report(r Report) (ReportResponse, error) {
+	switch r.Location {
+	case "":
+		return ReportResponse{}, ErrNoLocation
+	case a.location:
+		return ReportResponse{}, ErrOwnLocation
+	}
+
+	type observation struct {
+		svc config.Service
+		res checks.Result
+	}
+
+	now := time.Now()
+	var observed []observation
+
+	a.mu.Lock()
+	for _, reported := range r.Results {
+		svc, ok := a.services[reported.ServiceID]
+		if !ok {
+			continue
+		}
+
+		// Never trust a probe's clock to be ahead of ours
+		at := reported.CheckedAt
+		if at.IsZero() || at.After(now) {
+			at = now
+		}
+
+		res := reported.CheckResult()
+		a.record(svc.ID, r.Location, res, at)
+		observed = append(observed, observation{svc: svc, res: res})
+	}
+	a.mu.Unlock()
+
+	resp := ReportResponse{Accepted: len(observed), Ignored: len(r.Results) - len(observed)}
+	for _, o := range observed {
+		if a.metrics != nil {
+			a.metrics.ObserveLocation(o.svc, r.Location, o.res)
+		}
+		if !o.res.Success && a.OutageConfirmed(o.svc.ID) && !slices.Contains(resp.Confirmed, o.svc.ID) {
+			resp.Confirmed = append(resp.Confirmed, o.svc.ID)
+		}
+	}
+
+	if resp.Ignored > 0 {
+		a.log.Debug("probe results ignored for unknown services", "location", r.Location, "ignored", resp.Ignored)
+	}
+	return resp, nil
+}
+
+// OutageConfirmed passes the question on to the wrapped handler.
+func (a *Aggregator) OutageConfirmed(serviceID string) bool {
+	if tracker, ok := a.next.(outageTracker); ok {
+		return tracker.OutageConfirmed(serviceID)
+	}
+	return false
+}
+
+func (a *Aggregator) record(serviceID, location string, res checks.Result, at time.Time) {
+	byLocation, ok := a.latest[serviceID]
+	if !ok {
+		byLocation = make(map[string]sample)
+		a.latest[serviceID] = byLocation
+	}
+	if prev, ok := byLocation[location]; ok && prev.at.After(at) {
+		return // Out-of-order report
+	}
+	byLocation[location] = sample{res: res, at: at}
+}
+
+// decide turns the primary's own result into the quorum result. A service
+// is down when at least quorum locations (0 = a majority of those that
+// reported) with a result newer than staleAfter are failing. When fewer
+// locations are fresh than the quorum, the last decision stands unless no
+// fresh location is failing: a lone failing primary never pages.
+func (a *Aggregator) decide(svc config.Service, local checks.Result, now time.Time) checks.Result {
+	var failing []string
+	fresh := 0
+	for location, s := range a.latest[svc.ID] {
+		if now.Sub(s.at) > a.settings.StaleAfter {
+			continue
+		}
+		fresh++
+		if !s.res.Success {
+			failing = append(failing, fmt.Sprintf("%s (%s)", location, s.res.Error))
+		}
+	}
+	slices.Sort(failing)
+
+	if a.metrics != nil {
+		a.metrics.FailingLocations.WithLabelValues(svc.ID, svc.Name, svc.Type).Set(float64(len(failing)))
+	}
+
+	quorum := a.settings.Quorum
+	if quorum == 0 {
+		quorum = len(a.latest[svc.ID])/2 + 1
+	}
+
+	if len(failing) == 0 {
+		delete(a.down, svc.ID)
+		return local
+	}
+
+	summary := fmt.Sprintf("failing in %d/%d locations: %s", len(failing), fresh, strings.Join(failing, ", "))
+	down := len(failing) >= quorum
+	if fresh < quorum {
+		down = a.down[svc.ID]
+		summary += fmt.Sprintf("; too few recent locations for a quorum of %d", quorum)
+	}
+	a.down[svc.ID] = down
+
+	res := local
+	if down {
+		res.Success = false
+		res.Error = summary
+		res.Warning = ""
+		return res
+	}
+
+	res.Success = true
+	res.Error = ""
+	if local.Success && local.Warning != "" {
+		res.Warning = local.Warning + "; " + summary
+	} else {
+		res.Warning = summary
+	}
+	return res
+}
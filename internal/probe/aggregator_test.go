@@ -0,0 +1,255 @@
+package probe
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"uptiq/internal/checks"
+	"uptiq/internal/config"
+	"uptiq/internal/metrics"
+)
+
+type recordingHandler struct {
+	results []checks.Result
+}
+
+func (h *recordingHandler) HandleResult(svc config.Service, res checks.Result) {
+	h.results = append(h.results, res)
+}
+
+func (h *recordingHandler) OutageConfirmed(serviceID string) bool { return serviceID == "down" }
+
+func (h *recordingHandler) last() checks.Result { return h.results[len(h.results)-1] }
+
+func newTestAggregator(t *testing.T, quorum int, staleAfter string) (*Aggregator, *recordingHandler) {
+	t.Helper()
+
+	next := &recordingHandler{}
+	agg, err := NewAggregator(config.ProbingConfig{
+		Mode:       "primary",
+		Location:   "primary",
+		Quorum:     quorum,
+		StaleAfter: staleAfter,
+	}, next, metrics.NewBundle().Collector, nil)
+	if err != nil {
+		t.Fatalf("NewAggregator() error: %v", err)
+	}
+	agg.SetServices([]config.Service{{ID: "web", Name: "Web", Type: "http"}})
+	return agg, next
+}
+
+func report(t *testing.T, agg *Aggregator, location string, success bool, at time.Time) {
+	t.Helper()
+
+	res := Result{ServiceID: "web", Success: success, CheckedAt: at}
+	if !success {
+		res.Error = "timeout"
+	}
+	if _, err := agg.Report(Report{Location: location, Results: []Result{res}}); err != nil {
+		t.Fatalf("Report(%s) error: %v", location, err)
+	}
+}
+
+func TestAggregator_Quorum(t *testing.T) {
+	web := config.Service{ID: "web", Name: "Web", Type: "http"}
+	fail := checks.Result{Success: false, Error: "connection refused"}
+	ok := checks.Result{Success: true}
+
+	tests := []struct {
+		name        string
+		quorum      int
+		remote      map[string]bool // Location → success
+		local       checks.Result
+		wantSuccess bool
+		wantText    string // Expected in Error when failing, in Warning otherwise
+	}{
+		{
+			name:        "single blip is not an outage",
+			remote:      map[string]bool{"eu": true, "us": true},
+			local:       fail,
+			wantSuccess: true,
+			wantText:    "failing in 1/3 locations: primary (connection refused)",
+		},
+		{
+			name:        "majority down",
+			remote:      map[string]bool{"eu": false, "us": true},
+			local:       fail,
+			wantSuccess: false,
+			wantText:    "failing in 2/3 locations: eu (timeout), primary (connection refused)",
+		},
+		{
+			name:        "remote quorum overrides local success",
+			remote:      map[string]bool{"eu": false, "us": false},
+			local:       ok,
+			wantSuccess: false,
+			wantText:    "failing in 2/3 locations",
+		},
+		{
+			name:        "explicit quorum not reached",
+			quorum:      3,
+			remote:      map[string]bool{"eu": false, "us": true},
+			local:       fail,
+			wantSuccess: true,
+			wantText:    "failing in 2/3 locations",
+		},
+		{
+			name:        "too few fresh locations for quorum",
+			quorum:      3,
+			local:       fail,
+			wantSuccess: true,
+			wantText:    "failing in 1/1 locations: primary (connection refused); too few recent locations for a quorum of 3",
+		},
+		{
+			name:        "all up",
+			remote:      map[string]bool{"eu": true},
+			local:       ok,
+			wantSuccess: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			agg, next := newTestAggregator(t, tc.quorum, "5m")
+			for location, success := range tc.remote {
+				report(t, agg, location, success, time.Now())
+			}
+
+			agg.HandleResult(web, tc.local)
+
+			got := next.last()
+			if got.Success != tc.wantSuccess {
+				t.Fatalf("success = %v, want %v (error %q, warning %q)", got.Success, tc.wantSuccess, got.Error, got.Warning)
+			}
+			text := got.Warning
+			if !got.Success {
+				text = got.Error
+			}
+			if tc.wantText == "" && text != "" {
+				t.Errorf("unexpected annotation %q", text)
+			}
+			if !strings.Contains(text, tc.wantText) {
+				t.Errorf("annotation = %q, want it to contain %q", text, tc.wantText)
+			}
+		})
+	}
+}
+
+func TestAggregator_IgnoresStaleLocations(t *testing.T) {
+	agg, next := newTestAggregator(t, 0, "1m")
+
+	report(t, agg, "eu", false, time.Now().Add(-2*time.Minute))
+	report(t, agg, "us", true, time.Now())
+
+	agg.HandleResult(config.Service{ID: "web"}, checks.Result{Success: false, Error: "refused"})
+
+	// Fresh: primary (failing) and us (up); majority of 2 is 2
+	if got := next.last(); !got.Success {
+		t.Errorf("stale failure counted: error %q", got.Error)
+	}
+}
+
+func TestAggregator_StaleRemotesKeepLastDecision(t *testing.T) {
+	web := config.Service{ID: "web"}
+	fail := checks.Result{Success: false, Error: "refused"}
+
+	tests := []struct {
+		name        string
+		remote      bool // Success of both remotes while fresh
+		wantSuccess bool
+	}{
+		{name: "up stays up", remote: true, wantSuccess: true},
+		{name: "down stays down", remote: false, wantSuccess: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			agg, next := newTestAggregator(t, 0, "1m")
+
+			report(t, agg, "eu", tc.remote, time.Now())
+			report(t, agg, "us", tc.remote, time.Now())
+			agg.HandleResult(web, fail)
+			if got := next.last(); got.Success != tc.wantSuccess {
+				t.Fatalf("with fresh remotes: success = %v, want %v", got.Success, tc.wantSuccess)
+			}
+
+			// Both remotes go quiet: only the failing primary is fresh
+			agg.mu.Lock()
+			for _, location := range []string{"eu", "us"} {
+				s := agg.latest["web"][location]
+				s.at = time.Now().Add(-2 * time.Minute)
+				agg.latest["web"][location] = s
+			}
+			agg.mu.Unlock()
+
+			agg.HandleResult(web, fail)
+			got := next.last()
+			if got.Success != tc.wantSuccess {
+				t.Errorf("with stale remotes: success = %v, want %v (error %q, warning %q)", got.Success, tc.wantSuccess, got.Error, got.Warning)
+			}
+			if text := got.Error + got.Warning; !strings.Contains(text, "too few recent locations") {
+				t.Errorf("annotation = %q, want it to mention the missing quorum", text)
+			}
+		})
+	}
+}
+
+func TestAggregator_IgnoresOutOfOrderReports(t *testing.T) {
+	agg, next := newTestAggregator(t, 1, "5m")
+
+	now := time.Now()
+	report(t, agg, "eu", true, now)
+	report(t, agg, "eu", false, now.Add(-time.Second))
+
+	agg.HandleResult(config.Service{ID: "web"}, checks.Result{Success: true})
+	if got := next.last(); !got.Success {
+		t.Errorf("older report replaced a newer one: error %q", got.Error)
+	}
+}
+
+func TestAggregator_Report(t *testing.T) {
+	agg, _ := newTestAggregator(t, 0, "5m")
+	agg.SetServices([]config.Service{{ID: "web", Name: "Web", Type: "http"}, {ID: "down", Name: "Down", Type: "http"}})
+
+	resp, err := agg.Report(Report{Location: "eu", Results: []Result{
+		{ServiceID: "web", Success: false},
+		{ServiceID: "down", Success: false},
+		{ServiceID: "unknown", Success: true},
+	}})
+	if err != nil {
+		t.Fatalf("Report() error: %v", err)
+	}
+	if resp.Accepted != 2 || resp.Ignored != 1 {
+		t.Errorf("accepted/ignored = %d/%d, want 2/1", resp.Accepted, resp.Ignored)
+	}
+	// The wrapped handler confirmed the outage of "down" only
+	if len(resp.Confirmed) != 1 || resp.Confirmed[0] != "down" {
+		t.Errorf("confirmed = %v, want [down]", resp.Confirmed)
+	}
+
+	if _, err := agg.Report(Report{Results: []Result{{ServiceID: "web"}}}); !errors.Is(err, ErrNoLocation) {
+		t.Errorf("Report() without location error = %v, want ErrNoLocation", err)
+	}
+	if _, err := agg.Report(Report{Location: "primary"}); !errors.Is(err, ErrOwnLocation) {
+		t.Errorf("Report() from own location error = %v, want ErrOwnLocation", err)
+	}
+}
+
+func TestAggregator_SetServicesForgetsRemoved(t *testing.T) {
+	agg, _ := newTestAggregator(t, 0, "5m")
+	report(t, agg, "eu", false, time.Now())
+
+	agg.SetServices(nil)
+	if len(agg.latest) != 0 {
+		t.Errorf("latest = %v, want results of removed services dropped", agg.latest)
+	}
+}
+
+func TestAggregator_OutageConfirmed(t *testing.T) {
+	agg, _ := newTestAggregator(t, 0, "5m")
+
+	if !agg.OutageConfirmed("down") || agg.OutageConfirmed("web") {
+		t.Error("OutageConfirmed should be delegated to the wrapped handler")
+	}
+}
@@ -0,0 +1,64 @@
+// Package probe implements multi-location probing. Probes check the same
+// services as the primary and report their results to it over HTTP; the
+// primary only treats a service as down when a quorum of locations agree.
+package probe
+
+import (
+	"time"
+
+	"uptiq/internal/checks"
+)
+
+// ReportPath is where the primary accepts reports, relative to its base URL.
+const ReportPath = "/api/probe/results"
+
+// Report is a batch of check results sent by a probe to the primary.
+type Report struct {
+	Location string   `json:"location"`
+	Results  []Result `json:"results"`
+}
+
+// ReportResponse is the primary's answer to a report.
+type ReportResponse struct {
+	Accepted int `json:"accepted"`
+	Ignored  int `json:"ignored"` // Results for services the primary does not check
+
+	// Confirmed lists the reported services whose outage the primary has
+	// confirmed, so probes back off their failure_interval like it does.
+	Confirmed []string `json:"confirmed,omitempty"`
+}
+
+// Result is a check result as reported by a probe.
+type Result struct {
+	ServiceID  string    `json:"service_id"`
+	Success    bool      `json:"success"`
+	StatusCode int       `json:"status_code,omitempty"`
+	LatencyMS  int64     `json:"latency_ms"`
+	Error      string    `json:"error,omitempty"`
+	Warning    string    `json:"warning,omitempty"`
+	CheckedAt  time.Time `json:"checked_at"`
+}
+
+// NewResult converts a check result for reporting.
+func NewResult(serviceID string, res checks.Result, checkedAt time.Time) Result {
+	return Result{
+		ServiceID:  serviceID,
+		Success:    res.Success,
+		StatusCode: res.StatusCode,
+		LatencyMS:  res.Latency.Milliseconds(),
+		Error:      res.Error,
+		Warning:    res.Warning,
+		CheckedAt:  checkedAt,
+	}
+}
+
+// CheckResult converts a reported result back to a check result.
+func (r Result) CheckResult() checks.Result {
+	return checks.Result{
+		Success:    r.Success,
+		StatusCode: r.StatusCode,
+		Latency:    time.Duration(r.LatencyMS) * time.Millisecond,
+		Error:      r.Error,
+		Warning:    r.Warning,
+	}
+}
@@ -0,0 +1,210 @@
+package probe
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"uptiq/internal/checks"
+	"uptiq/internal/config"
+)
+
+// Reporter configuration constants.
+const (
+	reportTimeout   = 5 * time.Second // Per report
+	reportQueueSize = 1000            // Results waiting to be reported
+	maxReportBatch  = 100             // Results per report
+)
+
+// Reporter runs on a probe in place of the alerting engine: it sends check
+// results to the primary. Results are queued and sent off the check path,
+// batched while an earlier report is in flight, so a slow primary does not
+// hold up checks. A report that fails is logged and dropped, as are results
+// arriving while the queue is full; the primary stops counting a location
+// whose results go stale.
+type Reporter struct {
+	log    *slog.Logger
+	client *http.Client
+
+	mu       sync.RWMutex
+	location string
+	url      string
+	token    string
+
+	queue     chan Result
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+
+	// Cancelled when Close gives up waiting, abandoning the report in flight
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// Services whose outage the primary confirmed, from its last answers
+	confirmedMu sync.Mutex
+	confirmed   map[string]bool
+}
+
+// NewReporter creates a reporter for a probe.
+func NewReporter(cfg config.ProbingConfig, log *slog.Logger) *Reporter {
+	if log == nil {
+		log = slog.Default()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &Reporter{
+		log:       log,
+		client:    &http.Client{Timeout: reportTimeout},
+		queue:     make(chan Result, reportQueueSize),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+		ctx:       ctx,
+		cancel:    cancel,
+		confirmed: make(map[string]bool),
+	}
+	r.UpdateConfig(cfg)
+
+	go r.run()
+	return r
+}
+
+// UpdateConfig changes the primary URL and token.
+func (r *Reporter) UpdateConfig(cfg config.ProbingConfig) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.location = cfg.Location
+	r.url = strings.TrimSuffix(cfg.Primary, "/") + ReportPath
+	r.token = cfg.Token
+}
+
+// HandleResult queues a result for the primary.
+func (r *Reporter) HandleResult(svc config.Service, res checks.Result) {
+	select {
+	case r.queue <- NewResult(svc.ID, res, time.Now()):
+	default:
+		r.log.Warn("probe report queue full; dropping result",
+			"service_id", svc.ID,
+			"service_name", svc.Name,
+		)
+	}
+}
+
+// Close sends the results still queued and stops the reporter. If ctx ends
+// first, the rest are abandoned and ctx's error is returned.
+func (r *Reporter) Close(ctx context.Context) error {
+	r.closeOnce.Do(func() { close(r.stop) })
+
+	select {
+	case <-r.done:
+		return nil
+	case <-ctx.Done():
+		r.cancel()
+		return ctx.Err()
+	}
+}
+
+// OutageConfirmed reports whether the primary confirmed the service's
+// outage when it last answered a report of it, so failure_interval backs
+// off on probes as on the primary.
+func (r *Reporter) OutageConfirmed(serviceID string) bool {
+	r.confirmedMu.Lock()
+	defer r.confirmedMu.Unlock()
+
+	return r.confirmed[serviceID]
+}
+
+// run sends queued results until Close, then the ones left.
+func (r *Reporter) run() {
+	defer close(r.done)
+
+	for {
+		select {
+		case res := <-r.queue:
+			r.flush(res)
+		case <-r.stop:
+			for {
+				select {
+				case res := <-r.queue:
+					r.flush(res)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// flush reports first along with whatever else is queued, up to
+// maxReportBatch results.
+func (r *Reporter) flush(first Result) {
+	batch := []Result{first}
+collect:
+	for len(batch) < maxReportBatch {
+		select {
+		case res := <-r.queue:
+			batch = append(batch, res)
+		default:
+			break collect
+		}
+	}
+
+	if err := r.send(batch); err != nil {
+		r.log.Warn("probe report failed",
+			"results", len(batch),
+			"error", err.Error(),
+		)
+	}
+}
+
+func (r *Reporter) send(results []Result) error {
+	r.mu.RLock()
+	location, url, token := r.location, r.url, r.token
+	r.mu.RUnlock()
+
+	body, err := json.Marshal(Report{
+		Location: location,
+		Results:  results,
+	})
+	if err != nil {
+		return fmt.Errorf("encode report: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(r.ctx, reportTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("send report: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("primary returned status %d", resp.StatusCode)
+	}
+
+	// Primaries that predate confirmations answer without them
+	var answer ReportResponse
+	_ = json.NewDecoder(resp.Body).Decode(&answer)
+
+	r.confirmedMu.Lock()
+	for _, res := range results {
+		r.confirmed[res.ServiceID] = slices.Contains(answer.Confirmed, res.ServiceID)
+	}
+	r.confirmedMu.Unlock()
+	return nil
+}
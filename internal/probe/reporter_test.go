@@ -0,0 +1,169 @@
+package probe
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"uptiq/internal/checks"
+	"uptiq/internal/config"
+)
+
+func TestReporter_HandleResult(t *testing.T) {
+	var (
+		mu   sync.Mutex
+		got  Report
+		auth string
+	)
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if r.Method != http.MethodPost || r.URL.Path != ReportPath {
+			t.Errorf("request = %s %s, want POST %s", r.Method, r.URL.Path, ReportPath)
+		}
+		auth = r.Header.Get("Authorization")
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decode report: %v", err)
+		}
+		_ = json.NewEncoder(w).Encode(ReportResponse{Accepted: 1, Confirmed: []string{"web"}})
+	}))
+	defer primary.Close()
+
+	reporter := NewReporter(config.ProbingConfig{
+		Mode:     "probe",
+		Location: "us-east",
+		Token:    "s3cret",
+		Primary:  primary.URL + "/",
+	}, nil)
+
+	before := time.Now()
+	reporter.HandleResult(
+		config.Service{ID: "web"},
+		checks.Result{Success: false, StatusCode: 503, Latency: 120 * time.Millisecond, Error: "unexpected status"},
+	)
+	if err := reporter.Close(context.Background()); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if auth != "Bearer s3cret" {
+		t.Errorf("Authorization = %q, want the probing token", auth)
+	}
+	if got.Location != "us-east" || len(got.Results) != 1 {
+		t.Fatalf("report = %+v, want one result from us-east", got)
+	}
+
+	res := got.Results[0]
+	if res.ServiceID != "web" || res.Success || res.StatusCode != 503 || res.LatencyMS != 120 || res.Error != "unexpected status" {
+		t.Errorf("result = %+v", res)
+	}
+	if res.CheckedAt.Before(before.Truncate(time.Second)) {
+		t.Errorf("checked_at = %v, want about now", res.CheckedAt)
+	}
+
+	// The primary's confirmation drives the probe's failure backoff
+	if !reporter.OutageConfirmed("web") || reporter.OutageConfirmed("api") {
+		t.Error("OutageConfirmed should follow the primary's last answer")
+	}
+}
+
+func TestReporter_SendErrors(t *testing.T) {
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer primary.Close()
+
+	reporter := NewReporter(config.ProbingConfig{Location: "us-east", Token: "wrong", Primary: primary.URL}, nil)
+	defer reporter.Close(context.Background())
+
+	if err := reporter.send([]Result{{ServiceID: "web", Success: true}}); err == nil {
+		t.Error("expected an error for a rejected report")
+	}
+}
+
+func TestReporter_QueuesWhilePrimaryIsSlow(t *testing.T) {
+	release := make(chan struct{})
+	var (
+		mu      sync.Mutex
+		reports []Report
+	)
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var report Report
+		if err := json.NewDecoder(r.Body).Decode(&report); err != nil {
+			t.Errorf("decode report: %v", err)
+		}
+		mu.Lock()
+		reports = append(reports, report)
+		first := len(reports) == 1
+		mu.Unlock()
+
+		if first {
+			<-release
+		}
+	}))
+	defer primary.Close()
+
+	reporter := NewReporter(config.ProbingConfig{Location: "us-east", Primary: primary.URL}, nil)
+
+	// Results are queued rather than sent from the check path, so a
+	// hanging primary does not hold up HandleResult
+	start := time.Now()
+	const n = 10
+	for range n {
+		reporter.HandleResult(config.Service{ID: "web"}, checks.Result{Success: true})
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("HandleResult took %v with a hanging primary", elapsed)
+	}
+
+	close(release)
+	if err := reporter.Close(context.Background()); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	// Results queued behind the first report go out together
+	total := 0
+	for _, r := range reports {
+		total += len(r.Results)
+	}
+	if total != n {
+		t.Errorf("reported %d results, want %d", total, n)
+	}
+	if len(reports) > 3 {
+		t.Errorf("sent %d reports for %d results, want them batched", len(reports), n)
+	}
+}
+
+func TestReporter_CloseAbandonsOnTimeout(t *testing.T) {
+	release := make(chan struct{})
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer primary.Close()
+	defer close(release)
+
+	reporter := NewReporter(config.ProbingConfig{Location: "us-east", Primary: primary.URL}, nil)
+	reporter.HandleResult(config.Service{ID: "web"}, checks.Result{Success: true})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := reporter.Close(ctx); err == nil {
+		t.Error("Close() should report abandoning the report in flight")
+	}
+
+	// The abandoned report is cancelled rather than left to its timeout
+	select {
+	case <-reporter.done:
+	case <-time.After(time.Second):
+		t.Error("reporter still running after Close gave up")
+	}
+}
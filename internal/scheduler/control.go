@@ -0,0 +1,201 @@
+package scheduler
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"uptiq/internal/checks"
+	"uptiq/internal/config"
+)
+
+// Control errors.
+var (
+	ErrUnknownService = errors.New("service not scheduled")
+	ErrUnknownTag     = errors.New("no scheduled service has this tag")
+	ErrCheckRunning   = errors.New("check already in flight")
+)
+
+// do runs cmd on the scheduling loop and waits for it to finish. It fails
+// if the loop does not pick the command up before ctx is done. Once picked
+// up, the command always completes and is waited for, so the caller sees
+// its effects even if ctx expires meanwhile (e.g. RunNow's startRun, which
+// the caller must pair with finishRun).
+func (s *Scheduler) do(ctx context.Context, cmd func(h *scheduleHeap)) error {
+	done := make(chan struct{})
+
+	select {
+	case s.commandCh <- func(h *scheduleHeap) {
+		defer close(done)
+		cmd(h)
+	}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	// Commands run on the loop without blocking, so this wait is short
+	<-done
+	return nil
+}
+
+// RunNow checks a service immediately and returns the result. The result
+// is handled like a scheduled one (metrics, alerts, failure interval), but
+// the service's schedule is left unchanged. Limits do not apply; a service
+// is still never checked concurrently with itself.
+func (s *Scheduler) RunNow(ctx context.Context, serviceID string) (checks.Result, error) {
+	var (
+		svc config.Service
+		err error
+	)
+	if doErr := s.do(ctx, func(h *scheduleHeap) {
+		item, ok := s.items[serviceID]
+		switch {
+		case !ok:
+			err = ErrUnknownService
+		case !s.startRun(serviceID):
+			err = ErrCheckRunning
+		default:
+			svc = item.service
+		}
+	}); doErr != nil {
+		return checks.Result{}, doErr
+	}
+	if err != nil {
+		return checks.Result{}, err
+	}
+	defer s.finishRun(serviceID)
+
+	s.log.Info("running check on demand", "service_id", svc.ID, "service_name", svc.Name)
+
+	// The check runs to its own timeout even if the caller goes away, so
+	// the result is never mistaken for a failure of the service.
+	return s.runCheck(context.Background(), 0, svc), nil
+}
+
+// Pause stops scheduling a service until it is resumed. A check already in
+// flight still completes. Pauses are not persisted across restarts.
+func (s *Scheduler) Pause(ctx context.Context, serviceID string) error {
+	return s.setPaused(ctx, serviceID, true)
+}
+
+// Resume undoes Pause. A service also paused by one of its tags stays paused.
+func (s *Scheduler) Resume(ctx context.Context, serviceID string) error {
+	return s.setPaused(ctx, serviceID, false)
+}
+
+// PauseTag stops scheduling every service with the tag, including services
+// added with it later, until the tag is resumed.
+func (s *Scheduler) PauseTag(ctx context.Context, tag string) error {
+	return s.setTagPaused(ctx, tag, true)
+}
+
+// ResumeTag undoes PauseTag.
+func (s *Scheduler) ResumeTag(ctx context.Context, tag string) error {
+	return s.setTagPaused(ctx, tag, false)
+}
+
+// PausedTags lists the paused tags in order.
+func (s *Scheduler) PausedTags(ctx context.Context) ([]string, error) {
+	var tags []string
+	err := s.do(ctx, func(h *scheduleHeap) {
+		for tag := range s.pausedTags {
+			tags = append(tags, tag)
+		}
+	})
+	slices.Sort(tags)
+	return tags, err
+}
+
+func (s *Scheduler) setPaused(ctx context.Context, serviceID string, paused bool) error {
+	var err error
+	if doErr := s.do(ctx, func(h *scheduleHeap) {
+		item, ok := s.items[serviceID]
+		if !ok {
+			err = ErrUnknownService
+			return
+		}
+
+		if paused {
+			s.pausedServices[serviceID] = struct{}{}
+		} else {
+			delete(s.pausedServices, serviceID)
+			s.resumeItem(h, item, time.Now())
+		}
+		s.observePaused()
+	}); doErr != nil {
+		return doErr
+	}
+	if err == nil {
+		s.log.Info("service pause changed", "service_id", serviceID, "paused", paused)
+	}
+	return err
+}
+
+func (s *Scheduler) setTagPaused(ctx context.Context, tag string, paused bool) error {
+	var err error
+	if doErr := s.do(ctx, func(h *scheduleHeap) {
+		var tagged []*scheduledItem
+		for _, item := range s.items {
+			if slices.Contains(item.service.Tags, tag) {
+				tagged = append(tagged, item)
+			}
+		}
+		if len(tagged) == 0 {
+			err = ErrUnknownTag
+			return
+		}
+
+		if paused {
+			s.pausedTags[tag] = struct{}{}
+		} else {
+			delete(s.pausedTags, tag)
+			now := time.Now()
+			for _, item := range tagged {
+				s.resumeItem(h, item, now)
+			}
+		}
+		s.observePaused()
+	}); doErr != nil {
+		return doErr
+	}
+	if err == nil {
+		s.log.Info("tag pause changed", "tag", tag, "paused", paused)
+	}
+	return err
+}
+
+// resumeItem runs a resumed service right away if it missed a run while
+// paused, so its state is fresh.
+func (s *Scheduler) resumeItem(h *scheduleHeap, item *scheduledItem, now time.Time) {
+	if s.paused(item.service) || !item.missedRun {
+		return
+	}
+	item.missedRun = false
+	item.due = time.Time{}
+	item.nextRun = now
+	heap.Fix(h, item.index)
+}
+
+// paused reports whether a service is paused directly or by a tag.
+func (s *Scheduler) paused(svc config.Service) bool {
+	if _, ok := s.pausedServices[svc.ID]; ok {
+		return true
+	}
+	for _, tag := range svc.Tags {
+		if _, ok := s.pausedTags[tag]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Scheduler) observePaused() {
+	if s.metrics == nil {
+		return
+	}
+	for _, item := range s.items {
+		s.metrics.SetPaused(item.service, s.paused(item.service))
+	}
+}
@@ -0,0 +1,238 @@
+package scheduler
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"uptiq/internal/config"
+	"uptiq/internal/metrics"
+)
+
+// startLoop runs the scheduler in the background until the test ends.
+func startLoop(t *testing.T, sched *Scheduler, services []config.Service) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		_ = sched.Start(ctx, services)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+}
+
+func TestScheduler_RunNow(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{Global: config.GlobalConfig{WorkerCount: 1, Jitter: "0s"}}
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	handler := &mockResultHandler{}
+
+	sched, err := New(cfg, log, nil, handler)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	startLoop(t, sched, []config.Service{
+		{ID: "web", Type: "http", URL: server.URL, Schedule: "@yearly", Timeout: "1s"},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	res, err := sched.RunNow(ctx, "web")
+	if err != nil {
+		t.Fatalf("RunNow() error: %v", err)
+	}
+	if !res.Success || res.StatusCode != http.StatusOK {
+		t.Errorf("result = %+v, want a successful 200", res)
+	}
+	if len(handler.results) != 1 {
+		t.Errorf("handler got %d results, want 1", len(handler.results))
+	}
+
+	if _, err := sched.RunNow(ctx, "missing"); !errors.Is(err, ErrUnknownService) {
+		t.Errorf("RunNow(missing) error = %v, want ErrUnknownService", err)
+	}
+
+	sched.startRun("web")
+	defer sched.finishRun("web")
+	if _, err := sched.RunNow(ctx, "web"); !errors.Is(err, ErrCheckRunning) {
+		t.Errorf("RunNow() during a run error = %v, want ErrCheckRunning", err)
+	}
+}
+
+func TestScheduler_RunNowContextExpiresMidRun(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{Global: config.GlobalConfig{WorkerCount: 1, Jitter: "0s"}}
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	sched, err := New(cfg, log, nil, &mockResultHandler{})
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	startLoop(t, sched, []config.Service{
+		{ID: "web", Type: "http", URL: server.URL, Schedule: "@yearly", Timeout: "1s"},
+	})
+
+	// The caller's ctx expires once the command was accepted: the command's
+	// effects must still be seen, or its startRun is never undone
+	for i := range 20 {
+		ctx, cancel := context.WithCancel(context.Background())
+		if err := sched.do(ctx, func(h *scheduleHeap) { cancel() }); err != nil {
+			t.Fatalf("do() #%d after accepting the command error = %v, want nil", i, err)
+		}
+	}
+
+	// The caller's ctx expires while the check runs
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	res, err := sched.RunNow(ctx, "web")
+	if err != nil || !res.Success {
+		t.Fatalf("RunNow() = %+v, %v, want a successful check", res, err)
+	}
+	if n := sched.inFlightCount(); n != 0 {
+		t.Fatalf("in flight after RunNow = %d, want 0", n)
+	}
+
+	if _, err := sched.RunNow(context.Background(), "web"); err != nil {
+		t.Errorf("RunNow() after an expired caller error = %v, want nil", err)
+	}
+}
+
+func TestScheduler_PauseAndResume(t *testing.T) {
+	cfg := &config.Config{Global: config.GlobalConfig{WorkerCount: 1, Jitter: "0s"}}
+	bundle := metrics.NewBundle()
+
+	sched, err := New(cfg, nil, bundle.Collector, nil)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	web := config.Service{ID: "web", Name: "Web", Type: "tcp", Interval: "1h", Tags: []string{"edge"}}
+	db := config.Service{ID: "db", Name: "DB", Type: "tcp", Interval: "1h"}
+
+	h := newScheduleHeap()
+	sched.rebuildHeap(h, []config.Service{web, db})
+
+	run := func(cmd func(ctx context.Context) error) error {
+		errCh := make(chan error, 1)
+		go func() { errCh <- cmd(context.Background()) }()
+		(<-sched.commandCh)(h)
+		return <-errCh
+	}
+	pause := func(id string) error {
+		return run(func(ctx context.Context) error { return sched.Pause(ctx, id) })
+	}
+
+	if err := pause("web"); err != nil {
+		t.Fatalf("Pause() error: %v", err)
+	}
+	if err := pause("missing"); !errors.Is(err, ErrUnknownService) {
+		t.Errorf("Pause(missing) error = %v, want ErrUnknownService", err)
+	}
+	if got := testutil.ToFloat64(bundle.Collector.ServicePaused.WithLabelValues("web", "Web", "tcp")); got != 1 {
+		t.Errorf("service_paused{web} = %v, want 1", got)
+	}
+
+	// A paused service due to run is skipped and rescheduled
+	dbItem := sched.items["db"]
+	dbItem.nextRun = time.Now().Add(time.Hour)
+	heap.Fix(h, dbItem.index)
+	webItem := sched.items["web"]
+	webItem.nextRun = time.Now().Add(-time.Second)
+	heap.Fix(h, webItem.index)
+	sched.processReadyItems(context.Background(), h)
+	select {
+	case j := <-sched.jobsCh:
+		t.Fatalf("service %q dispatched, want the paused service skipped", j.service.ID)
+	default:
+	}
+	if !webItem.missedRun || !webItem.nextRun.After(time.Now()) {
+		t.Errorf("paused item missedRun=%v nextRun=%v, want a missed run rescheduled ahead", webItem.missedRun, webItem.nextRun)
+	}
+	if got := testutil.ToFloat64(bundle.Collector.SkippedPaused.WithLabelValues("web", "Web", "tcp")); got != 1 {
+		t.Errorf("check_skipped_paused_total{web} = %v, want 1", got)
+	}
+
+	// Pausing its tag keeps it paused after resuming the service itself
+	if err := run(func(ctx context.Context) error { return sched.PauseTag(ctx, "edge") }); err != nil {
+		t.Fatalf("PauseTag() error: %v", err)
+	}
+	if err := run(func(ctx context.Context) error { return sched.Resume(ctx, "web") }); err != nil {
+		t.Fatalf("Resume() error: %v", err)
+	}
+	if !sched.paused(web) {
+		t.Error("service should stay paused by its tag")
+	}
+	if err := run(func(ctx context.Context) error { return sched.PauseTag(ctx, "nope") }); !errors.Is(err, ErrUnknownTag) {
+		t.Errorf("PauseTag(nope) error = %v, want ErrUnknownTag", err)
+	}
+
+	// Resuming the tag runs the service right away since it missed a run
+	if err := run(func(ctx context.Context) error { return sched.ResumeTag(ctx, "edge") }); err != nil {
+		t.Fatalf("ResumeTag() error: %v", err)
+	}
+	if sched.paused(web) {
+		t.Error("service still paused after resuming its tag")
+	}
+	if webItem.missedRun || time.Until(webItem.nextRun) > 0 {
+		t.Errorf("resumed item nextRun in %v, want now", time.Until(webItem.nextRun))
+	}
+	if got := testutil.ToFloat64(bundle.Collector.ServicePaused.WithLabelValues("web", "Web", "tcp")); got != 0 {
+		t.Errorf("service_paused{web} = %v after resume, want 0", got)
+	}
+}
+
+func TestScheduler_PauseForgetsRemovedServices(t *testing.T) {
+	cfg := &config.Config{Global: config.GlobalConfig{WorkerCount: 1, Jitter: "0s"}}
+	sched, err := New(cfg, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	svc := config.Service{ID: "web", Type: "tcp", Interval: "1h"}
+	h := newScheduleHeap()
+	sched.rebuildHeap(h, []config.Service{svc})
+	sched.pausedServices["web"] = struct{}{}
+
+	sched.rebuildHeap(h, nil)
+	sched.rebuildHeap(h, []config.Service{svc})
+	if sched.paused(svc) {
+		t.Error("re-added service should not inherit its old pause")
+	}
+}
+
+func TestScheduler_ControlTimesOutWithoutLoop(t *testing.T) {
+	cfg := &config.Config{Global: config.GlobalConfig{WorkerCount: 1, Jitter: "0s"}}
+	sched, err := New(cfg, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := sched.Pause(ctx, "web"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Pause() error = %v, want deadline exceeded", err)
+	}
+}
@@ -10,8 +10,26 @@ import (
 // scheduledItem represents a service scheduled for checking.
 type scheduledItem struct {
 	service config.Service
+	timing  nextRunCalculator
+	failure failurePolicy
 	nextRun time.Time
+	due     time.Time // Original run time while delayed by a limit
+	lastRun time.Time // Due time of the last dispatched run
 	index   int
+
+	missedRun bool // A run was skipped while paused
+
+	// Adaptive rescheduling state, see Scheduler.applyOutcome
+	failing    bool
+	downChecks int
+}
+
+// dueTime returns when the item was originally due to run.
+func (item *scheduledItem) dueTime() time.Time {
+	if !item.due.IsZero() {
+		return item.due
+	}
+	return item.nextRun
 }
 
 // scheduleHeap is a min-heap of scheduled items ordered by nextRun time.
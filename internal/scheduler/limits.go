@@ -0,0 +1,192 @@
+package scheduler
+
+import (
+	"math"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"uptiq/internal/config"
+)
+
+// limitRetryInterval is how long a job blocked by a concurrency limit waits
+// before the scheduler tries to dispatch it again.
+const limitRetryInterval = 100 * time.Millisecond
+
+// limitRule is a compiled config.Limit with its live usage.
+type limitRule struct {
+	name          string
+	hosts         []string
+	tags          []string
+	maxConcurrent int
+	rps           float64
+
+	inFlight int
+	tokens   float64
+	refilled time.Time
+}
+
+// limiter enforces concurrency and rate limits on check dispatch. Slots are
+// acquired by the scheduling loop and released by workers.
+type limiter struct {
+	mu     sync.Mutex
+	rules  []*limitRule
+	active map[*reservation]struct{} // Acquired and not yet released
+}
+
+// reservation is the slots held by one dispatched check.
+type reservation struct {
+	svc   config.Service
+	host  string
+	rules []*limitRule
+}
+
+func newLimiter(limits []config.Limit) *limiter {
+	l := &limiter{active: make(map[*reservation]struct{})}
+	l.update(limits)
+	return l
+}
+
+// update replaces the limits. Checks still in flight keep counting against
+// the new limits that match them, and release from those, so a reload never
+// lets more checks run than a limit allows.
+func (l *limiter) update(limits []config.Limit) {
+	var rules []*limitRule
+	for _, cfg := range limits {
+		rule := &limitRule{
+			name:          cfg.Name,
+			tags:          slices.Clone(cfg.Match.Tags),
+			maxConcurrent: cfg.MaxConcurrent,
+			rps:           cfg.RPS,
+			tokens:        rateBurst(cfg.RPS),
+		}
+		for _, host := range cfg.Match.Hosts {
+			rule.hosts = append(rule.hosts, strings.ToLower(strings.TrimSpace(host)))
+		}
+		rules = append(rules, rule)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.rules = rules
+	for res := range l.active {
+		res.rules = res.rules[:0]
+		for _, rule := range rules {
+			if rule.matches(res.svc, res.host) {
+				rule.inFlight++
+				res.rules = append(res.rules, rule)
+			}
+		}
+	}
+}
+
+// rateBurst allows up to one second's worth of checks to start at once.
+func rateBurst(rps float64) float64 {
+	return math.Max(1, rps)
+}
+
+// acquire reserves a slot in every limit matching svc. When a limit is
+// exhausted nothing is reserved; the blocking limit's name and the time to
+// wait before retrying are returned instead.
+func (l *limiter) acquire(svc config.Service, now time.Time) (release func(), blockedBy string, retry time.Duration) {
+	if l == nil {
+		return func() {}, "", 0
+	}
+
+	host := targetHost(svc)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var matched []*limitRule
+	for _, rule := range l.rules {
+		if !rule.matches(svc, host) {
+			continue
+		}
+		rule.refill(now)
+
+		if rule.maxConcurrent > 0 && rule.inFlight >= rule.maxConcurrent {
+			return nil, rule.name, limitRetryInterval
+		}
+		if rule.rps > 0 && rule.tokens < 1 {
+			wait := time.Duration((1 - rule.tokens) / rule.rps * float64(time.Second))
+			return nil, rule.name, wait
+		}
+		matched = append(matched, rule)
+	}
+
+	for _, rule := range matched {
+		rule.inFlight++
+		if rule.rps > 0 {
+			rule.tokens--
+		}
+	}
+	res := &reservation{svc: svc, host: host, rules: matched}
+	l.active[res] = struct{}{}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			for _, rule := range res.rules {
+				rule.inFlight--
+			}
+			delete(l.active, res)
+		})
+	}, "", 0
+}
+
+func (r *limitRule) matches(svc config.Service, host string) bool {
+	if host != "" && slices.Contains(r.hosts, host) {
+		return true
+	}
+	for _, tag := range r.tags {
+		if slices.Contains(svc.Tags, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *limitRule) refill(now time.Time) {
+	if r.rps <= 0 {
+		return
+	}
+	if !r.refilled.IsZero() {
+		elapsed := now.Sub(r.refilled).Seconds()
+		r.tokens = math.Min(rateBurst(r.rps), r.tokens+elapsed*r.rps)
+	}
+	r.refilled = now
+}
+
+// targetHost returns the lower-cased host a service's check connects to.
+func targetHost(svc config.Service) string {
+	switch {
+	case svc.IsHTTP():
+		u, err := url.Parse(svc.URL)
+		if err != nil {
+			return ""
+		}
+		return strings.ToLower(u.Hostname())
+	case svc.IsTCP(), svc.IsNTP():
+		return strings.ToLower(svc.Host)
+	case svc.IsDomain():
+		return strings.ToLower(svc.Domain)
+	default:
+		return ""
+	}
+}
+
+func limitsEqual(a, b []config.Limit) bool {
+	return slices.EqualFunc(a, b, func(x, y config.Limit) bool {
+		return x.Name == y.Name &&
+			slices.Equal(x.Match.Hosts, y.Match.Hosts) &&
+			slices.Equal(x.Match.Tags, y.Match.Tags) &&
+			x.MaxConcurrent == y.MaxConcurrent &&
+			x.RPS == y.RPS
+	})
+}
@@ -0,0 +1,178 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"uptiq/internal/config"
+)
+
+func TestLimiter_Concurrency(t *testing.T) {
+	l := newLimiter([]config.Limit{
+		{Name: "gateway", Match: config.LimitMatch{Hosts: []string{"API.example.com"}}, MaxConcurrent: 2},
+	})
+	svc := config.Service{ID: "a", Type: "http", URL: "https://api.example.com/health"}
+	now := time.Now()
+
+	r1, _, _ := l.acquire(svc, now)
+	r2, _, _ := l.acquire(svc, now)
+	if r1 == nil || r2 == nil {
+		t.Fatal("first two acquisitions should succeed")
+	}
+
+	r3, blockedBy, retry := l.acquire(svc, now)
+	if r3 != nil {
+		t.Fatal("third acquisition should be blocked")
+	}
+	if blockedBy != "gateway" || retry != limitRetryInterval {
+		t.Errorf("blocked by %q retry %v, want gateway/%v", blockedBy, retry, limitRetryInterval)
+	}
+
+	r1()
+	r1() // Releasing twice must not free a second slot
+	if r, _, _ := l.acquire(svc, now); r == nil {
+		t.Error("acquisition after release should succeed")
+	}
+	if r, _, _ := l.acquire(svc, now); r != nil {
+		t.Error("double release freed an extra slot")
+	}
+}
+
+func TestLimiter_UpdateKeepsInFlight(t *testing.T) {
+	svc := config.Service{ID: "a", Type: "http", URL: "https://api.example.com/health", Tags: []string{"gw"}}
+	now := time.Now()
+
+	l := newLimiter(nil)
+	r1, _, _ := l.acquire(svc, now)
+	if r1 == nil {
+		t.Fatal("acquisition without limits should succeed")
+	}
+
+	// Checks started before the reload count against the new limits
+	l.update([]config.Limit{
+		{Name: "gateway", Match: config.LimitMatch{Hosts: []string{"api.example.com"}}, MaxConcurrent: 2},
+		{Name: "gw-tag", Match: config.LimitMatch{Tags: []string{"gw"}}, MaxConcurrent: 3},
+	})
+	r2, _, _ := l.acquire(svc, now)
+	if r2 == nil {
+		t.Fatal("second acquisition should succeed")
+	}
+	if r, blockedBy, _ := l.acquire(svc, now); r != nil || blockedBy != "gateway" {
+		t.Fatalf("third acquisition blocked by %q, want gateway with one check from before the reload", blockedBy)
+	}
+
+	// A release after another reload frees the slot in the current limits
+	l.update([]config.Limit{
+		{Name: "gateway", Match: config.LimitMatch{Hosts: []string{"api.example.com"}}, MaxConcurrent: 2},
+	})
+	r1()
+	r3, _, _ := l.acquire(svc, now)
+	if r3 == nil {
+		t.Fatal("acquisition after release should succeed")
+	}
+	if r, _, _ := l.acquire(svc, now); r != nil {
+		t.Error("limit exceeded after releases across reloads")
+	}
+	r2()
+	r3()
+	if n := l.rules[0].inFlight; n != 0 {
+		t.Errorf("inFlight = %d after all releases, want 0", n)
+	}
+}
+
+func TestLimiter_Rate(t *testing.T) {
+	l := newLimiter([]config.Limit{
+		{Name: "slow", Match: config.LimitMatch{Tags: []string{"gw"}}, RPS: 2},
+	})
+	svc := config.Service{ID: "a", Type: "tcp", Host: "10.0.0.1", Tags: []string{"gw"}}
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	// Burst of one second's worth
+	for i := 0; i < 2; i++ {
+		if r, _, _ := l.acquire(svc, now); r == nil {
+			t.Fatalf("acquisition %d in burst should succeed", i+1)
+		}
+	}
+
+	r, blockedBy, retry := l.acquire(svc, now)
+	if r != nil || blockedBy != "slow" {
+		t.Fatalf("acquisition over rate should be blocked by slow, got %q", blockedBy)
+	}
+	if retry != 500*time.Millisecond {
+		t.Errorf("retry = %v, want 500ms", retry)
+	}
+
+	if r, _, _ := l.acquire(svc, now.Add(500*time.Millisecond)); r == nil {
+		t.Error("acquisition after refill should succeed")
+	}
+}
+
+func TestLimiter_AllOrNothing(t *testing.T) {
+	l := newLimiter([]config.Limit{
+		{Name: "host", Match: config.LimitMatch{Hosts: []string{"db.internal"}}, MaxConcurrent: 5},
+		{Name: "tag", Match: config.LimitMatch{Tags: []string{"db"}}, MaxConcurrent: 1},
+	})
+	now := time.Now()
+	first := config.Service{ID: "a", Type: "tcp", Host: "other.internal", Tags: []string{"db"}}
+	second := config.Service{ID: "b", Type: "tcp", Host: "db.internal", Tags: []string{"db"}}
+
+	if r, _, _ := l.acquire(first, now); r == nil {
+		t.Fatal("first acquisition should succeed")
+	}
+	if r, blockedBy, _ := l.acquire(second, now); r != nil || blockedBy != "tag" {
+		t.Fatalf("second acquisition should be blocked by tag, got %q", blockedBy)
+	}
+
+	// The blocked acquisition must not have taken a host slot
+	if inFlight := l.rules[0].inFlight; inFlight != 0 {
+		t.Errorf("host inFlight = %d, want 0", inFlight)
+	}
+}
+
+func TestLimiter_Unmatched(t *testing.T) {
+	l := newLimiter([]config.Limit{
+		{Name: "gateway", Match: config.LimitMatch{Hosts: []string{"api.example.com"}}, MaxConcurrent: 1},
+	})
+	svc := config.Service{ID: "a", Type: "http", URL: "https://www.example.com"}
+
+	for i := 0; i < 3; i++ {
+		if r, _, _ := l.acquire(svc, time.Now()); r == nil {
+			t.Fatal("unmatched service should never be limited")
+		}
+	}
+}
+
+func TestTargetHost(t *testing.T) {
+	tests := []struct {
+		svc  config.Service
+		want string
+	}{
+		{config.Service{Type: "http", URL: "https://API.example.com:8443/health"}, "api.example.com"},
+		{config.Service{Type: "tcp", Host: "DB.internal", Port: 5432}, "db.internal"},
+		{config.Service{Type: "ntp", Host: "pool.ntp.org"}, "pool.ntp.org"},
+		{config.Service{Type: "domain", Domain: "example.com"}, "example.com"},
+		{config.Service{Type: "http", URL: "://bad"}, ""},
+	}
+
+	for _, tc := range tests {
+		if got := targetHost(tc.svc); got != tc.want {
+			t.Errorf("targetHost(%+v) = %q, want %q", tc.svc, got, tc.want)
+		}
+	}
+}
+
+func TestLimitsEqual(t *testing.T) {
+	a := []config.Limit{{Name: "gw", Match: config.LimitMatch{Hosts: []string{"h"}}, MaxConcurrent: 2}}
+	b := []config.Limit{{Name: "gw", Match: config.LimitMatch{Hosts: []string{"h"}}, MaxConcurrent: 2}}
+	c := []config.Limit{{Name: "gw", Match: config.LimitMatch{Hosts: []string{"h"}}, MaxConcurrent: 3}}
+
+	if !limitsEqual(a, b) {
+		t.Error("identical limits should be equal")
+	}
+	if limitsEqual(a, c) {
+		t.Error("limits with different max_concurrent should differ")
+	}
+	if limitsEqual(a, nil) {
+		t.Error("limits and nil should differ")
+	}
+}
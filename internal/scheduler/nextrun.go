@@ -0,0 +1,125 @@
+package scheduler
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"time"
+
+	"uptiq/internal/config"
+	"uptiq/internal/cron"
+)
+
+// nextRunCalculator decides when a scheduled service runs.
+type nextRunCalculator interface {
+	// First returns the initial run time after a (re)schedule at now.
+	First(now time.Time) time.Time
+	// Next returns the run time following a run dispatched at now.
+	// The zero time means the service will never run again.
+	Next(now time.Time) time.Time
+}
+
+// intervalCalculator runs a service immediately and then every interval.
+type intervalCalculator struct {
+	interval time.Duration
+}
+
+func (c intervalCalculator) First(now time.Time) time.Time { return now }
+func (c intervalCalculator) Next(now time.Time) time.Time  { return now.Add(c.interval) }
+
+// phasedCalculator runs a service every interval at a fixed offset from the
+// Unix epoch, so run times are stable across restarts and reloads.
+type phasedCalculator struct {
+	interval time.Duration
+	offset   time.Duration
+}
+
+func (c phasedCalculator) First(now time.Time) time.Time {
+	return c.Next(now.Add(-time.Nanosecond))
+}
+
+// Next returns the first slot strictly after now.
+func (c phasedCalculator) Next(now time.Time) time.Time {
+	n := now.UnixNano()
+	slot := n - n%int64(c.interval) + int64(c.offset)
+	if slot <= n {
+		slot += int64(c.interval)
+	}
+	return time.Unix(0, slot)
+}
+
+// hashOffset derives a stable phase offset within interval from a service ID.
+func hashOffset(id string, interval time.Duration) time.Duration {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(id))
+	return time.Duration(h.Sum64() % uint64(interval))
+}
+
+// cronCalculator runs a service at each activation of a cron schedule.
+type cronCalculator struct {
+	schedule *cron.Schedule
+	location *time.Location
+}
+
+func (c cronCalculator) First(now time.Time) time.Time { return c.Next(now) }
+func (c cronCalculator) Next(now time.Time) time.Time {
+	return c.schedule.Next(now.In(c.location))
+}
+
+// newNextRunCalculator builds the calculator matching a service's timing config.
+// The spread mode only affects interval services; cron schedules are exact.
+func newNextRunCalculator(svc config.Service, spread config.SpreadMode) (nextRunCalculator, error) {
+	if !svc.IsScheduled() {
+		interval := parseIntervalOrDefault(svc.Interval)
+		if spread == config.SpreadHash {
+			return phasedCalculator{interval: interval, offset: hashOffset(svc.ID, interval)}, nil
+		}
+		return intervalCalculator{interval: interval}, nil
+	}
+
+	sched, err := cron.Parse(svc.Schedule)
+	if err != nil {
+		return nil, fmt.Errorf("parse schedule: %w", err)
+	}
+	loc, err := time.LoadLocation(svc.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("load timezone: %w", err)
+	}
+
+	return cronCalculator{schedule: sched, location: loc}, nil
+}
+
+// failurePolicy shortens the delay between checks while a service is failing.
+type failurePolicy struct {
+	interval    time.Duration // Zero disables adaptive rescheduling
+	backoff     float64
+	maxInterval time.Duration
+}
+
+func newFailurePolicy(svc config.Service) failurePolicy {
+	interval, err := time.ParseDuration(svc.FailureInterval)
+	if err != nil || interval <= 0 {
+		return failurePolicy{}
+	}
+
+	p := failurePolicy{interval: interval}
+	if max, err := time.ParseDuration(svc.MaxFailureInterval); err == nil && max > 0 && svc.FailureBackoff > 1 {
+		p.backoff = svc.FailureBackoff
+		p.maxInterval = max
+	}
+	return p
+}
+
+// delay returns the wait before the next check of a failing service.
+// downChecks counts failed checks since the outage was confirmed.
+func (p failurePolicy) delay(downChecks int) time.Duration {
+	if p.backoff <= 1 || downChecks == 0 {
+		return p.interval
+	}
+
+	d := float64(p.interval) * math.Pow(p.backoff, float64(downChecks))
+	if d >= float64(p.maxInterval) {
+		return p.maxInterval
+	}
+	return time.Duration(d)
+}
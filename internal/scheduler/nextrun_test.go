@@ -0,0 +1,211 @@
+package scheduler
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"uptiq/internal/config"
+)
+
+func TestNewNextRunCalculator_Interval(t *testing.T) {
+	calc, err := newNextRunCalculator(config.Service{ID: "svc", Interval: "45s"}, config.SpreadRandom)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	if got := calc.First(now); !got.Equal(now) {
+		t.Errorf("First = %v, want %v", got, now)
+	}
+	if got := calc.Next(now); !got.Equal(now.Add(45 * time.Second)) {
+		t.Errorf("Next = %v, want %v", got, now.Add(45*time.Second))
+	}
+}
+
+func TestNewNextRunCalculator_Schedule(t *testing.T) {
+	calc, err := newNextRunCalculator(config.Service{
+		ID:       "svc",
+		Schedule: "0 9 * * mon-fri",
+		Timezone: "America/New_York",
+	}, config.SpreadHash)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Saturday 2026-03-07; DST starts on Sunday, so Monday 09:00 EDT is 13:00 UTC
+	now := time.Date(2026, 3, 7, 15, 0, 0, 0, time.UTC)
+	want := time.Date(2026, 3, 9, 13, 0, 0, 0, time.UTC)
+
+	if got := calc.First(now); !got.Equal(want) {
+		t.Errorf("First = %v, want %v", got.UTC(), want)
+	}
+
+	next := calc.Next(want)
+	wantNext := time.Date(2026, 3, 10, 13, 0, 0, 0, time.UTC)
+	if !next.Equal(wantNext) {
+		t.Errorf("Next = %v, want %v", next.UTC(), wantNext)
+	}
+}
+
+func TestNewNextRunCalculator_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		svc  config.Service
+	}{
+		{"bad schedule", config.Service{Schedule: "not a cron"}},
+		{"bad timezone", config.Service{Schedule: "@daily", Timezone: "Nowhere/Special"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := newNextRunCalculator(tc.svc, config.SpreadRandom); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestFailurePolicy_Delay(t *testing.T) {
+	tests := []struct {
+		name       string
+		svc        config.Service
+		downChecks int
+		want       time.Duration
+	}{
+		{
+			name: "disabled",
+			svc:  config.Service{Interval: "5m"},
+			want: 0,
+		},
+		{
+			name:       "no backoff keeps failure interval",
+			svc:        config.Service{FailureInterval: "30s"},
+			downChecks: 4,
+			want:       30 * time.Second,
+		},
+		{
+			name: "before confirmation",
+			svc:  config.Service{FailureInterval: "30s", FailureBackoff: 2, MaxFailureInterval: "5m"},
+			want: 30 * time.Second,
+		},
+		{
+			name:       "backing off",
+			svc:        config.Service{FailureInterval: "30s", FailureBackoff: 2, MaxFailureInterval: "5m"},
+			downChecks: 2,
+			want:       2 * time.Minute,
+		},
+		{
+			name:       "capped",
+			svc:        config.Service{FailureInterval: "30s", FailureBackoff: 2, MaxFailureInterval: "5m"},
+			downChecks: 10,
+			want:       5 * time.Minute,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := newFailurePolicy(tc.svc).delay(tc.downChecks); got != tc.want {
+				t.Errorf("delay(%d) = %v, want %v", tc.downChecks, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestPhasedCalculator(t *testing.T) {
+	calc, err := newNextRunCalculator(config.Service{ID: "api-health", Interval: "30s"}, config.SpreadHash)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	phased, ok := calc.(phasedCalculator)
+	if !ok {
+		t.Fatalf("calculator = %T, want phasedCalculator", calc)
+	}
+	if phased.offset != hashOffset("api-health", 30*time.Second) {
+		t.Errorf("offset = %v, want the hash offset", phased.offset)
+	}
+
+	now := time.Date(2026, 3, 1, 12, 0, 7, 0, time.UTC)
+	first := calc.First(now)
+	if first.Before(now) || first.Sub(now) >= 30*time.Second {
+		t.Errorf("First = %v, want within one interval of %v", first, now)
+	}
+	if got := time.Duration(first.UnixNano() % int64(30*time.Second)); got != phased.offset {
+		t.Errorf("First phase = %v, want %v", got, phased.offset)
+	}
+
+	// Dispatching exactly on the slot or slightly late both land on the next slot
+	if got := calc.Next(first); !got.Equal(first.Add(30 * time.Second)) {
+		t.Errorf("Next(slot) = %v, want %v", got, first.Add(30*time.Second))
+	}
+	if got := calc.Next(first.Add(40 * time.Millisecond)); !got.Equal(first.Add(30 * time.Second)) {
+		t.Errorf("Next(late) = %v, want %v", got, first.Add(30*time.Second))
+	}
+
+	// The slot is stable across restarts
+	if again := calc.First(now.Add(-3 * time.Second)); !again.Equal(first) {
+		t.Errorf("First from an earlier start = %v, want %v", again, first)
+	}
+}
+
+func TestHashOffset(t *testing.T) {
+	interval := 30 * time.Second
+
+	if hashOffset("svc-a", interval) != hashOffset("svc-a", interval) {
+		t.Error("hashOffset is not deterministic")
+	}
+
+	seen := make(map[time.Duration]bool)
+	for _, id := range []string{"svc-a", "svc-b", "svc-c", "svc-d"} {
+		off := hashOffset(id, interval)
+		if off < 0 || off >= interval {
+			t.Errorf("hashOffset(%q) = %v, want within [0, %v)", id, off, interval)
+		}
+		seen[off] = true
+	}
+	if len(seen) < 2 {
+		t.Error("hashOffset gave every service the same offset")
+	}
+}
+
+func TestScheduleLoad(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	items := []*scheduledItem{
+		// Every 30s at :05 and :35
+		{timing: intervalCalculator{interval: 30 * time.Second}, nextRun: now.Add(5 * time.Second)},
+		// Every 10m at :20
+		{timing: intervalCalculator{interval: 10 * time.Minute}, nextRun: now.Add(20 * time.Second)},
+	}
+
+	load := scheduleLoad(items, now)
+
+	want := map[int]float64{5: 1, 35: 1, 20: 0.1}
+	for second, v := range load {
+		if v != want[second] {
+			t.Errorf("load[%d] = %v, want %v", second, v, want[second])
+		}
+	}
+}
+
+func TestScheduleLoad_HashSpreadIsEven(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	var items []*scheduledItem
+	for i := 0; i < 600; i++ {
+		calc, err := newNextRunCalculator(config.Service{ID: fmt.Sprintf("svc-%d", i), Interval: "30s"}, config.SpreadHash)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		items = append(items, &scheduledItem{timing: calc, nextRun: calc.First(now)})
+	}
+
+	load := scheduleLoad(items, now)
+
+	// 600 services every 30s is 1200 checks/min, 20 per second on average
+	for second, v := range load {
+		if v < 8 || v > 32 {
+			t.Errorf("load[%d] = %v, want close to 20", second, v)
+		}
+	}
+}
@@ -24,6 +24,7 @@ const (
 	defaultTimeout      = 5 * time.Second
 	defaultWaitInterval = 500 * time.Millisecond
 	minWorkerCount      = 1
+	cancelGrace         = time.Second // For cancelled checks to return at shutdown
 )
 
 // ResultHandler processes check results.
@@ -31,18 +32,119 @@ type ResultHandler interface {
 	HandleResult(svc config.Service, res checks.Result)
 }
 
+// OutageTracker is optionally implemented by a ResultHandler to report whether
+// a failing service's outage is confirmed (e.g. its failure threshold was
+// reached). Services with failure_backoff only back off once it is.
+type OutageTracker interface {
+	OutageConfirmed(serviceID string) bool
+}
+
+// job is a check handed to a worker. release frees the job's limit slots.
+type job struct {
+	service config.Service
+	due     time.Time // Planned run time, for the lag metric
+	release func()
+}
+
+// checkOutcome is the part of a check result the scheduling loop reacts to.
+type checkOutcome struct {
+	success   bool
+	confirmed bool
+	finished  time.Time
+}
+
+// Settings holds the global scheduler settings that can change at runtime.
+type Settings struct {
+	WorkerCount int
+	Jitter      time.Duration
+	Spread      config.SpreadMode
+	Limits      []config.Limit
+
+	// DrainTimeout bounds how long in-flight checks may run at shutdown.
+	// Checks still running are then cancelled and their results discarded.
+	DrainTimeout time.Duration
+}
+
+// SettingsFromConfig extracts scheduler settings from the global config.
+func SettingsFromConfig(cfg *config.Config) (Settings, error) {
+	jitter, err := time.ParseDuration(cfg.Global.Jitter)
+	if err != nil {
+		return Settings{}, fmt.Errorf("parse global.jitter: %w", err)
+	}
+
+	workerCount := cfg.Global.WorkerCount
+	if workerCount < minWorkerCount {
+		workerCount = minWorkerCount
+	}
+
+	spread := config.SpreadMode(cfg.Global.Spread)
+	if spread == "" {
+		spread = config.SpreadRandom
+	}
+
+	drain, err := parseShutdownTimeout(cfg.Global.Shutdown.DrainTimeout, config.DefaultDrainTimeout)
+	if err != nil {
+		return Settings{}, fmt.Errorf("parse global.shutdown.drain_timeout: %w", err)
+	}
+
+	return Settings{
+		WorkerCount:  workerCount,
+		Jitter:       jitter,
+		Spread:       spread,
+		Limits:       cfg.Limits,
+		DrainTimeout: drain,
+	}, nil
+}
+
+func parseShutdownTimeout(value, fallback string) (time.Duration, error) {
+	if value == "" {
+		value = fallback
+	}
+	return time.ParseDuration(value)
+}
+
 // Scheduler manages periodic health checks.
 type Scheduler struct {
 	log         *slog.Logger
 	workerCount int
 	jitter      time.Duration
+	spread      config.SpreadMode
+
+	drainTimeout time.Duration
 
 	checkers *checks.Factory
 	metrics  *metrics.Collector
 	handler  ResultHandler
 
-	jobsCh   chan config.Service
-	updateCh chan []config.Service
+	// jobsCh keeps its startup capacity when the pool is resized: workers
+	// and the shutdown drain read it concurrently, so it cannot be swapped.
+	// It only buffers checks that are already due; when it is full, dispatch
+	// waits for a free worker, which a larger buffer would only postpone.
+	jobsCh     chan job
+	updateCh   chan []config.Service
+	settingsCh chan Settings
+	commandCh  chan func(h *scheduleHeap) // Runs on the loop, see do
+
+	// Owned by the scheduling loop
+	workers []chan struct{} // Per-worker stop channels
+	items   map[string]*scheduledItem
+	limits  []config.Limit
+	limiter *limiter
+
+	// Paused services and tags; see Pause and PauseTag
+	pausedServices map[string]struct{}
+	pausedTags     map[string]struct{}
+
+	// Services with a check in flight; a service never runs concurrently
+	// with itself so its results reach the handler in order.
+	inFlightMu sync.Mutex
+	inFlight   map[string]struct{}
+
+	// Latest outcome per service, reported by workers. Coalesced so
+	// workers never block on the scheduling loop.
+	outcomeMu sync.Mutex
+	outcomes  map[string]checkOutcome
+	outcomeCh chan struct{}
 
 	wg sync.WaitGroup
 }
@@ -53,28 +155,58 @@ func New(cfg *config.Config, log *slog.Logger, m *metrics.Collector, handler Res
 		log = slog.Default()
 	}
 
-	jitter, err := time.ParseDuration(cfg.Global.Jitter)
+	settings, err := SettingsFromConfig(cfg)
 	if err != nil {
-		return nil, fmt.Errorf("parse global.jitter: %w", err)
-	}
-
-	workerCount := cfg.Global.WorkerCount
-	if workerCount < minWorkerCount {
-		workerCount = minWorkerCount
+		return nil, err
 	}
 
 	return &Scheduler{
-		log:         log,
-		workerCount: workerCount,
-		jitter:      jitter,
-		checkers:    checks.NewFactory(),
-		metrics:     m,
-		handler:     handler,
-		jobsCh:      make(chan config.Service, workerCount*4),
-		updateCh:    make(chan []config.Service, 1),
+		log:            log,
+		workerCount:    settings.WorkerCount,
+		jitter:         settings.Jitter,
+		spread:         settings.Spread,
+		drainTimeout:   settings.DrainTimeout,
+		checkers:       checks.NewFactory(),
+		metrics:        m,
+		handler:        handler,
+		jobsCh:         make(chan job, settings.WorkerCount*4),
+		updateCh:       make(chan []config.Service, 1),
+		settingsCh:     make(chan Settings, 1),
+		commandCh:      make(chan func(h *scheduleHeap)),
+		items:          make(map[string]*scheduledItem),
+		pausedServices: make(map[string]struct{}),
+		pausedTags:     make(map[string]struct{}),
+		limits:         settings.Limits,
+		limiter:        newLimiter(settings.Limits),
+		inFlight:       make(map[string]struct{}),
+		outcomes:       make(map[string]checkOutcome),
+		outcomeCh:      make(chan struct{}, 1),
 	}, nil
 }
 
+// UpdateSettings resizes the worker pool and changes the jitter at runtime.
+// Workers being removed finish their current check before exiting. The job
+// queue keeps its capacity (see Scheduler.jobsCh).
+func (s *Scheduler) UpdateSettings(settings Settings) {
+	if settings.WorkerCount < minWorkerCount {
+		settings.WorkerCount = minWorkerCount
+	}
+	if settings.Spread == "" {
+		settings.Spread = config.SpreadRandom
+	}
+
+	// Keep only the latest update (drop older pending updates)
+	select {
+	case s.settingsCh <- settings:
+	default:
+		select {
+		case <-s.settingsCh:
+		default:
+		}
+		s.settingsCh <- settings
+	}
+}
+
 // UpdateServices triggers a schedule rebuild with new services.
 func (s *Scheduler) UpdateServices(services []config.Service) {
 	// Keep only the latest update (drop older pending updates)
@@ -89,9 +221,14 @@ func (s *Scheduler) UpdateServices(services []config.Service) {
 	}
 }
 
-// Start begins the scheduling loop.
+// Start begins the scheduling loop. When ctx is done it stops dispatching
+// and drains the workers before returning.
 func (s *Scheduler) Start(ctx context.Context, services []config.Service) error {
-	s.startWorkers(ctx)
+	// Checks run under their own context so shutdown can let them finish
+	checkCtx, cancelChecks := context.WithCancel(context.Background())
+	defer cancelChecks()
+
+	s.startWorkers(checkCtx)
 
 	h := newScheduleHeap()
 	s.rebuildHeap(h, services)
@@ -106,13 +243,23 @@ func (s *Scheduler) Start(ctx context.Context, services []config.Service) error
 		select {
 		case <-ctx.Done():
 			s.log.Info("scheduler stopping", "reason", ctx.Err())
-			s.stopWorkers()
+			s.stopWorkers(cancelChecks)
 			return nil
 
 		case newServices := <-s.updateCh:
 			s.rebuildHeap(h, newServices)
 
+		case settings := <-s.settingsCh:
+			s.applySettings(checkCtx, h, settings)
+
+		case <-s.outcomeCh:
+			s.applyOutcomes(h)
+
+		case cmd := <-s.commandCh:
+			cmd(h)
+
 		case <-timer.C:
+			// Dispatch stops with ctx: a full queue must not hold up shutdown
 			s.processReadyItems(ctx, h)
 		}
 	}
@@ -132,7 +279,7 @@ func (s *Scheduler) nextWaitDuration(h *scheduleHeap) time.Duration {
 }
 
 func (s *Scheduler) processReadyItems(ctx context.Context, h *scheduleHeap) {
-	for {
+	for ctx.Err() == nil {
 		item := h.Peek()
 		if item == nil || item.nextRun.After(time.Now()) {
 			return
@@ -140,23 +287,126 @@ func (s *Scheduler) processReadyItems(ctx context.Context, h *scheduleHeap) {
 
 		item = heap.Pop(h).(*scheduledItem)
 
+		now := time.Now()
+		if s.paused(item.service) {
+			if s.metrics != nil {
+				s.metrics.ObserveSkippedPaused(item.service)
+			}
+			item.missedRun = true
+			s.reschedule(h, item, now)
+			continue
+		}
+		if !s.startRun(item.service.ID) {
+			s.skipOverlap(h, item, now)
+			continue
+		}
+
+		release, blockedBy, retry := s.limiter.acquire(item.service, now)
+		if release == nil {
+			s.finishRun(item.service.ID)
+			s.delay(h, item, now, blockedBy, retry)
+			continue
+		}
+
 		select {
 		case <-ctx.Done():
+			release()
+			s.finishRun(item.service.ID)
+			if s.metrics != nil {
+				s.metrics.SchedulerDropped.Inc()
+			}
 			return
-		case s.jobsCh <- item.service:
+		case s.jobsCh <- job{service: item.service, due: item.dueTime(), release: release}:
+		}
+
+		if s.metrics != nil {
+			s.metrics.SchedulerQueueDepth.Set(float64(len(s.jobsCh)))
 		}
+		item.lastRun = item.dueTime()
+		s.reschedule(h, item, time.Now())
+	}
+}
+
+// reschedule pushes an item back at its next regular run. The next run is
+// computed from the due time so limit delays don't shift the phase.
+func (s *Scheduler) reschedule(h *scheduleHeap, item *scheduledItem, now time.Time) {
+	next := item.timing.Next(item.dueTime())
+	if next.Before(now) {
+		next = item.timing.Next(now)
+	}
+	item.due = time.Time{}
+
+	if next.IsZero() {
+		s.log.Warn("schedule has no future runs; unscheduling service", "service_id", item.service.ID)
+		delete(s.items, item.service.ID)
+		return
+	}
+	item.nextRun = next.Add(s.randomJitter())
+	heap.Push(h, item)
+}
+
+// startRun marks a service as in flight. It returns false if a previous
+// run of the service has not finished yet.
+func (s *Scheduler) startRun(serviceID string) bool {
+	s.inFlightMu.Lock()
+	defer s.inFlightMu.Unlock()
+
+	if _, running := s.inFlight[serviceID]; running {
+		return false
+	}
+	s.inFlight[serviceID] = struct{}{}
+	return true
+}
+
+func (s *Scheduler) finishRun(serviceID string) {
+	s.inFlightMu.Lock()
+	defer s.inFlightMu.Unlock()
+
+	delete(s.inFlight, serviceID)
+}
+
+// skipOverlap drops a run whose previous run is still in flight and moves
+// the service on to its next regular run.
+func (s *Scheduler) skipOverlap(h *scheduleHeap, item *scheduledItem, now time.Time) {
+	if s.metrics != nil {
+		s.metrics.ObserveSkippedOverlap(item.service)
+	}
+	s.log.Warn("skipping check; previous run still in flight",
+		"service_id", item.service.ID,
+		"service_name", item.service.Name,
+		"timeout", item.service.Timeout,
+	)
+	s.reschedule(h, item, now)
+}
 
-		// Reschedule
-		interval := parseIntervalOrDefault(item.service.Interval)
-		item.nextRun = time.Now().Add(interval).Add(s.randomJitter())
-		heap.Push(h, item)
+// delay puts back an item blocked by a limit. The original due time is kept
+// so the lag metric covers the whole wait.
+func (s *Scheduler) delay(h *scheduleHeap, item *scheduledItem, now time.Time, blockedBy string, retry time.Duration) {
+	if item.due.IsZero() {
+		item.due = item.nextRun
 	}
+	item.nextRun = now.Add(max(retry, time.Millisecond))
+	heap.Push(h, item)
+
+	if s.metrics != nil {
+		s.metrics.SchedulerDelayed.WithLabelValues(blockedBy).Inc()
+	}
+	s.log.Debug("check delayed by limit",
+		"service_id", item.service.ID,
+		"limit", blockedBy,
+		"retry_in", retry,
+	)
 }
 
+// rebuildHeap replaces the schedule with services. Unchanged services keep
+// their next run and changed services continue from their last run, so a
+// reload doesn't re-run every check at once; only new services get a fresh
+// jittered start.
 func (s *Scheduler) rebuildHeap(h *scheduleHeap, services []config.Service) {
-	prev := s.collectServiceIDs(h)
+	prev := s.collectScheduledItems(h)
 
 	h.clear()
+	clear(s.items)
 
 	now := time.Now()
 	current := make(map[string]config.Service)
@@ -166,33 +416,238 @@ func (s *Scheduler) rebuildHeap(h *scheduleHeap, services []config.Service) {
 			continue
 		}
 
+		if old, ok := prev[svc.ID]; ok && servicesEqual(old.service, svc) {
+			current[svc.ID] = svc
+			s.push(h, &scheduledItem{
+				service:    svc,
+				timing:     old.timing,
+				failure:    old.failure,
+				nextRun:    old.nextRun,
+				lastRun:    old.lastRun,
+				failing:    old.failing,
+				downChecks: old.downChecks,
+				missedRun:  old.missedRun,
+			})
+			continue
+		}
+
+		timing, err := newNextRunCalculator(svc, s.spread)
+		if err != nil {
+			s.log.Warn("skipping service with invalid schedule",
+				"service_id", svc.ID,
+				"service_name", svc.Name,
+				"error", err.Error(),
+			)
+			continue
+		}
+
+		first := timing.First(now)
+		var lastRun time.Time
+		if old, ok := prev[svc.ID]; ok {
+			first = continueSchedule(old, timing, now)
+			lastRun = old.lastRun
+		}
+		if first.IsZero() {
+			s.log.Warn("skipping service whose schedule never runs", "service_id", svc.ID)
+			continue
+		}
+
 		current[svc.ID] = svc
-		first := now.Add(s.randomJitter())
-		heap.Push(h, &scheduledItem{service: svc, nextRun: first})
+		s.push(h, &scheduledItem{
+			service: svc,
+			timing:  timing,
+			failure: newFailurePolicy(svc),
+			nextRun: first.Add(s.randomJitter()),
+			lastRun: lastRun,
+		})
 
 		s.log.Info("scheduled service",
 			"service_id", svc.ID,
 			"service_name", svc.Name,
 			"type", svc.Type,
 			"interval", svc.Interval,
+			"schedule", svc.Schedule,
 			"timeout", svc.Timeout,
+			"first_run", first,
 		)
 	}
 
-	s.logScheduleChanges(prev, current)
+	// A removed service is not paused if it comes back later
+	for id := range s.pausedServices {
+		if _, ok := current[id]; !ok {
+			delete(s.pausedServices, id)
+		}
+	}
+	s.observePaused()
+
+	diff := diffSchedules(prev, current)
+	s.logScheduleChanges(diff)
+	if s.metrics != nil {
+		s.metrics.ObserveScheduleChanges(len(diff.added), len(diff.changed), len(diff.removed), len(current))
+	}
+	s.observeLoad(h, now)
+}
+
+// continueSchedule returns the next run of a changed service under its new
+// timing: the run following its last run, or, if it never ran, no later
+// than its pending first run. Like reschedule, a run that would already be
+// overdue moves to the next run after now rather than to now.
+func continueSchedule(old *scheduledItem, timing nextRunCalculator, now time.Time) time.Time {
+	if old.lastRun.IsZero() {
+		next := timing.Next(now)
+		if !old.nextRun.IsZero() && old.nextRun.Before(next) {
+			return old.nextRun
+		}
+		return next
+	}
+
+	next := timing.Next(old.lastRun)
+	if next.Before(now) {
+		next = timing.Next(now)
+	}
+	return next
 }
 
-func (s *Scheduler) collectServiceIDs(h *scheduleHeap) map[string]config.Service {
-	result := make(map[string]config.Service)
+// respread recomputes every interval service's timing after a spread change.
+func (s *Scheduler) respread(h *scheduleHeap) {
+	now := time.Now()
 	for _, item := range *h {
-		result[item.service.ID] = item.service
+		if item.service.IsScheduled() {
+			continue
+		}
+		timing, err := newNextRunCalculator(item.service, s.spread)
+		if err != nil {
+			continue
+		}
+		item.timing = timing
+		item.nextRun = timing.First(now).Add(s.randomJitter())
+	}
+	heap.Init(h)
+	s.observeLoad(h, now)
+}
+
+func (s *Scheduler) observeLoad(h *scheduleHeap, now time.Time) {
+	if s.metrics != nil {
+		s.metrics.SetScheduleLoad(scheduleLoad(*h, now))
+	}
+}
+
+// Load projection bounds.
+const (
+	loadWindow         = time.Hour
+	maxLoadRunsPerItem = 3600
+)
+
+// scheduleLoad projects the schedule over the next hour and returns the
+// average number of checks per minute starting in each second of the minute.
+// Jitter and failure rescheduling are not included.
+func scheduleLoad(items []*scheduledItem, now time.Time) [60]float64 {
+	var load [60]float64
+	end := now.Add(loadWindow)
+
+	for _, item := range items {
+		t := item.nextRun
+		for runs := 0; !t.IsZero() && t.Before(end) && runs < maxLoadRunsPerItem; runs++ {
+			load[t.Second()]++
+			t = item.timing.Next(t)
+		}
+	}
+
+	for i := range load {
+		load[i] /= loadWindow.Minutes()
+	}
+	return load
+}
+
+func (s *Scheduler) push(h *scheduleHeap, item *scheduledItem) {
+	s.items[item.service.ID] = item
+	heap.Push(h, item)
+}
+
+// reportOutcome hands a check outcome to the scheduling loop without blocking.
+func (s *Scheduler) reportOutcome(svc config.Service, res checks.Result) {
+	outcome := checkOutcome{success: res.Success, finished: time.Now()}
+	if tracker, ok := s.handler.(OutageTracker); ok && !res.Success {
+		outcome.confirmed = tracker.OutageConfirmed(svc.ID)
+	}
+
+	s.outcomeMu.Lock()
+	s.outcomes[svc.ID] = outcome
+	s.outcomeMu.Unlock()
+
+	select {
+	case s.outcomeCh <- struct{}{}:
+	default:
+	}
+}
+
+func (s *Scheduler) applyOutcomes(h *scheduleHeap) {
+	s.outcomeMu.Lock()
+	outcomes := s.outcomes
+	s.outcomes = make(map[string]checkOutcome)
+	s.outcomeMu.Unlock()
+
+	for id, outcome := range outcomes {
+		if item, ok := s.items[id]; ok {
+			s.applyOutcome(h, item, outcome)
+		}
+	}
+}
+
+// applyOutcome reschedules a failing service on its failure_interval until
+// its outage is confirmed, then backs off by failure_backoff or, without
+// one, leaves it on the regular schedule. The regular schedule resumes with
+// the first run dispatched after a success.
+func (s *Scheduler) applyOutcome(h *scheduleHeap, item *scheduledItem, outcome checkOutcome) {
+	if outcome.success {
+		if item.failing {
+			s.log.Debug("service recovered; resuming regular schedule", "service_id", item.service.ID)
+		}
+		item.failing = false
+		item.downChecks = 0
+		return
+	}
+
+	if item.failure.interval <= 0 {
+		return
+	}
+
+	item.failing = true
+	if outcome.confirmed && item.failure.backoff <= 1 {
+		// Checking faster only helps to confirm the outage
+		s.log.Debug("outage confirmed; resuming regular schedule", "service_id", item.service.ID)
+		return
+	}
+
+	delay := item.failure.delay(item.downChecks)
+	if outcome.confirmed {
+		item.downChecks++
+	}
+	item.nextRun = outcome.finished.Add(delay).Add(s.randomJitter())
+	if item.index >= 0 {
+		heap.Fix(h, item.index)
+	}
+
+	s.log.Debug("service failing; rescheduled on failure interval",
+		"service_id", item.service.ID,
+		"delay", delay,
+		"confirmed", outcome.confirmed,
+	)
+}
+
+func (s *Scheduler) collectScheduledItems(h *scheduleHeap) map[string]*scheduledItem {
+	result := make(map[string]*scheduledItem)
+	for _, item := range *h {
+		result[item.service.ID] = item
 	}
 	return result
 }
 
 func (s *Scheduler) isValidServiceType(svc config.Service) bool {
-	typ := strings.ToLower(strings.TrimSpace(svc.Type))
-	if typ != "http" && typ != "tcp" {
+	switch config.ServiceType(strings.ToLower(strings.TrimSpace(svc.Type))) {
+	case config.ServiceTypeHTTP, config.ServiceTypeTCP, config.ServiceTypeDomain, config.ServiceTypeNTP:
+		return true
+	default:
 		s.log.Warn("skipping unsupported service type",
 			"service_id", svc.ID,
 			"service_name", svc.Name,
@@ -200,66 +655,194 @@ func (s *Scheduler) isValidServiceType(svc config.Service) bool {
 		)
 		return false
 	}
-	return true
 }
 
-func (s *Scheduler) logScheduleChanges(prev, current map[string]config.Service) {
-	var added, removed, changed []string
+// scheduleDiff lists the service IDs affected by a schedule rebuild.
+type scheduleDiff struct {
+	added, changed, removed []string
+}
+
+func diffSchedules(prev map[string]*scheduledItem, current map[string]config.Service) scheduleDiff {
+	var diff scheduleDiff
 
 	for id, svc := range current {
 		if old, ok := prev[id]; !ok {
-			added = append(added, id)
-		} else if !servicesEqual(old, svc) {
-			changed = append(changed, id)
+			diff.added = append(diff.added, id)
+		} else if !servicesEqual(old.service, svc) {
+			diff.changed = append(diff.changed, id)
 		}
 	}
 
 	for id := range prev {
 		if _, ok := current[id]; !ok {
-			removed = append(removed, id)
+			diff.removed = append(diff.removed, id)
 		}
 	}
 
-	if len(added)+len(removed)+len(changed) > 0 {
+	slices.Sort(diff.added)
+	slices.Sort(diff.changed)
+	slices.Sort(diff.removed)
+	return diff
+}
+
+func (s *Scheduler) logScheduleChanges(diff scheduleDiff) {
+	if len(diff.added)+len(diff.removed)+len(diff.changed) > 0 {
 		s.log.Info("schedule reloaded",
-			"added", added,
-			"removed", removed,
-			"changed", changed,
+			"added", diff.added,
+			"removed", diff.removed,
+			"changed", diff.changed,
 		)
 	} else {
 		s.log.Info("schedule reloaded (no changes)")
 	}
 }
 
+func (s *Scheduler) applySettings(ctx context.Context, h *scheduleHeap, settings Settings) {
+	if settings.Jitter != s.jitter {
+		s.log.Info("scheduler jitter changed", "from", s.jitter, "to", settings.Jitter)
+		s.jitter = settings.Jitter
+	}
+
+	if settings.Spread != s.spread {
+		s.log.Info("scheduler spread changed", "from", s.spread, "to", settings.Spread)
+		s.spread = settings.Spread
+		s.respread(h)
+	}
+
+	s.drainTimeout = settings.DrainTimeout
+
+	if !limitsEqual(settings.Limits, s.limits) {
+		s.log.Info("scheduler limits changed", "limits", len(settings.Limits))
+		s.limits = settings.Limits
+		s.limiter.update(settings.Limits)
+	}
+
+	if settings.WorkerCount != s.workerCount {
+		s.log.Info("resizing worker pool", "from", s.workerCount, "to", settings.WorkerCount)
+		s.workerCount = settings.WorkerCount
+		s.startWorkers(ctx)
+	}
+}
+
+// startWorkers grows or shrinks the pool to workerCount.
 func (s *Scheduler) startWorkers(ctx context.Context) {
-	for i := 0; i < s.workerCount; i++ {
+	for len(s.workers) < s.workerCount {
+		stop := make(chan struct{})
+		s.workers = append(s.workers, stop)
 		s.wg.Add(1)
-		go s.worker(ctx, i+1)
+		go s.worker(ctx, len(s.workers), stop)
+	}
+
+	for len(s.workers) > s.workerCount {
+		last := len(s.workers) - 1
+		close(s.workers[last])
+		s.workers = s.workers[:last]
+	}
+
+	if s.metrics != nil {
+		s.metrics.SchedulerWorkers.Set(float64(len(s.workers)))
 	}
 }
 
-func (s *Scheduler) worker(ctx context.Context, id int) {
+func (s *Scheduler) worker(ctx context.Context, id int, stop <-chan struct{}) {
 	defer s.wg.Done()
 
 	for {
 		select {
-		case <-ctx.Done():
+		case <-stop:
 			return
-		case svc, ok := <-s.jobsCh:
+		case j, ok := <-s.jobsCh:
 			if !ok {
 				return
 			}
-			s.runCheck(ctx, id, svc)
+			s.runJob(ctx, id, j)
 		}
 	}
 }
 
-func (s *Scheduler) stopWorkers() {
+func (s *Scheduler) runJob(ctx context.Context, workerID int, j job) {
+	defer s.finishRun(j.service.ID)
+	defer j.release()
+
+	if s.metrics != nil {
+		s.metrics.SchedulerQueueDepth.Set(float64(len(s.jobsCh)))
+		s.metrics.SchedulerLagSeconds.Observe(time.Since(j.due).Seconds())
+		s.metrics.SchedulerWorkersBusy.Inc()
+		defer s.metrics.SchedulerWorkersBusy.Dec()
+	}
+
+	s.runCheck(ctx, workerID, j.service)
+}
+
+// stopWorkers discards queued jobs and waits for the workers to exit. Checks
+// in flight get drainTimeout to finish; those still running are then
+// cancelled via cancelChecks and get cancelGrace to return. Alerts already
+// queued are left to the alert engine's own shutdown.
+func (s *Scheduler) stopWorkers(cancelChecks context.CancelFunc) {
+	dropped := s.discardQueued()
 	close(s.jobsCh)
-	s.wg.Wait()
+	if s.metrics != nil {
+		s.metrics.SchedulerDropped.Add(float64(dropped))
+	}
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	s.log.Info("draining in-flight checks",
+		"in_flight", s.inFlightCount(),
+		"dropped", dropped,
+		"timeout", s.drainTimeout,
+	)
+	if waitDone(done, s.drainTimeout) {
+		return
+	}
+
+	s.log.Warn("drain timeout reached; cancelling in-flight checks", "in_flight", s.inFlightCount())
+	cancelChecks()
+	if waitDone(done, cancelGrace) {
+		return
+	}
+
+	s.log.Warn("abandoning in-flight checks", "in_flight", s.inFlightCount())
+}
+
+// discardQueued drops jobs dispatched but not yet picked up by a worker.
+func (s *Scheduler) discardQueued() int {
+	dropped := 0
+	for {
+		select {
+		case j := <-s.jobsCh:
+			j.release()
+			s.finishRun(j.service.ID)
+			dropped++
+		default:
+			return dropped
+		}
+	}
 }
 
-func (s *Scheduler) runCheck(parent context.Context, workerID int, svc config.Service) {
+func (s *Scheduler) inFlightCount() int {
+	s.inFlightMu.Lock()
+	defer s.inFlightMu.Unlock()
+	return len(s.inFlight)
+}
+
+func waitDone(done <-chan struct{}, timeout time.Duration) bool {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-done:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
+
+func (s *Scheduler) runCheck(parent context.Context, workerID int, svc config.Service) checks.Result {
 	timeout := parseTimeoutOrDefault(svc.Timeout)
 	ctx, cancel := context.WithTimeout(parent, timeout)
 	defer cancel()
@@ -267,14 +850,27 @@ func (s *Scheduler) runCheck(parent context.Context, workerID int, svc config.Se
 	start := time.Now()
 	res := s.checkers.Check(ctx, svc)
 
+	if parent.Err() != nil {
+		// Cancelled by shutdown; the result says nothing about the service
+		s.log.Warn("check abandoned at shutdown",
+			"worker", workerID,
+			"service_id", svc.ID,
+			"service_name", svc.Name,
+		)
+		return res
+	}
+
 	if s.metrics != nil {
 		s.metrics.Observe(svc, res)
+		s.metrics.ObserveTimeoutRatio(svc, time.Since(start), timeout)
 	}
 	if s.handler != nil {
 		s.handler.HandleResult(svc, res)
 	}
+	s.reportOutcome(svc, res)
 
 	s.logCheckResult(workerID, svc, res, start)
+	return res
 }
 
 func (s *Scheduler) logCheckResult(workerID int, svc config.Service, res checks.Result, start time.Time) {
@@ -290,7 +886,9 @@ func (s *Scheduler) logCheckResult(workerID int, svc config.Service, res checks.
 		"target", targetForService(svc),
 	}
 
-	if res.Success {
+	if res.Success && res.Warning != "" {
+		s.log.Warn("check completed with warning", append(fields, "warning", res.Warning)...)
+	} else if res.Success {
 		s.log.Info("check completed", fields...)
 	} else {
 		fields = append(fields,
@@ -301,8 +899,10 @@ func (s *Scheduler) logCheckResult(workerID int, svc config.Service, res checks.
 	}
 }
 
+// randomJitter returns a random delay up to the configured jitter. Hash
+// spreading already phases checks deterministically, so it adds none.
 func (s *Scheduler) randomJitter() time.Duration {
-	if s.jitter <= 0 {
+	if s.jitter <= 0 || s.spread == config.SpreadHash {
 		return 0
 	}
 	return time.Duration(rand.Int63n(int64(s.jitter)))
@@ -314,9 +914,12 @@ func targetForService(svc config.Service) string {
 	if svc.IsHTTP() {
 		return svc.URL
 	}
-	if svc.IsTCP() {
+	if svc.IsTCP() || svc.IsNTP() {
 		return net.JoinHostPort(svc.Host, fmt.Sprintf("%d", svc.Port))
 	}
+	if svc.IsDomain() {
+		return svc.Domain
+	}
 	return ""
 }
 
@@ -348,6 +951,8 @@ func servicesEqual(a, b config.Service) bool {
 	return a.ID == b.ID &&
 		a.Type == b.Type &&
 		a.Interval == b.Interval &&
+		a.Schedule == b.Schedule &&
+		a.Timezone == b.Timezone &&
 		a.Timeout == b.Timeout &&
 		a.URL == b.URL &&
 		a.Method == b.Method &&
@@ -355,7 +960,22 @@ func servicesEqual(a, b config.Service) bool {
 		slices.Equal(a.ExpectedStatus, b.ExpectedStatus) &&
 		a.Contains == b.Contains &&
 		a.Host == b.Host &&
-		a.Port == b.Port
+		a.Port == b.Port &&
+		a.MaxOffset == b.MaxOffset &&
+		a.Domain == b.Domain &&
+		a.Lookup == b.Lookup &&
+		a.LookupServer == b.LookupServer &&
+		expiryWindowsEqual(a, b) &&
+		a.FailureInterval == b.FailureInterval &&
+		a.FailureBackoff == b.FailureBackoff &&
+		a.MaxFailureInterval == b.MaxFailureInterval &&
+		slices.Equal(a.DependsOn, b.DependsOn)
+}
+
+func expiryWindowsEqual(a, b config.Service) bool {
+	aWarn, aFail := a.ExpiryWindows()
+	bWarn, bFail := b.ExpiryWindows()
+	return aWarn == bWarn && aFail == bFail
 }
 
 func mapsEqual(a, b map[string]string) bool {
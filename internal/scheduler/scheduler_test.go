@@ -2,15 +2,20 @@ package scheduler
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"net"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"slices"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
 	"uptiq/internal/checks"
 	"uptiq/internal/config"
 	"uptiq/internal/metrics"
@@ -154,6 +159,13 @@ func TestScheduler_ExecutesChecks(t *testing.T) {
 	if count < 1 {
 		t.Errorf("expected at least 1 request, got %d", count)
 	}
+
+	if got := testutil.ToFloat64(m.Collector.SchedulerWorkers); got != 2 {
+		t.Errorf("scheduler_workers = %v, want 2", got)
+	}
+	if got := testutil.ToFloat64(m.Collector.SchedulerWorkersBusy); got != 0 {
+		t.Errorf("scheduler_workers_busy = %v after shutdown, want 0", got)
+	}
 }
 
 func TestScheduler_UpdateServices(t *testing.T) {
@@ -519,3 +531,812 @@ func TestScheduler_ZeroJitter(t *testing.T) {
 		}
 	}
 }
+
+func TestScheduler_RebuildHeapPreservesPhase(t *testing.T) {
+	cfg := &config.Config{
+		Global: config.GlobalConfig{
+			WorkerCount: 1,
+			Jitter:      "0s",
+		},
+	}
+
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	bundle := metrics.NewBundle()
+
+	sched, err := New(cfg, log, bundle.Collector, nil)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	h := newScheduleHeap()
+	sched.rebuildHeap(h, []config.Service{
+		{ID: "keep", Type: "http", URL: "http://example.com", Interval: "1h", Timeout: "5s"},
+		{ID: "change", Type: "http", URL: "http://example.com", Interval: "1h", Timeout: "5s"},
+		{ID: "shorten", Type: "http", URL: "http://example.com", Interval: "1h", Timeout: "5s"},
+		{ID: "pending", Type: "http", URL: "http://example.com", Interval: "1h", Timeout: "5s"},
+		{ID: "remove", Type: "tcp", Host: "localhost", Port: 80, Interval: "1h", Timeout: "5s"},
+	})
+
+	// Pretend every service last ran 30 minutes ago and is due in 30 minutes,
+	// except one that has not run yet
+	phase := time.Now().Add(30 * time.Minute)
+	for _, item := range *h {
+		item.nextRun = phase
+		if item.service.ID != "pending" {
+			item.lastRun = phase.Add(-time.Hour)
+		}
+	}
+
+	before := time.Now()
+	sched.rebuildHeap(h, []config.Service{
+		{ID: "keep", Name: "Renamed", Type: "http", URL: "http://example.com", Interval: "1h", Timeout: "5s"},
+		{ID: "change", Type: "http", URL: "http://example.org", Interval: "1h", Timeout: "5s"},
+		{ID: "shorten", Type: "http", URL: "http://example.com", Interval: "10m", Timeout: "5s"},
+		{ID: "pending", Type: "http", URL: "http://example.com", Interval: "2h", Timeout: "5s"},
+		{ID: "add", Type: "tcp", Host: "localhost", Port: 443, Interval: "1h", Timeout: "5s"},
+	})
+
+	items := make(map[string]*scheduledItem)
+	for _, item := range *h {
+		items[item.service.ID] = item
+	}
+
+	if len(items) != 5 {
+		t.Fatalf("heap has %d items, want 5", len(items))
+	}
+	if _, ok := items["remove"]; ok {
+		t.Error("removed service is still scheduled")
+	}
+	if !items["keep"].nextRun.Equal(phase) {
+		t.Errorf("unchanged service nextRun = %v, want preserved %v", items["keep"].nextRun, phase)
+	}
+	if items["keep"].service.Name != "Renamed" {
+		t.Errorf("unchanged service should pick up the new config, got name %q", items["keep"].service.Name)
+	}
+	// Changed services continue from their last run instead of starting over
+	if !items["change"].nextRun.Equal(phase) {
+		t.Errorf("changed service nextRun = %v, want its last run + interval %v", items["change"].nextRun, phase)
+	}
+	if next := items["shorten"].nextRun; next.Before(before.Add(10*time.Minute)) || next.After(time.Now().Add(10*time.Minute)) {
+		t.Errorf("overdue changed service nextRun = %v, want one new interval from now", next)
+	}
+	if !items["pending"].nextRun.Equal(phase) {
+		t.Errorf("changed service that never ran nextRun = %v, want its pending run %v", items["pending"].nextRun, phase)
+	}
+	if items["add"].nextRun.Before(before) || items["add"].nextRun.After(time.Now()) {
+		t.Errorf("added service nextRun = %v, want a fresh start", items["add"].nextRun)
+	}
+
+	families, err := bundle.Registry.Gather()
+	if err != nil {
+		t.Fatalf("failed to gather metrics: %v", err)
+	}
+	changes := make(map[string]float64)
+	for _, f := range families {
+		if f.GetName() != "uptiq_schedule_changes_total" {
+			continue
+		}
+		for _, m := range f.GetMetric() {
+			changes[m.GetLabel()[0].GetValue()] = m.GetCounter().GetValue()
+		}
+	}
+	// First rebuild adds five services, the second adds one, changes three and removes one
+	want := map[string]float64{metrics.ChangeAdded: 6, metrics.ChangeChanged: 3, metrics.ChangeRemoved: 1}
+	for change, v := range want {
+		if changes[change] != v {
+			t.Errorf("schedule_changes_total{change=%q} = %v, want %v", change, changes[change], v)
+		}
+	}
+}
+
+func TestDiffSchedules(t *testing.T) {
+	svc := func(id, url string) config.Service {
+		return config.Service{ID: id, Type: "http", URL: url}
+	}
+	prev := map[string]*scheduledItem{
+		"a": {service: svc("a", "http://a")},
+		"b": {service: svc("b", "http://b")},
+		"c": {service: svc("c", "http://c")},
+	}
+	current := map[string]config.Service{
+		"a": svc("a", "http://a"),
+		"b": svc("b", "http://b2"),
+		"d": svc("d", "http://d"),
+	}
+
+	diff := diffSchedules(prev, current)
+
+	if !slices.Equal(diff.added, []string{"d"}) {
+		t.Errorf("added = %v, want [d]", diff.added)
+	}
+	if !slices.Equal(diff.changed, []string{"b"}) {
+		t.Errorf("changed = %v, want [b]", diff.changed)
+	}
+	if !slices.Equal(diff.removed, []string{"c"}) {
+		t.Errorf("removed = %v, want [c]", diff.removed)
+	}
+}
+
+func TestScheduler_ApplySettings(t *testing.T) {
+	cfg := &config.Config{
+		Global: config.GlobalConfig{
+			WorkerCount: 2,
+			Jitter:      "0s",
+		},
+	}
+
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	sched, err := New(cfg, log, nil, nil)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sched.startWorkers(ctx)
+	if len(sched.workers) != 2 {
+		t.Fatalf("workers = %d, want 2", len(sched.workers))
+	}
+
+	sched.applySettings(ctx, newScheduleHeap(), Settings{WorkerCount: 5, Jitter: 3 * time.Second, Spread: config.SpreadRandom})
+	if len(sched.workers) != 5 {
+		t.Errorf("workers after grow = %d, want 5", len(sched.workers))
+	}
+	if sched.jitter != 3*time.Second {
+		t.Errorf("jitter = %v, want 3s", sched.jitter)
+	}
+
+	sched.applySettings(ctx, newScheduleHeap(), Settings{WorkerCount: 1, Jitter: 3 * time.Second, Spread: config.SpreadRandom})
+	if len(sched.workers) != 1 {
+		t.Errorf("workers after shrink = %d, want 1", len(sched.workers))
+	}
+
+	done := make(chan struct{})
+	go func() {
+		sched.stopWorkers(cancel)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("workers did not stop")
+	}
+}
+
+func TestScheduler_UpdateSettingsKeepsLatest(t *testing.T) {
+	cfg := &config.Config{
+		Global: config.GlobalConfig{
+			WorkerCount: 2,
+			Jitter:      "0s",
+		},
+	}
+
+	sched, err := New(cfg, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	sched.UpdateSettings(Settings{WorkerCount: 4})
+	sched.UpdateSettings(Settings{WorkerCount: 0, Jitter: time.Second})
+
+	got := <-sched.settingsCh
+	if got.WorkerCount != minWorkerCount || got.Jitter != time.Second || got.Spread != config.SpreadRandom {
+		t.Errorf("pending settings = %+v, want workers=%d jitter=1s", got, minWorkerCount)
+	}
+}
+
+func TestSettingsFromConfig(t *testing.T) {
+	settings, err := SettingsFromConfig(&config.Config{Global: config.GlobalConfig{WorkerCount: 0, Jitter: "250ms"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if settings.WorkerCount != minWorkerCount {
+		t.Errorf("WorkerCount = %d, want %d", settings.WorkerCount, minWorkerCount)
+	}
+	if settings.Jitter != 250*time.Millisecond {
+		t.Errorf("Jitter = %v, want 250ms", settings.Jitter)
+	}
+	if settings.Spread != config.SpreadRandom {
+		t.Errorf("Spread = %q, want %q", settings.Spread, config.SpreadRandom)
+	}
+
+	if _, err := SettingsFromConfig(&config.Config{Global: config.GlobalConfig{Jitter: "soon"}}); err == nil {
+		t.Error("expected error for invalid jitter")
+	}
+}
+
+// confirmingHandler reports an outage as confirmed after threshold failures.
+type confirmingHandler struct {
+	threshold int
+	failures  map[string]int
+}
+
+func (h *confirmingHandler) HandleResult(svc config.Service, res checks.Result) {
+	if res.Success {
+		h.failures[svc.ID] = 0
+	} else {
+		h.failures[svc.ID]++
+	}
+}
+
+func (h *confirmingHandler) OutageConfirmed(serviceID string) bool {
+	return h.failures[serviceID] >= h.threshold
+}
+
+func TestScheduler_FailureIntervalRechecksFaster(t *testing.T) {
+	var requestCount int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requestCount, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{
+		Global: config.GlobalConfig{
+			WorkerCount: 1,
+			Jitter:      "0s",
+		},
+	}
+
+	services := []config.Service{
+		{
+			ID:              "failing",
+			Type:            "http",
+			URL:             server.URL,
+			ExpectedStatus:  []int{200},
+			Interval:        "1h",
+			FailureInterval: "20ms",
+			Timeout:         "1s",
+		},
+	}
+
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	sched, err := New(cfg, log, nil, nil)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+
+	if err := sched.Start(ctx, services); err != nil {
+		t.Errorf("Start() error: %v", err)
+	}
+
+	// Only the first run would happen within 1h without failure_interval
+	if count := atomic.LoadInt32(&requestCount); count < 3 {
+		t.Errorf("expected at least 3 requests while failing, got %d", count)
+	}
+}
+
+func TestScheduler_ApplyOutcome(t *testing.T) {
+	cfg := &config.Config{
+		Global: config.GlobalConfig{
+			WorkerCount: 1,
+			Jitter:      "0s",
+		},
+	}
+
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	handler := &confirmingHandler{threshold: 2, failures: make(map[string]int)}
+
+	sched, err := New(cfg, log, nil, handler)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	svc := config.Service{
+		ID:                 "svc",
+		Type:               "http",
+		URL:                "http://example.com",
+		Interval:           "10m",
+		FailureInterval:    "10s",
+		FailureBackoff:     3,
+		MaxFailureInterval: "2m",
+	}
+
+	h := newScheduleHeap()
+	sched.rebuildHeap(h, []config.Service{svc})
+	item := sched.items["svc"]
+	regular := time.Now().Add(10 * time.Minute)
+	item.nextRun = regular
+
+	// Delay before each run: two unconfirmed failures, then backoff once confirmed
+	wantDelays := []time.Duration{10 * time.Second, 10 * time.Second, 30 * time.Second, 90 * time.Second, 2 * time.Minute}
+	for i, want := range wantDelays {
+		handler.HandleResult(svc, checks.Result{Success: false})
+		sched.reportOutcome(svc, checks.Result{Success: false})
+		finished := sched.outcomes["svc"].finished
+		sched.applyOutcomes(h)
+
+		if got := item.nextRun.Sub(finished); got != want {
+			t.Errorf("failure %d: delay = %v, want %v", i+1, got, want)
+		}
+		if h.Peek() != item {
+			t.Errorf("failure %d: heap not reordered", i+1)
+		}
+	}
+
+	item.nextRun = regular
+	handler.HandleResult(svc, checks.Result{Success: true})
+	sched.reportOutcome(svc, checks.Result{Success: true})
+	sched.applyOutcomes(h)
+
+	if item.failing || item.downChecks != 0 {
+		t.Errorf("after recovery failing=%v downChecks=%d, want reset", item.failing, item.downChecks)
+	}
+	if !item.nextRun.Equal(regular) {
+		t.Errorf("success should keep the regular schedule, nextRun = %v", item.nextRun)
+	}
+}
+
+func TestScheduler_ApplyOutcomeWithoutBackoff(t *testing.T) {
+	cfg := &config.Config{
+		Global: config.GlobalConfig{
+			WorkerCount: 1,
+			Jitter:      "0s",
+		},
+	}
+
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	handler := &confirmingHandler{threshold: 2, failures: make(map[string]int)}
+
+	sched, err := New(cfg, log, nil, handler)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	svc := config.Service{
+		ID:              "svc",
+		Type:            "http",
+		URL:             "http://example.com",
+		Interval:        "10m",
+		FailureInterval: "10s",
+	}
+
+	h := newScheduleHeap()
+	sched.rebuildHeap(h, []config.Service{svc})
+	item := sched.items["svc"]
+	regular := time.Now().Add(10 * time.Minute)
+
+	// Fast until the threshold confirms the outage, then back on interval
+	wantFast := []bool{true, false, false}
+	for i, fast := range wantFast {
+		item.nextRun = regular
+		handler.HandleResult(svc, checks.Result{Success: false})
+		sched.reportOutcome(svc, checks.Result{Success: false})
+		finished := sched.outcomes["svc"].finished
+		sched.applyOutcomes(h)
+
+		if fast {
+			if got := item.nextRun.Sub(finished); got != 10*time.Second {
+				t.Errorf("failure %d: delay = %v, want 10s", i+1, got)
+			}
+		} else if !item.nextRun.Equal(regular) {
+			t.Errorf("failure %d: nextRun = %v, want the regular schedule", i+1, item.nextRun)
+		}
+	}
+}
+
+func TestScheduler_ApplySettingsRespreads(t *testing.T) {
+	cfg := &config.Config{
+		Global: config.GlobalConfig{
+			WorkerCount: 1,
+			Jitter:      "5s",
+		},
+	}
+
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	sched, err := New(cfg, log, nil, nil)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	h := newScheduleHeap()
+	sched.rebuildHeap(h, []config.Service{
+		{ID: "a", Type: "tcp", Host: "localhost", Port: 80, Interval: "30s"},
+		{ID: "b", Type: "tcp", Host: "localhost", Port: 81, Interval: "30s"},
+		{ID: "cron", Type: "tcp", Host: "localhost", Port: 82, Schedule: "@hourly"},
+	})
+	cronRun := sched.items["cron"].nextRun
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	defer sched.stopWorkers(cancel)
+
+	sched.applySettings(ctx, h, Settings{WorkerCount: 1, Jitter: 5 * time.Second, Spread: config.SpreadHash})
+
+	for _, id := range []string{"a", "b"} {
+		item := sched.items[id]
+		if _, ok := item.timing.(phasedCalculator); !ok {
+			t.Errorf("%s timing = %T, want phasedCalculator", id, item.timing)
+		}
+		phase := time.Duration(item.nextRun.UnixNano() % int64(30*time.Second))
+		if want := hashOffset(id, 30*time.Second); phase != want {
+			t.Errorf("%s phase = %v, want %v (no jitter in hash mode)", id, phase, want)
+		}
+	}
+	if !sched.items["cron"].nextRun.Equal(cronRun) {
+		t.Error("cron services should not be respread")
+	}
+	if h.Peek().nextRun.After(sched.items["a"].nextRun) || h.Peek().nextRun.After(sched.items["b"].nextRun) {
+		t.Error("heap not reordered after respread")
+	}
+}
+
+func TestScheduler_ProcessReadyItemsDelaysLimitedJobs(t *testing.T) {
+	cfg := &config.Config{
+		Global: config.GlobalConfig{
+			WorkerCount: 1,
+			Jitter:      "0s",
+		},
+		Limits: []config.Limit{
+			{Name: "gateway", Match: config.LimitMatch{Hosts: []string{"gw.example.com"}}, MaxConcurrent: 1},
+		},
+	}
+
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	bundle := metrics.NewBundle()
+
+	sched, err := New(cfg, log, bundle.Collector, nil)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	h := newScheduleHeap()
+	sched.rebuildHeap(h, []config.Service{
+		{ID: "a", Type: "http", URL: "https://gw.example.com/a", Interval: "1h"},
+		{ID: "b", Type: "http", URL: "https://gw.example.com/b", Interval: "1h"},
+	})
+
+	// No workers are running, so the first job keeps its slot
+	sched.processReadyItems(context.Background(), h)
+
+	var dispatched job
+	select {
+	case dispatched = <-sched.jobsCh:
+	default:
+		t.Fatal("expected one job to be dispatched")
+	}
+	select {
+	case extra := <-sched.jobsCh:
+		t.Fatalf("second job %q dispatched despite max_concurrent=1", extra.service.ID)
+	default:
+	}
+
+	blocked := sched.items["a"]
+	if dispatched.service.ID == "a" {
+		blocked = sched.items["b"]
+	}
+	if blocked.due.IsZero() {
+		t.Error("delayed item should remember its due time")
+	}
+	if wait := time.Until(blocked.nextRun); wait <= 0 || wait > limitRetryInterval {
+		t.Errorf("delayed item retries in %v, want within %v", wait, limitRetryInterval)
+	}
+
+	dispatched.release()
+	time.Sleep(limitRetryInterval)
+	sched.processReadyItems(context.Background(), h)
+
+	select {
+	case j := <-sched.jobsCh:
+		if j.service.ID != blocked.service.ID {
+			t.Errorf("dispatched %q, want %q", j.service.ID, blocked.service.ID)
+		}
+		if lag := time.Since(j.due); lag < limitRetryInterval {
+			t.Errorf("job due %v ago, want the original due time at least %v ago", lag, limitRetryInterval)
+		}
+	default:
+		t.Fatal("delayed job not dispatched after release")
+	}
+	if !blocked.due.IsZero() {
+		t.Error("due time should be cleared after dispatch")
+	}
+
+	families, err := bundle.Registry.Gather()
+	if err != nil {
+		t.Fatalf("failed to gather metrics: %v", err)
+	}
+	var delayed float64
+	for _, f := range families {
+		if f.GetName() == "uptiq_scheduler_delayed_total" {
+			delayed = f.GetMetric()[0].GetCounter().GetValue()
+		}
+	}
+	if delayed < 1 {
+		t.Errorf("scheduler_delayed_total = %v, want at least 1", delayed)
+	}
+}
+
+func TestScheduler_ProcessReadyItemsSkipsOverlap(t *testing.T) {
+	cfg := &config.Config{
+		Global: config.GlobalConfig{
+			WorkerCount: 1,
+			Jitter:      "0s",
+		},
+	}
+
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	bundle := metrics.NewBundle()
+
+	sched, err := New(cfg, log, bundle.Collector, nil)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	svc := config.Service{ID: "slow", Name: "Slow", Type: "http", URL: "http://example.com", Interval: "20ms"}
+	h := newScheduleHeap()
+	sched.rebuildHeap(h, []config.Service{svc})
+
+	sched.processReadyItems(context.Background(), h)
+	first := <-sched.jobsCh
+
+	// The first run is still in flight when the next one becomes due
+	time.Sleep(25 * time.Millisecond)
+	sched.processReadyItems(context.Background(), h)
+
+	select {
+	case j := <-sched.jobsCh:
+		t.Fatalf("overlapping run of %q dispatched", j.service.ID)
+	default:
+	}
+	if next := sched.items["slow"].nextRun; !next.After(time.Now()) {
+		t.Errorf("skipped item should move on to its next run, nextRun = %v", next)
+	}
+
+	families, err := bundle.Registry.Gather()
+	if err != nil {
+		t.Fatalf("failed to gather metrics: %v", err)
+	}
+	var skipped float64
+	for _, f := range families {
+		if f.GetName() == "uptiq_check_skipped_overlap_total" {
+			skipped = f.GetMetric()[0].GetCounter().GetValue()
+		}
+	}
+	if skipped != 1 {
+		t.Errorf("check_skipped_overlap_total = %v, want 1", skipped)
+	}
+
+	// Once the run finishes the service is dispatched again
+	first.release()
+	sched.finishRun(first.service.ID)
+	time.Sleep(25 * time.Millisecond)
+	sched.processReadyItems(context.Background(), h)
+
+	select {
+	case <-sched.jobsCh:
+	default:
+		t.Error("service not dispatched after previous run finished")
+	}
+}
+
+// concurrencyHandler records the highest number of concurrent results per service.
+type concurrencyHandler struct {
+	active  int32
+	maxSeen int32
+	count   int32
+}
+
+func (h *concurrencyHandler) HandleResult(svc config.Service, res checks.Result) {
+	n := atomic.AddInt32(&h.active, 1)
+	for {
+		seen := atomic.LoadInt32(&h.maxSeen)
+		if n <= seen || atomic.CompareAndSwapInt32(&h.maxSeen, seen, n) {
+			break
+		}
+	}
+	time.Sleep(30 * time.Millisecond)
+	atomic.AddInt32(&h.count, 1)
+	atomic.AddInt32(&h.active, -1)
+}
+
+func TestScheduler_NoConcurrentRunsOfSameService(t *testing.T) {
+	cfg := &config.Config{
+		Global: config.GlobalConfig{
+			WorkerCount: 4,
+			Jitter:      "0s",
+		},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	handler := &concurrencyHandler{}
+
+	sched, err := New(cfg, log, nil, handler)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+
+	services := []config.Service{
+		{ID: "fast", Type: "http", URL: server.URL, Interval: "5ms", Timeout: "1s"},
+	}
+	if err := sched.Start(ctx, services); err != nil {
+		t.Errorf("Start() error: %v", err)
+	}
+
+	if got := atomic.LoadInt32(&handler.maxSeen); got != 1 {
+		t.Errorf("max concurrent results for one service = %d, want 1", got)
+	}
+	if got := atomic.LoadInt32(&handler.count); got < 2 {
+		t.Errorf("expected several runs, got %d", got)
+	}
+}
+
+func TestScheduler_ShutdownDrainsInFlightChecks(t *testing.T) {
+	started := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		time.Sleep(200 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{
+		Global: config.GlobalConfig{
+			WorkerCount: 1,
+			Jitter:      "0s",
+			Shutdown:    config.ShutdownConfig{DrainTimeout: "5s", AlertTimeout: "1s"},
+		},
+	}
+
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	handler := &mockResultHandler{}
+	sched, err := New(cfg, log, nil, handler)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		_ = sched.Start(ctx, []config.Service{
+			{ID: "slow", Type: "http", URL: server.URL, Interval: "1h", Timeout: "5s"},
+		})
+		close(done)
+	}()
+
+	<-started
+	cancel()
+	<-done
+
+	if len(handler.results) != 1 {
+		t.Fatalf("results = %d, want the in-flight check to finish", len(handler.results))
+	}
+	if !handler.results[0].Success {
+		t.Errorf("drained check failed: %s", handler.results[0].Error)
+	}
+}
+
+func TestScheduler_ShutdownCancelsChecksAfterDrainTimeout(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	cfg := &config.Config{
+		Global: config.GlobalConfig{
+			WorkerCount: 1,
+			Jitter:      "0s",
+			Shutdown:    config.ShutdownConfig{DrainTimeout: "50ms", AlertTimeout: "1s"},
+		},
+	}
+
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	handler := &mockResultHandler{}
+	sched, err := New(cfg, log, nil, handler)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		_ = sched.Start(ctx, []config.Service{
+			{ID: "hung", Type: "http", URL: server.URL, Interval: "1h", Timeout: "10s"},
+		})
+		close(done)
+	}()
+
+	<-started
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("scheduler did not stop after the drain timeout")
+	}
+	if n := atomic.LoadInt32(&handler.count); n != 0 {
+		t.Errorf("handler got %d results, want the cancelled check discarded", n)
+	}
+}
+
+func TestScheduler_ShutdownWithFullQueue(t *testing.T) {
+	started := make(chan struct{})
+	var once sync.Once
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		once.Do(func() { close(started) })
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{
+		Global: config.GlobalConfig{
+			WorkerCount: 1,
+			Jitter:      "0s",
+			Shutdown:    config.ShutdownConfig{DrainTimeout: "50ms", AlertTimeout: "1s"},
+		},
+	}
+
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	bundle := metrics.NewBundle()
+	sched, err := New(cfg, log, bundle.Collector, &mockResultHandler{})
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	// One check hangs the only worker, so dispatch blocks on the full queue
+	var services []config.Service
+	for i := range cap(sched.jobsCh) + 3 {
+		services = append(services, config.Service{
+			ID: fmt.Sprintf("svc-%d", i), Type: "http", URL: server.URL, Interval: "1h", Timeout: "30s",
+		})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		_ = sched.Start(ctx, services)
+		close(done)
+	}()
+
+	<-started
+	deadline := time.Now().Add(2 * time.Second)
+	for len(sched.jobsCh) < cap(sched.jobsCh) {
+		if time.Now().After(deadline) {
+			t.Fatal("job queue never filled")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("scheduler did not stop while dispatch was blocked on a full queue")
+	}
+
+	// The queued jobs and the one being dispatched were dropped
+	if got := testutil.ToFloat64(bundle.Collector.SchedulerDropped); got != float64(cap(sched.jobsCh)+1) {
+		t.Errorf("scheduler_dropped_total = %v, want %d", got, cap(sched.jobsCh)+1)
+	}
+}
+
+func TestSettingsFromConfig_ShutdownDefaults(t *testing.T) {
+	settings, err := SettingsFromConfig(&config.Config{Global: config.GlobalConfig{Jitter: "0s"}})
+	if err != nil {
+		t.Fatalf("SettingsFromConfig() error: %v", err)
+	}
+	if settings.DrainTimeout != 30*time.Second {
+		t.Errorf("drain timeout = %v, want the 30s default", settings.DrainTimeout)
+	}
+}
@@ -0,0 +1,61 @@
+package scheduler
+
+import (
+	"context"
+	"slices"
+	"time"
+)
+
+// Entry describes a scheduled service, as listed by /debug/schedule.
+type Entry struct {
+	ServiceID   string     `json:"service_id"`
+	ServiceName string     `json:"service_name"`
+	Type        string     `json:"type"`
+	Interval    string     `json:"interval,omitempty"`
+	Schedule    string     `json:"schedule,omitempty"`
+	NextRun     time.Time  `json:"next_run"`
+	DelayedFrom *time.Time `json:"delayed_from,omitempty"` // Set while held back by a limit
+	Failing     bool       `json:"failing"`
+	Paused      bool       `json:"paused"`
+	InFlight    bool       `json:"in_flight"`
+}
+
+// Snapshot returns the current schedule ordered by next run time. It fails
+// if the scheduling loop does not answer before ctx is done.
+func (s *Scheduler) Snapshot(ctx context.Context) ([]Entry, error) {
+	var entries []Entry
+	err := s.do(ctx, func(h *scheduleHeap) {
+		entries = s.snapshot(h)
+	})
+	return entries, err
+}
+
+func (s *Scheduler) snapshot(h *scheduleHeap) []Entry {
+	s.inFlightMu.Lock()
+	defer s.inFlightMu.Unlock()
+
+	entries := make([]Entry, 0, h.Len())
+	for _, item := range *h {
+		e := Entry{
+			ServiceID:   item.service.ID,
+			ServiceName: item.service.Name,
+			Type:        item.service.Type,
+			Interval:    item.service.Interval,
+			Schedule:    item.service.Schedule,
+			NextRun:     item.nextRun,
+			Failing:     item.failing,
+			Paused:      s.paused(item.service),
+		}
+		if !item.due.IsZero() {
+			due := item.due
+			e.DelayedFrom = &due
+		}
+		_, e.InFlight = s.inFlight[item.service.ID]
+		entries = append(entries, e)
+	}
+
+	slices.SortFunc(entries, func(a, b Entry) int {
+		return a.NextRun.Compare(b.NextRun)
+	})
+	return entries
+}
@@ -0,0 +1,109 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"uptiq/internal/config"
+)
+
+func TestScheduler_Snapshot(t *testing.T) {
+	cfg := &config.Config{
+		Global: config.GlobalConfig{
+			WorkerCount: 1,
+			Jitter:      "0s",
+		},
+	}
+
+	sched, err := New(cfg, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	h := newScheduleHeap()
+	sched.rebuildHeap(h, []config.Service{
+		{ID: "later", Name: "Later", Type: "tcp", Interval: "1h"},
+		{ID: "sooner", Name: "Sooner", Type: "tcp", Interval: "1m"},
+		{ID: "cron", Name: "Cron", Type: "tcp", Schedule: "@yearly"},
+	})
+
+	now := time.Now()
+	sched.items["sooner"].nextRun = now.Add(time.Minute)
+	sched.items["later"].nextRun = now.Add(time.Hour)
+
+	delayed := sched.items["later"]
+	delayed.due = now.Add(time.Minute)
+	sched.items["sooner"].failing = true
+	sched.inFlight["sooner"] = struct{}{}
+
+	entries := sched.snapshot(h)
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, want 3", len(entries))
+	}
+
+	var ids []string
+	for _, e := range entries {
+		ids = append(ids, e.ServiceID)
+	}
+	if want := []string{"sooner", "later", "cron"}; !slices.Equal(ids, want) {
+		t.Errorf("order = %v, want %v", ids, want)
+	}
+
+	sooner := entries[0]
+	if !sooner.Failing || !sooner.InFlight {
+		t.Errorf("sooner failing=%v in_flight=%v, want both true", sooner.Failing, sooner.InFlight)
+	}
+	if sooner.Interval != "1m" || sooner.DelayedFrom != nil {
+		t.Errorf("sooner interval=%q delayed_from=%v, want 1m and nil", sooner.Interval, sooner.DelayedFrom)
+	}
+	if later := entries[1]; later.DelayedFrom == nil || !later.DelayedFrom.Equal(delayed.due) {
+		t.Errorf("later delayed_from = %v, want %v", later.DelayedFrom, delayed.due)
+	}
+	if cron := entries[2]; cron.Schedule != "@yearly" || cron.Interval != "" {
+		t.Errorf("cron schedule=%q interval=%q, want @yearly and empty", cron.Schedule, cron.Interval)
+	}
+}
+
+func TestScheduler_SnapshotFromLoop(t *testing.T) {
+	cfg := &config.Config{
+		Global: config.GlobalConfig{
+			WorkerCount: 1,
+			Jitter:      "0s",
+		},
+	}
+
+	sched, err := New(cfg, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- sched.Start(ctx, []config.Service{{ID: "svc", Type: "tcp", Interval: "1h"}})
+	}()
+
+	reqCtx, reqCancel := context.WithTimeout(ctx, time.Second)
+	defer reqCancel()
+	entries, err := sched.Snapshot(reqCtx)
+	if err != nil {
+		t.Fatalf("Snapshot() error: %v", err)
+	}
+	if len(entries) != 1 || entries[0].ServiceID != "svc" {
+		t.Errorf("entries = %+v, want one entry for svc", entries)
+	}
+
+	cancel()
+	<-done
+
+	stopped, stoppedCancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer stoppedCancel()
+	if _, err := sched.Snapshot(stopped); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Snapshot() on stopped scheduler error = %v, want deadline exceeded", err)
+	}
+}
@@ -0,0 +1,377 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"uptiq/internal/alerting"
+	"uptiq/internal/checks"
+	"uptiq/internal/cluster"
+	"uptiq/internal/ha"
+	"uptiq/internal/maintenance"
+	"uptiq/internal/probe"
+	"uptiq/internal/scheduler"
+)
+
+// API configuration constants.
+const (
+	maxRequestBody   = 64 * 1024       // 64 KiB
+	maxLeaseBody     = 8 << 20         // 8 MiB; a lease carries every service's alert state
+	schedulerTimeout = 5 * time.Second // Wait for the scheduling loop to respond
+)
+
+// DependencySource provides the service dependency graph.
+type DependencySource interface {
+	Dependencies() []alerting.DependencyNode
+}
+
+// MaintenanceSource lists, creates and cancels maintenance windows.
+type MaintenanceSource interface {
+	Windows() []maintenance.Status
+	Create(req maintenance.Request) (maintenance.Status, error)
+	Cancel(id string) error
+}
+
+// ScheduleSource lists the scheduler's upcoming runs.
+type ScheduleSource interface {
+	Snapshot(ctx context.Context) ([]scheduler.Entry, error)
+}
+
+// ServiceControl runs, pauses and resumes scheduled services.
+type ServiceControl interface {
+	ScheduleSource
+	PausedTags(ctx context.Context) ([]string, error)
+	RunNow(ctx context.Context, serviceID string) (checks.Result, error)
+	Pause(ctx context.Context, serviceID string) error
+	Resume(ctx context.Context, serviceID string) error
+	PauseTag(ctx context.Context, tag string) error
+	ResumeTag(ctx context.Context, tag string) error
+}
+
+// ProbeSink accepts check results reported by remote probes.
+type ProbeSink interface {
+	Report(r probe.Report) (probe.ReportResponse, error)
+}
+
+// ClusterMember accepts heartbeats from other cluster members.
+type ClusterMember interface {
+	Self() string
+	Heartbeat(peer string) error
+}
+
+// LeaseHolder accepts HA lease renewals from the peer instance.
+type LeaseHolder interface {
+	Renew(lease ha.Lease) (ha.LeaseReply, error)
+}
+
+// checkResponse is the JSON form of a checks.Result.
+type checkResponse struct {
+	ServiceID          string     `json:"service_id"`
+	Success            bool       `json:"success"`
+	StatusCode         int        `json:"status_code,omitempty"`
+	LatencyMS          int64      `json:"latency_ms"`
+	Error              string     `json:"error,omitempty"`
+	Warning            string     `json:"warning,omitempty"`
+	Expiry             *time.Time `json:"expiry,omitempty"`
+	ClockOffsetSeconds float64    `json:"clock_offset_seconds,omitempty"`
+	Stratum            int        `json:"stratum,omitempty"`
+}
+
+func newCheckResponse(serviceID string, res checks.Result) checkResponse {
+	resp := checkResponse{
+		ServiceID:          serviceID,
+		Success:            res.Success,
+		StatusCode:         res.StatusCode,
+		LatencyMS:          res.Latency.Milliseconds(),
+		Error:              res.Error,
+		Warning:            res.Warning,
+		ClockOffsetSeconds: res.ClockOffset.Seconds(),
+		Stratum:            res.Stratum,
+	}
+	if !res.Expiry.IsZero() {
+		resp.Expiry = &res.Expiry
+	}
+	return resp
+}
+
+// SetAPIToken sets the bearer token required by write endpoints.
+// An empty token disables them.
+func (s *Server) SetAPIToken(token string) {
+	s.apiToken.Store(token)
+}
+
+// HandleDependencies exposes the dependency graph at /api/dependencies.
+func (s *Server) HandleDependencies(src DependencySource) {
+	s.mux.HandleFunc("GET /api/dependencies", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]any{"services": src.Dependencies()})
+	})
+}
+
+// HandleMaintenance exposes maintenance windows at /api/maintenance.
+func (s *Server) HandleMaintenance(src MaintenanceSource) {
+	s.mux.HandleFunc("GET /api/maintenance", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]any{"windows": src.Windows()})
+	})
+
+	s.mux.Handle("POST /api/maintenance", s.requireToken(func(w http.ResponseWriter, r *http.Request) {
+		var req maintenance.Request
+		if err := decodeJSON(w, r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		status, err := src.Create(req)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		s.log.Info("maintenance window created", "id", status.ID, "name", status.Name, "end", status.End)
+		writeJSON(w, http.StatusCreated, status)
+	}))
+
+	s.mux.Handle("DELETE /api/maintenance/{id}", s.requireToken(func(w http.ResponseWriter, r *http.Request) {
+		id := r.PathValue("id")
+
+		switch err := src.Cancel(id); {
+		case errors.Is(err, maintenance.ErrNotFound):
+			writeError(w, http.StatusNotFound, err)
+		case errors.Is(err, maintenance.ErrReadOnly):
+			writeError(w, http.StatusConflict, err)
+		case err != nil:
+			writeError(w, http.StatusInternalServerError, err)
+		default:
+			s.log.Info("maintenance window cancelled", "id", id)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+}
+
+// SetProbeToken sets the bearer token probes must send with their reports.
+// An empty token disables the report endpoint.
+func (s *Server) SetProbeToken(token string) {
+	s.probeToken.Store(token)
+}
+
+// HandleProbeReports accepts probe results at probe.ReportPath.
+func (s *Server) HandleProbeReports(sink ProbeSink) {
+	s.mux.Handle("POST "+probe.ReportPath, s.requireProbeToken(func(w http.ResponseWriter, r *http.Request) {
+		// Unknown fields are ignored: during a rolling upgrade, newer
+		// probes report to a primary that does not know all their fields
+		var report probe.Report
+		dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBody))
+		if err := dec.Decode(&report); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		resp, err := sink.Report(report)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, resp)
+	}))
+}
+
+// SetClusterToken sets the bearer token cluster heartbeats must carry.
+// An empty token disables the heartbeat endpoint.
+func (s *Server) SetClusterToken(token string) {
+	s.clusterToken.Store(token)
+}
+
+// HandleClusterHeartbeat accepts heartbeats at cluster.HeartbeatPath and
+// answers with this member's name.
+func (s *Server) HandleClusterHeartbeat(member ClusterMember) {
+	s.mux.Handle("POST "+cluster.HeartbeatPath, s.requireClusterToken(func(w http.ResponseWriter, r *http.Request) {
+		var hb cluster.Heartbeat
+		if err := decodeJSON(w, r, &hb); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := member.Heartbeat(hb.Peer); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, cluster.Heartbeat{Peer: member.Self()})
+	}))
+}
+
+// SetHAToken sets the bearer token HA lease renewals must carry. An empty
+// token disables the lease endpoint.
+func (s *Server) SetHAToken(token string) {
+	s.haToken.Store(token)
+}
+
+// HandleHALease accepts lease renewals at ha.LeasePath.
+func (s *Server) HandleHALease(holder LeaseHolder) {
+	s.mux.Handle("POST "+ha.LeasePath, s.requireHAToken(func(w http.ResponseWriter, r *http.Request) {
+		var lease ha.Lease
+		if err := decodeJSONLimit(w, r, &lease, maxLeaseBody); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		reply, err := holder.Renew(lease)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, reply)
+	}))
+}
+
+// HandleSchedule exposes the scheduler's queue at /debug/schedule.
+func (s *Server) HandleSchedule(src ScheduleSource) {
+	s.mux.HandleFunc("GET /debug/schedule", func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), schedulerTimeout)
+		defer cancel()
+
+		entries, err := src.Snapshot(ctx)
+		if err != nil {
+			writeError(w, http.StatusServiceUnavailable, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"services": entries})
+	})
+}
+
+// HandleServices exposes runtime service controls under /api/services and
+// /api/tags: listing state, running a check now, and pausing or resuming
+// services and tags.
+func (s *Server) HandleServices(src ServiceControl) {
+	s.mux.HandleFunc("GET /api/services", func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), schedulerTimeout)
+		defer cancel()
+
+		entries, err := src.Snapshot(ctx)
+		if err != nil {
+			writeSchedulerError(w, err)
+			return
+		}
+		tags, err := src.PausedTags(ctx)
+		if err != nil {
+			writeSchedulerError(w, err)
+			return
+		}
+		if tags == nil {
+			tags = []string{}
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"services": entries, "paused_tags": tags})
+	})
+
+	s.mux.Handle("POST /api/services/{id}/run", s.requireToken(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), schedulerTimeout)
+		defer cancel()
+
+		id := r.PathValue("id")
+		res, err := src.RunNow(ctx, id)
+		if err != nil {
+			writeSchedulerError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, newCheckResponse(id, res))
+	}))
+
+	toggles := []struct {
+		pattern string
+		value   string
+		apply   func(ctx context.Context, v string) error
+	}{
+		{"POST /api/services/{id}/pause", "id", src.Pause},
+		{"POST /api/services/{id}/resume", "id", src.Resume},
+		{"POST /api/tags/{tag}/pause", "tag", src.PauseTag},
+		{"POST /api/tags/{tag}/resume", "tag", src.ResumeTag},
+	}
+	for _, t := range toggles {
+		s.mux.Handle(t.pattern, s.requireToken(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), schedulerTimeout)
+			defer cancel()
+
+			if err := t.apply(ctx, r.PathValue(t.value)); err != nil {
+				writeSchedulerError(w, err)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		}))
+	}
+}
+
+func writeSchedulerError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, scheduler.ErrUnknownService), errors.Is(err, scheduler.ErrUnknownTag):
+		writeError(w, http.StatusNotFound, err)
+	case errors.Is(err, scheduler.ErrCheckRunning):
+		writeError(w, http.StatusConflict, err)
+	default:
+		writeError(w, http.StatusServiceUnavailable, err)
+	}
+}
+
+// requireToken rejects requests without the configured API token.
+func (s *Server) requireToken(next http.HandlerFunc) http.Handler {
+	return requireBearer(&s.apiToken, "write API disabled (set global.api_token)", next)
+}
+
+// requireProbeToken rejects probe reports without the probing token.
+func (s *Server) requireProbeToken(next http.HandlerFunc) http.Handler {
+	return requireBearer(&s.probeToken, "probe reports disabled (set probing.token)", next)
+}
+
+// requireClusterToken rejects heartbeats without the cluster token.
+func (s *Server) requireClusterToken(next http.HandlerFunc) http.Handler {
+	return requireBearer(&s.clusterToken, "cluster heartbeats disabled (set cluster.token)", next)
+}
+
+// requireHAToken rejects lease renewals without the HA token.
+func (s *Server) requireHAToken(next http.HandlerFunc) http.Handler {
+	return requireBearer(&s.haToken, "ha lease disabled (set ha.token)", next)
+}
+
+// requireBearer rejects requests without the bearer token held in stored.
+// An empty token disables the endpoint.
+func requireBearer(stored *atomic.Value, disabled string, next http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, _ := stored.Load().(string)
+		if token == "" {
+			writeError(w, http.StatusForbidden, errors.New(disabled))
+			return
+		}
+
+		got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(w, http.StatusUnauthorized, errors.New("invalid or missing bearer token"))
+			return
+		}
+
+		next(w, r)
+	})
+}
+
+func decodeJSON(w http.ResponseWriter, r *http.Request, v any) error {
+	return decodeJSONLimit(w, r, v, maxRequestBody)
+}
+
+func decodeJSONLimit(w http.ResponseWriter, r *http.Request, v any, limit int64) error {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, limit))
+	dec.DisallowUnknownFields()
+	return dec.Decode(v)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
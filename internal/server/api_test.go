@@ -0,0 +1,526 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"uptiq/internal/alerting"
+	"uptiq/internal/checks"
+	"uptiq/internal/cluster"
+	"uptiq/internal/ha"
+	"uptiq/internal/maintenance"
+	"uptiq/internal/probe"
+	"uptiq/internal/scheduler"
+)
+
+type stubDependencies []alerting.DependencyNode
+
+func (s stubDependencies) Dependencies() []alerting.DependencyNode { return s }
+
+func TestServer_HandleDependencies(t *testing.T) {
+	srv := New("127.0.0.1:0", nil, nil)
+	srv.HandleDependencies(stubDependencies{
+		{ServiceID: "lb", State: alerting.StateDown, DependsOn: []string{}, Dependents: []string{"web"}},
+		{ServiceID: "web", State: alerting.StateUnreachable, DependsOn: []string{"lb"}, Dependents: []string{}},
+	})
+
+	rec := httptest.NewRecorder()
+	srv.httpServer.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/dependencies", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", ct)
+	}
+
+	var body struct {
+		Services []alerting.DependencyNode `json:"services"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if len(body.Services) != 2 {
+		t.Fatalf("len(services) = %d, want 2", len(body.Services))
+	}
+	if body.Services[1].State != alerting.StateUnreachable {
+		t.Errorf("web state = %q, want %q", body.Services[1].State, alerting.StateUnreachable)
+	}
+}
+
+func TestServer_HandleDependencies_MethodNotAllowed(t *testing.T) {
+	srv := New("127.0.0.1:0", nil, nil)
+	srv.HandleDependencies(stubDependencies{})
+
+	rec := httptest.NewRecorder()
+	srv.httpServer.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/dependencies", nil))
+
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("status = %d, want 405", rec.Code)
+	}
+}
+
+type stubMaintenance struct {
+	windows  []maintenance.Status
+	created  []maintenance.Request
+	cancelFn func(id string) error
+}
+
+func (s *stubMaintenance) Windows() []maintenance.Status { return s.windows }
+
+func (s *stubMaintenance) Create(req maintenance.Request) (maintenance.Status, error) {
+	if req.Name == "" {
+		return maintenance.Status{}, errors.New("name is required")
+	}
+	s.created = append(s.created, req)
+	return maintenance.Status{ID: "api-1", Name: req.Name, Active: true}, nil
+}
+
+func (s *stubMaintenance) Cancel(id string) error { return s.cancelFn(id) }
+
+func serve(srv *Server, req *http.Request) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	srv.httpServer.Handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestServer_HandleMaintenance_List(t *testing.T) {
+	srv := New("127.0.0.1:0", nil, nil)
+	srv.HandleMaintenance(&stubMaintenance{windows: []maintenance.Status{{ID: "config-0", Name: "nightly"}}})
+
+	rec := serve(srv, httptest.NewRequest(http.MethodGet, "/api/maintenance", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), `"id":"config-0"`) {
+		t.Errorf("body = %s, want config-0 window", rec.Body.String())
+	}
+}
+
+func TestServer_HandleMaintenance_CreateRequiresToken(t *testing.T) {
+	body := `{"name":"deploy","tags":["web"],"duration":"15m"}`
+
+	tests := []struct {
+		name       string
+		token      string
+		authHeader string
+		wantStatus int
+	}{
+		{name: "api disabled", token: "", authHeader: "Bearer secret", wantStatus: http.StatusForbidden},
+		{name: "missing header", token: "secret", authHeader: "", wantStatus: http.StatusUnauthorized},
+		{name: "wrong token", token: "secret", authHeader: "Bearer nope", wantStatus: http.StatusUnauthorized},
+		{name: "valid token", token: "secret", authHeader: "Bearer secret", wantStatus: http.StatusCreated},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			src := &stubMaintenance{}
+			srv := New("127.0.0.1:0", nil, nil)
+			srv.SetAPIToken(tc.token)
+			srv.HandleMaintenance(src)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/maintenance", strings.NewReader(body))
+			if tc.authHeader != "" {
+				req.Header.Set("Authorization", tc.authHeader)
+			}
+			rec := serve(srv, req)
+
+			if rec.Code != tc.wantStatus {
+				t.Errorf("status = %d, want %d (body: %s)", rec.Code, tc.wantStatus, rec.Body.String())
+			}
+			if tc.wantStatus == http.StatusCreated && len(src.created) != 1 {
+				t.Errorf("created = %d windows, want 1", len(src.created))
+			}
+		})
+	}
+}
+
+func TestServer_HandleMaintenance_CreateBadRequest(t *testing.T) {
+	srv := New("127.0.0.1:0", nil, nil)
+	srv.SetAPIToken("secret")
+	srv.HandleMaintenance(&stubMaintenance{})
+
+	for _, body := range []string{`not json`, `{"name":""}`, `{"name":"x","unknown":1}`} {
+		req := httptest.NewRequest(http.MethodPost, "/api/maintenance", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer secret")
+		rec := serve(srv, req)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("body %q: status = %d, want 400", body, rec.Code)
+		}
+	}
+}
+
+func TestServer_HandleMaintenance_Cancel(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{name: "cancelled", err: nil, wantStatus: http.StatusNoContent},
+		{name: "not found", err: maintenance.ErrNotFound, wantStatus: http.StatusNotFound},
+		{name: "config window", err: maintenance.ErrReadOnly, wantStatus: http.StatusConflict},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var gotID string
+			srv := New("127.0.0.1:0", nil, nil)
+			srv.SetAPIToken("secret")
+			srv.HandleMaintenance(&stubMaintenance{cancelFn: func(id string) error {
+				gotID = id
+				return tc.err
+			}})
+
+			req := httptest.NewRequest(http.MethodDelete, "/api/maintenance/api-7", nil)
+			req.Header.Set("Authorization", "Bearer secret")
+			rec := serve(srv, req)
+
+			if rec.Code != tc.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tc.wantStatus)
+			}
+			if gotID != "api-7" {
+				t.Errorf("cancelled id = %q, want api-7", gotID)
+			}
+		})
+	}
+}
+
+type stubSchedule struct {
+	entries []scheduler.Entry
+	err     error
+}
+
+func (s stubSchedule) Snapshot(ctx context.Context) ([]scheduler.Entry, error) {
+	return s.entries, s.err
+}
+
+func TestServer_HandleSchedule(t *testing.T) {
+	next := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	srv := New("127.0.0.1:0", nil, nil)
+	srv.HandleSchedule(stubSchedule{entries: []scheduler.Entry{
+		{ServiceID: "web", Type: "http", Interval: "30s", NextRun: next, InFlight: true},
+	}})
+
+	rec := serve(srv, httptest.NewRequest(http.MethodGet, "/debug/schedule", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+
+	var body struct {
+		Services []scheduler.Entry `json:"services"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if len(body.Services) != 1 {
+		t.Fatalf("len(services) = %d, want 1", len(body.Services))
+	}
+	if got := body.Services[0]; got.ServiceID != "web" || !got.NextRun.Equal(next) || !got.InFlight {
+		t.Errorf("entry = %+v, want web at %v in flight", got, next)
+	}
+}
+
+func TestServer_HandleSchedule_Unavailable(t *testing.T) {
+	srv := New("127.0.0.1:0", nil, nil)
+	srv.HandleSchedule(stubSchedule{err: context.DeadlineExceeded})
+
+	rec := serve(srv, httptest.NewRequest(http.MethodGet, "/debug/schedule", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want 503", rec.Code)
+	}
+}
+
+type stubControl struct {
+	stubSchedule
+	pausedTags []string
+	calls      []string
+	err        error
+}
+
+func (s *stubControl) PausedTags(ctx context.Context) ([]string, error) { return s.pausedTags, nil }
+
+func (s *stubControl) RunNow(ctx context.Context, serviceID string) (checks.Result, error) {
+	s.calls = append(s.calls, "run "+serviceID)
+	return checks.Result{Success: true, StatusCode: 200, Latency: 42 * time.Millisecond}, s.err
+}
+
+func (s *stubControl) Pause(ctx context.Context, serviceID string) error {
+	s.calls = append(s.calls, "pause "+serviceID)
+	return s.err
+}
+
+func (s *stubControl) Resume(ctx context.Context, serviceID string) error {
+	s.calls = append(s.calls, "resume "+serviceID)
+	return s.err
+}
+
+func (s *stubControl) PauseTag(ctx context.Context, tag string) error {
+	s.calls = append(s.calls, "pause tag "+tag)
+	return s.err
+}
+
+func (s *stubControl) ResumeTag(ctx context.Context, tag string) error {
+	s.calls = append(s.calls, "resume tag "+tag)
+	return s.err
+}
+
+func TestServer_HandleServices_List(t *testing.T) {
+	srv := New("127.0.0.1:0", nil, nil)
+	srv.HandleServices(&stubControl{
+		stubSchedule: stubSchedule{entries: []scheduler.Entry{{ServiceID: "web", Paused: true}}},
+		pausedTags:   []string{"edge"},
+	})
+
+	rec := serve(srv, httptest.NewRequest(http.MethodGet, "/api/services", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+
+	var body struct {
+		Services   []scheduler.Entry `json:"services"`
+		PausedTags []string          `json:"paused_tags"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if len(body.Services) != 1 || !body.Services[0].Paused {
+		t.Errorf("services = %+v, want paused web", body.Services)
+	}
+	if len(body.PausedTags) != 1 || body.PausedTags[0] != "edge" {
+		t.Errorf("paused_tags = %v, want [edge]", body.PausedTags)
+	}
+}
+
+func TestServer_HandleServices_RunNow(t *testing.T) {
+	ctl := &stubControl{}
+	srv := New("127.0.0.1:0", nil, nil)
+	srv.SetAPIToken("secret")
+	srv.HandleServices(ctl)
+
+	rec := serve(srv, httptest.NewRequest(http.MethodPost, "/api/services/web/run", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("status without token = %d, want 401", rec.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/services/web/run", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	rec = serve(srv, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+
+	var body map[string]any
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if body["service_id"] != "web" || body["success"] != true || body["latency_ms"] != float64(42) {
+		t.Errorf("body = %v, want web success with latency_ms 42", body)
+	}
+	if len(ctl.calls) != 1 || ctl.calls[0] != "run web" {
+		t.Errorf("calls = %v, want [run web]", ctl.calls)
+	}
+}
+
+func TestServer_HandleServices_PauseResume(t *testing.T) {
+	tests := []struct {
+		path       string
+		err        error
+		wantStatus int
+		wantCall   string
+	}{
+		{path: "/api/services/web/pause", wantStatus: http.StatusNoContent, wantCall: "pause web"},
+		{path: "/api/services/web/resume", wantStatus: http.StatusNoContent, wantCall: "resume web"},
+		{path: "/api/tags/edge/pause", wantStatus: http.StatusNoContent, wantCall: "pause tag edge"},
+		{path: "/api/tags/edge/resume", wantStatus: http.StatusNoContent, wantCall: "resume tag edge"},
+		{path: "/api/services/nope/pause", err: scheduler.ErrUnknownService, wantStatus: http.StatusNotFound, wantCall: "pause nope"},
+		{path: "/api/tags/nope/pause", err: scheduler.ErrUnknownTag, wantStatus: http.StatusNotFound, wantCall: "pause tag nope"},
+		{path: "/api/services/web/run", err: scheduler.ErrCheckRunning, wantStatus: http.StatusConflict, wantCall: "run web"},
+		{path: "/api/services/web/pause", err: context.DeadlineExceeded, wantStatus: http.StatusServiceUnavailable, wantCall: "pause web"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.path, func(t *testing.T) {
+			ctl := &stubControl{err: tc.err}
+			srv := New("127.0.0.1:0", nil, nil)
+			srv.SetAPIToken("secret")
+			srv.HandleServices(ctl)
+
+			req := httptest.NewRequest(http.MethodPost, tc.path, nil)
+			req.Header.Set("Authorization", "Bearer secret")
+			rec := serve(srv, req)
+
+			if rec.Code != tc.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tc.wantStatus)
+			}
+			if len(ctl.calls) != 1 || ctl.calls[0] != tc.wantCall {
+				t.Errorf("calls = %v, want [%s]", ctl.calls, tc.wantCall)
+			}
+		})
+	}
+}
+
+type stubProbeSink struct {
+	reports []probe.Report
+}
+
+func (s *stubProbeSink) Report(r probe.Report) (probe.ReportResponse, error) {
+	if r.Location == "" {
+		return probe.ReportResponse{}, probe.ErrNoLocation
+	}
+	s.reports = append(s.reports, r)
+	return probe.ReportResponse{Accepted: len(r.Results) - 1, Ignored: 1, Confirmed: []string{"web"}}, nil
+}
+
+func TestServer_HandleProbeReports(t *testing.T) {
+	sink := &stubProbeSink{}
+	srv := New("127.0.0.1:0", nil, nil)
+	srv.SetAPIToken("api")
+	srv.SetProbeToken("probe")
+	srv.HandleProbeReports(sink)
+
+	body := `{"location":"us-east","results":[{"service_id":"web","success":true},{"service_id":"gone","success":false}]}`
+	tests := []struct {
+		name       string
+		token      string
+		body       string
+		wantStatus int
+	}{
+		{name: "api token rejected", token: "api", body: body, wantStatus: http.StatusUnauthorized},
+		{name: "accepted", token: "probe", body: body, wantStatus: http.StatusOK},
+		{name: "newer probe fields", token: "probe", body: `{"location":"eu-west","version":2,"results":[{"service_id":"web","success":true,"dns_ms":3}]}`, wantStatus: http.StatusOK},
+		{name: "missing location", token: "probe", body: `{"results":[]}`, wantStatus: http.StatusBadRequest},
+		{name: "malformed", token: "probe", body: `{`, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, probe.ReportPath, strings.NewReader(tc.body))
+			req.Header.Set("Authorization", "Bearer "+tc.token)
+			rec := serve(srv, req)
+
+			if rec.Code != tc.wantStatus {
+				t.Errorf("status = %d, want %d (%s)", rec.Code, tc.wantStatus, rec.Body.String())
+			}
+		})
+	}
+
+	if len(sink.reports) != 2 || sink.reports[0].Location != "us-east" || sink.reports[1].Location != "eu-west" {
+		t.Fatalf("reports = %+v, want one from us-east and one from eu-west", sink.reports)
+	}
+}
+
+func TestServer_HandleProbeReports_Disabled(t *testing.T) {
+	srv := New("127.0.0.1:0", nil, nil)
+	srv.HandleProbeReports(&stubProbeSink{})
+
+	req := httptest.NewRequest(http.MethodPost, probe.ReportPath, strings.NewReader(`{}`))
+	req.Header.Set("Authorization", "Bearer ")
+	if rec := serve(srv, req); rec.Code != http.StatusForbidden {
+		t.Errorf("status = %d, want 403 without a probing token", rec.Code)
+	}
+}
+
+type stubMember struct {
+	seen []string
+}
+
+func (m *stubMember) Self() string { return "a" }
+
+func (m *stubMember) Heartbeat(peer string) error {
+	if peer != "b" {
+		return cluster.ErrUnknownPeer
+	}
+	m.seen = append(m.seen, peer)
+	return nil
+}
+
+func TestServer_HandleClusterHeartbeat(t *testing.T) {
+	member := &stubMember{}
+	srv := New("127.0.0.1:0", nil, nil)
+	srv.SetClusterToken("s3cret")
+	srv.HandleClusterHeartbeat(member)
+
+	tests := []struct {
+		name       string
+		token      string
+		body       string
+		wantStatus int
+	}{
+		{name: "known peer", token: "s3cret", body: `{"peer":"b"}`, wantStatus: http.StatusOK},
+		{name: "unknown peer", token: "s3cret", body: `{"peer":"z"}`, wantStatus: http.StatusBadRequest},
+		{name: "wrong token", token: "nope", body: `{"peer":"b"}`, wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, cluster.HeartbeatPath, strings.NewReader(tc.body))
+			req.Header.Set("Authorization", "Bearer "+tc.token)
+			rec := serve(srv, req)
+
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tc.wantStatus)
+			}
+			if rec.Code == http.StatusOK && !strings.Contains(rec.Body.String(), `"peer":"a"`) {
+				t.Errorf("body = %s, want this member's name", rec.Body.String())
+			}
+		})
+	}
+
+	if len(member.seen) != 1 {
+		t.Errorf("heartbeats recorded = %v, want one from b", member.seen)
+	}
+}
+
+type stubLeaseHolder struct {
+	renewals []ha.Lease
+}
+
+func (h *stubLeaseHolder) Renew(lease ha.Lease) (ha.LeaseReply, error) {
+	if lease.Holder == "b" {
+		return ha.LeaseReply{}, ha.ErrOwnLease
+	}
+	h.renewals = append(h.renewals, lease)
+	return ha.LeaseReply{Name: "b"}, nil
+}
+
+func TestServer_HandleHALease(t *testing.T) {
+	holder := &stubLeaseHolder{}
+	srv := New("127.0.0.1:0", nil, nil)
+	srv.SetHAToken("s3cret")
+	srv.HandleHALease(holder)
+
+	tests := []struct {
+		name       string
+		token      string
+		body       string
+		wantStatus int
+	}{
+		{name: "renewal", token: "s3cret", body: `{"holder":"a","state":{"web":{"state":"DOWN","down_notified":true}}}`, wantStatus: http.StatusOK},
+		{name: "own lease", token: "s3cret", body: `{"holder":"b"}`, wantStatus: http.StatusBadRequest},
+		{name: "wrong token", token: "nope", body: `{"holder":"a"}`, wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, ha.LeasePath, strings.NewReader(tc.body))
+			req.Header.Set("Authorization", "Bearer "+tc.token)
+			rec := serve(srv, req)
+
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tc.wantStatus)
+			}
+		})
+	}
+
+	if len(holder.renewals) != 1 || !holder.renewals[0].State["web"].DownNotified {
+		t.Errorf("renewals = %+v, want one carrying web's state", holder.renewals)
+	}
+}
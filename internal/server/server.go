@@ -5,7 +5,10 @@ import (
 	"context"
 	"errors"
 	"log/slog"
+	"net"
 	"net/http"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -17,13 +20,25 @@ var ErrServerClosed = http.ErrServerClosed
 
 // Server configuration constants.
 const (
-	readHeaderTimeout = 5 * time.Second
+	readHeaderTimeout  = 5 * time.Second
+	rebindDrainTimeout = 10 * time.Second
 )
 
 // Server provides HTTP endpoints.
 type Server struct {
+	mux          *http.ServeMux
+	handler      http.Handler
+	log          *slog.Logger
+	apiToken     atomic.Value // string
+	probeToken   atomic.Value // string
+	clusterToken atomic.Value // string
+	haToken      atomic.Value // string
+
+	mu         sync.Mutex
 	httpServer *http.Server
-	log        *slog.Logger
+	listening  bool
+	closed     bool
+	pending    net.Listener // Listener handed over by Rebind
 }
 
 // New creates a new server.
@@ -39,34 +54,127 @@ func New(addr string, log *slog.Logger, reg *prometheus.Registry) *Server {
 	mux.HandleFunc("/healthz", healthHandler)
 	mux.Handle("/metrics", promhttp.HandlerFor(reg, promhttp.HandlerOpts{}))
 
-	return &Server{
-		httpServer: &http.Server{
-			Addr:              addr,
-			Handler:           withRequestLogging(mux, log),
-			ReadHeaderTimeout: readHeaderTimeout,
-		},
-		log: log,
+	s := &Server{
+		mux:     mux,
+		handler: withRequestLogging(mux, log),
+		log:     log,
+	}
+	s.httpServer = s.newHTTPServer(addr)
+	return s
+}
+
+func (s *Server) newHTTPServer(addr string) *http.Server {
+	return &http.Server{
+		Addr:              addr,
+		Handler:           s.handler,
+		ReadHeaderTimeout: readHeaderTimeout,
 	}
 }
 
-// ListenAndServe starts the server.
+// ListenAndServe starts the server. It keeps serving across Rebind calls and
+// returns ErrServerClosed after Shutdown.
 func (s *Server) ListenAndServe() error {
+	s.mu.Lock()
 	if s.httpServer == nil {
+		s.mu.Unlock()
 		return errors.New("server not initialized")
 	}
-	return s.httpServer.ListenAndServe()
+	if s.closed {
+		s.mu.Unlock()
+		return ErrServerClosed
+	}
+	ln, err := net.Listen("tcp", s.httpServer.Addr)
+	if err != nil {
+		s.mu.Unlock()
+		return err
+	}
+	s.listening = true
+	srv := s.httpServer
+	s.mu.Unlock()
+
+	for {
+		err := srv.Serve(ln)
+		if !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+
+		s.mu.Lock()
+		if s.closed || s.pending == nil {
+			s.mu.Unlock()
+			return err
+		}
+		srv, ln = s.httpServer, s.pending
+		s.pending = nil
+		s.mu.Unlock()
+	}
+}
+
+// Rebind moves the server to a new address. The new address is bound before
+// the old listener is released, so on error the server keeps serving on the
+// old address. In-flight requests on the old listener drain in the background.
+func (s *Server) Rebind(addr string) error {
+	s.mu.Lock()
+	if s.httpServer.Addr == addr || s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	if !s.listening {
+		s.httpServer = s.newHTTPServer(addr)
+		s.mu.Unlock()
+		return nil
+	}
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		s.mu.Unlock()
+		return err
+	}
+
+	// A listener from an earlier Rebind that was not served yet is replaced
+	if s.pending != nil {
+		_ = s.pending.Close()
+	}
+
+	old := s.httpServer
+	s.httpServer = s.newHTTPServer(addr)
+	s.pending = ln
+	s.mu.Unlock()
+
+	s.log.Info("server rebound", "from", old.Addr, "to", addr)
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), rebindDrainTimeout)
+		defer cancel()
+
+		if err := old.Shutdown(ctx); err != nil {
+			s.log.Warn("old listener drain error", "addr", old.Addr, "error", err.Error())
+		}
+	}()
+	return nil
 }
 
 // Shutdown gracefully stops the server.
 func (s *Server) Shutdown(ctx context.Context) error {
-	if s.httpServer == nil {
+	s.mu.Lock()
+	srv := s.httpServer
+	s.closed = true
+	if s.pending != nil {
+		_ = s.pending.Close()
+		s.pending = nil
+	}
+	s.mu.Unlock()
+
+	if srv == nil {
 		return nil
 	}
-	return s.httpServer.Shutdown(ctx)
+	return srv.Shutdown(ctx)
 }
 
 // Addr returns the server's address.
 func (s *Server) Addr() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	if s.httpServer == nil {
 		return ""
 	}
@@ -0,0 +1,139 @@
+package server
+
+import (
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// freeAddr returns a loopback address that is currently unused.
+func freeAddr(t *testing.T) string {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to get free port: %v", err)
+	}
+	addr := ln.Addr().String()
+	_ = ln.Close()
+	return addr
+}
+
+func waitForHealthz(t *testing.T, addr string) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		resp, err := http.Get("http://" + addr + "/healthz")
+		if err == nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			_ = resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				return
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("server not reachable at %s", addr)
+}
+
+func TestServer_Rebind(t *testing.T) {
+	oldAddr, newAddr := freeAddr(t), freeAddr(t)
+	srv := New(oldAddr, nil, nil)
+
+	done := make(chan error, 1)
+	go func() { done <- srv.ListenAndServe() }()
+	waitForHealthz(t, oldAddr)
+
+	if err := srv.Rebind(newAddr); err != nil {
+		t.Fatalf("Rebind() error: %v", err)
+	}
+	waitForHealthz(t, newAddr)
+
+	if srv.Addr() != newAddr {
+		t.Errorf("Addr() = %q, want %q", srv.Addr(), newAddr)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		conn, err := net.DialTimeout("tcp", oldAddr, 100*time.Millisecond)
+		if err != nil {
+			break
+		}
+		_ = conn.Close()
+		if time.Now().After(deadline) {
+			t.Fatal("old address still accepting connections after rebind")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if err := srv.Shutdown(t.Context()); err != nil {
+		t.Fatalf("Shutdown() error: %v", err)
+	}
+	if err := <-done; !errors.Is(err, ErrServerClosed) {
+		t.Errorf("ListenAndServe() = %v, want ErrServerClosed", err)
+	}
+}
+
+func TestServer_RebindFailureKeepsListener(t *testing.T) {
+	addr := freeAddr(t)
+	srv := New(addr, nil, nil)
+
+	go func() { _ = srv.ListenAndServe() }()
+	t.Cleanup(func() { _ = srv.Shutdown(t.Context()) })
+	waitForHealthz(t, addr)
+
+	busy, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to occupy port: %v", err)
+	}
+	defer func() { _ = busy.Close() }()
+
+	if err := srv.Rebind(busy.Addr().String()); err == nil {
+		t.Fatal("expected Rebind to fail on an address in use")
+	}
+
+	if srv.Addr() != addr {
+		t.Errorf("Addr() = %q, want unchanged %q", srv.Addr(), addr)
+	}
+	waitForHealthz(t, addr)
+}
+
+func TestServer_RebindTwiceBeforeServed(t *testing.T) {
+	firstAddr, secondAddr := freeAddr(t), freeAddr(t)
+	srv := New(freeAddr(t), nil, nil)
+	srv.listening = true // As if serving, without picking up rebinds
+
+	if err := srv.Rebind(firstAddr); err != nil {
+		t.Fatalf("Rebind() error: %v", err)
+	}
+	if err := srv.Rebind(secondAddr); err != nil {
+		t.Fatalf("second Rebind() error: %v", err)
+	}
+	t.Cleanup(func() { _ = srv.Shutdown(t.Context()) })
+
+	// The first pending listener was released
+	ln, err := net.Listen("tcp", firstAddr)
+	if err != nil {
+		t.Fatalf("first rebind address still bound: %v", err)
+	}
+	_ = ln.Close()
+
+	if srv.Addr() != secondAddr {
+		t.Errorf("Addr() = %q, want %q", srv.Addr(), secondAddr)
+	}
+}
+
+func TestServer_RebindBeforeListen(t *testing.T) {
+	srv := New("127.0.0.1:0", nil, nil)
+
+	if err := srv.Rebind("127.0.0.1:9999"); err != nil {
+		t.Fatalf("Rebind() error: %v", err)
+	}
+	if srv.Addr() != "127.0.0.1:9999" {
+		t.Errorf("Addr() = %q, want %q", srv.Addr(), "127.0.0.1:9999")
+	}
+}
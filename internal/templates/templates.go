@@ -0,0 +1,168 @@
+// Package templates renders user-supplied templates with the context of an
+// alert: text/template for messages and requests, html/template for HTML
+// email.
+package templates
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Alert is what is known about an alert when it is raised. It travels with
+// the alert, including through the outbox, so it is rendered the same way
+// whenever the alert is sent.
+type Alert struct {
+	Kind      string    `json:"kind"`     // "down" | "recovery"
+	Reminder  bool      `json:"reminder"` // A DOWN alert repeated while still down
+	State     string    `json:"state"`    // "DOWN" | "UP"
+	Service   Service   `json:"service"`
+	Result    Result    `json:"result"`    // The check result that raised the alert
+	Failures  int       `json:"failures"`  // Consecutive failures; 0 on recovery
+	Threshold int       `json:"threshold"` // Failures needed to alert; 0 on recovery
+	Time      time.Time `json:"time"`      // When the alert was raised
+	Since     time.Time `json:"since"`     // When the service started failing
+}
+
+// Duration is how long the service has been failing, or was on recovery,
+// to the second.
+func (a Alert) Duration() time.Duration {
+	if a.Since.IsZero() || a.Time.Before(a.Since) {
+		return 0
+	}
+	return a.Time.Sub(a.Since).Round(time.Second)
+}
+
+// Service describes the service an alert is about.
+type Service struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+	Target string `json:"target"` // URL, host:port, domain, ...
+
+	Tags   []string          `json:"tags,omitempty"`
+	Labels map[string]string `json:"labels,omitempty"` // e.g. team
+	Links  map[string]string `json:"links,omitempty"`  // e.g. runbook, dashboard
+}
+
+// Result is the outcome of the check that raised an alert.
+type Result struct {
+	Success    bool   `json:"success"`
+	StatusCode int    `json:"status_code,omitempty"`
+	LatencyMS  int64  `json:"latency_ms"`
+	Error      string `json:"error,omitempty"`
+}
+
+// Data is what a template is executed with: the alert and the messages
+// uptiq formatted for it.
+type Data struct {
+	Alert
+	Message string `json:"message"` // Chat message, as sent to Slack
+	Subject string `json:"subject"` // One-line summary, as the email subject
+	Body    string `json:"body"`    // Long form, as the email body
+}
+
+// Funcs are the functions available to templates besides the builtins.
+var Funcs = template.FuncMap{
+	"json":  toJSON,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"join":  strings.Join,
+	"rfc3339": func(t time.Time) string {
+		return t.UTC().Format(time.RFC3339)
+	},
+	"unix": func(t time.Time) int64 {
+		return t.Unix()
+	},
+}
+
+// toJSON encodes v as JSON, so strings can be embedded in a JSON body
+// with quotes and escaping.
+func toJSON(v any) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// Template is a parsed text or HTML template.
+type Template interface {
+	Name() string
+	Execute(w io.Writer, data any) error
+}
+
+// Parse parses text as a template named name. A label or link a service
+// does not have renders empty.
+func Parse(name, text string) (*template.Template, error) {
+	return template.New(name).Funcs(Funcs).Option("missingkey=zero").Parse(text)
+}
+
+// ParseHTML parses text as an HTML template named name, which escapes
+// what it inserts.
+func ParseHTML(name, text string) (*htmltemplate.Template, error) {
+	return htmltemplate.New(name).Funcs(htmltemplate.FuncMap(Funcs)).Option("missingkey=zero").Parse(text)
+}
+
+// Check parses text and executes it once with Sample data, so unknown
+// fields are reported when the config is loaded instead of when the first
+// alert is sent.
+func Check(name, text string) error {
+	t, err := Parse(name, text)
+	if err != nil {
+		return err
+	}
+	_, err = Render(t, Sample())
+	return err
+}
+
+// CheckHTML is Check for HTML templates.
+func CheckHTML(name, text string) error {
+	t, err := ParseHTML(name, text)
+	if err != nil {
+		return err
+	}
+	_, err = Render(t, Sample())
+	return err
+}
+
+// Render executes t with data.
+func Render(t Template, data Data) (string, error) {
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("render %s: %w", t.Name(), err)
+	}
+	return buf.String(), nil
+}
+
+// Sample returns data for a made-up DOWN alert, to check templates with.
+func Sample() Data {
+	return Data{
+		Alert: Alert{
+			Kind:  "down",
+			State: "DOWN",
+			Service: Service{
+				ID:     "example",
+				Name:   "Example",
+				Type:   "http",
+				Target: "https://example.com/health",
+				Tags:   []string{"production"},
+				Labels: map[string]string{"team": "platform"},
+				Links:  map[string]string{"runbook": "https://runbooks.example.com/example"},
+			},
+			Result:    Result{StatusCode: 503, LatencyMS: 120, Error: "unexpected status 503"},
+			Failures:  3,
+			Threshold: 3,
+			Time:      time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC),
+			Since:     time.Date(2026, 1, 2, 15, 2, 5, 0, time.UTC),
+		},
+		Message: "🚨 DOWN: Example (example) [http]",
+		Subject: "[DOWN] Example (example)",
+		Body:    "ALERT: SERVICE DOWN",
+	}
+}
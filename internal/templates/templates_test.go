@@ -0,0 +1,112 @@
+package templates
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name       string
+		text       string
+		errContain string // Empty: valid
+	}{
+		{name: "empty", text: ""},
+		{name: "fields", text: `{{ .Service.Name }} is {{ .State }} after {{ .Failures }}/{{ .Threshold }} at {{ rfc3339 .Time }}`},
+		{name: "functions", text: `{"text": {{ json .Message }}, "id": "{{ upper .Service.ID }}", "at": {{ unix .Time }}}`},
+		{name: "syntax error", text: `{{ .Service.Name `, errContain: "unclosed action"},
+		{name: "unknown field", text: `{{ .Service.Hostname }}`, errContain: "Hostname"},
+		{name: "unknown function", text: `{{ shout .Message }}`, errContain: "shout"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := Check("body", tc.text)
+			if tc.errContain == "" {
+				if err != nil {
+					t.Errorf("Check = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.errContain) {
+				t.Errorf("Check = %v, want error containing %q", err, tc.errContain)
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	data := Sample()
+	data.Message = "line \"one\"\nline two"
+
+	tmpl, err := Parse("body", `{"text": {{ json .Message }}, "down": {{ eq .Kind "down" }}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Render(tmpl, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{"text": "line \"one\"\nline two", "down": true}`
+	if got != want {
+		t.Errorf("Render = %s, want %s", got, want)
+	}
+}
+
+func TestRenderHTML(t *testing.T) {
+	data := Sample()
+	data.Result.Error = "<script>alert(1)</script>"
+
+	tmpl, err := ParseHTML("html_body", `<p>{{ .Result.Error }}</p><a href="{{ .Service.Links.runbook }}">Runbook</a>`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Render(tmpl, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `<p>&lt;script&gt;alert(1)&lt;/script&gt;</p><a href="https://runbooks.example.com/example">Runbook</a>`
+	if got != want {
+		t.Errorf("Render = %s, want %s", got, want)
+	}
+}
+
+func TestRender_MissingLabel(t *testing.T) {
+	tmpl, err := Parse("message", `owner={{ .Service.Labels.owner }}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Render(tmpl, Sample())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "owner=" {
+		t.Errorf("Render = %q, want %q", got, "owner=")
+	}
+}
+
+func TestAlert_Duration(t *testing.T) {
+	at := time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		since time.Time
+		want  time.Duration
+	}{
+		{name: "unknown", since: time.Time{}, want: 0},
+		{name: "rounded", since: at.Add(-90*time.Second - 400*time.Millisecond), want: 90 * time.Second},
+		{name: "after alert", since: at.Add(time.Second), want: 0},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			a := Alert{Time: at, Since: tc.since}
+			if got := a.Duration(); got != tc.want {
+				t.Errorf("Duration = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}